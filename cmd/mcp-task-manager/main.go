@@ -26,17 +26,16 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Initialize storage
-	tasksDir := cfg.TasksDir()
-	mdStorage := storage.NewMarkdownStorage(tasksDir)
-	index := storage.NewIndex(tasksDir, mdStorage)
-
-	// Initialize task service
-	svc := task.NewService(mdStorage, mdStorage, index, cfg.TaskTypes, cfg)
-	if err := svc.Initialize(); err != nil {
+	svc, err := openService(cfg)
+	if err != nil {
 		log.Fatalf("Failed to initialize service: %v", err)
 	}
 
+	ws, err := config.FindWorkspace()
+	if err != nil {
+		log.Fatalf("Failed to load workspace: %v", err)
+	}
+
 	// Create MCP server
 	s := server.NewMCPServer(
 		"mcp-task-manager",
@@ -44,11 +43,49 @@ func main() {
 		server.WithToolCapabilities(false),
 	)
 
-	// Register tools
-	tools.Register(s, svc, cfg.TaskTypes, cfg.RelationTypes)
+	// Register tools; in a workspace every tool also accepts a project
+	if ws != nil {
+		resolve := task.NewProjectResolver(func(name string) (*task.Service, error) {
+			projectCfg, err := ws.LoadProject(name)
+			if err != nil {
+				return nil, err
+			}
+			return openService(projectCfg)
+		})
+		svc.SetWorkspace(ws.ProjectOf(cfg), resolve)
+		// Tools of other projects use the same services as relations into them
+		open := func(name string) (*task.Service, *config.Config, error) {
+			projectCfg, err := ws.LoadProject(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			projectSvc, err := resolve(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			return projectSvc, projectCfg, nil
+		}
+		tools.RegisterWorkspace(s, svc, cfg.TaskTypes, cfg.RelationTypeNames(), ws.Names(), open)
+	} else {
+		tools.Register(s, svc, cfg.TaskTypes, cfg.RelationTypeNames())
+	}
 
 	// Start server
 	if err := server.ServeStdio(s); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// openService initializes the task service for a project configuration
+func openService(cfg *config.Config) (*task.Service, error) {
+	tasksDir := cfg.TasksDir()
+	mdStorage := storage.NewMarkdownStorage(tasksDir)
+	index := storage.NewIndex(tasksDir, mdStorage)
+	index.SetRelationTypes(cfg.RelationTypes)
+
+	svc := task.NewService(mdStorage, storage.NewArchiveIndex(mdStorage), index, cfg.TaskTypes, cfg)
+	if err := svc.Initialize(); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
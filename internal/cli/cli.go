@@ -4,15 +4,65 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/exporter"
+	"github.com/gpayer/mcp-task-manager/internal/graph"
+	"github.com/gpayer/mcp-task-manager/internal/importer"
+	"github.com/gpayer/mcp-task-manager/internal/tui"
 	"github.com/integrii/flaggy"
 )
 
 // Version is set at build time
 var Version = "dev"
 
+// activeProject is the workspace project selected with --project or a
+// project-qualified task reference; empty means the project found from cwd
+var activeProject string
+
+// projectFromArgs finds --project before parsing, so the project's task types
+// can be used for flag defaults
+func projectFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case (arg == "--project" || arg == "-P") && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--project="):
+			return strings.TrimPrefix(arg, "--project=")
+		}
+	}
+	return ""
+}
+
+// parseTaskRef parses a task ID or a project-qualified reference (api#42).
+// A project in the reference selects that project for the command.
+func parseTaskRef(ref string) (int, error) {
+	project, id, err := config.ParseTaskRef(ref)
+	if err != nil {
+		return 0, fmt.Errorf("invalid task ID: %s", ref)
+	}
+	if project != "" {
+		if activeProject != "" && activeProject != project {
+			return 0, fmt.Errorf("task reference %s conflicts with project %s", ref, activeProject)
+		}
+		activeProject = project
+	}
+	return id, nil
+}
+
+// flagUsed reports whether a flag was given on the command line, with or
+// without a value (--close, -c, --close=false)
+func flagUsed(sc *flaggy.Subcommand, shortName, longName string) bool {
+	for _, v := range sc.ParsedValues {
+		key, _, _ := strings.Cut(v.Key, "=")
+		if !v.IsPositional && (key == shortName || key == longName) {
+			return true
+		}
+	}
+	return false
+}
+
 // Run executes the CLI with os.Args
 func Run() {
 	code := RunWithArgs(os.Args, os.Stdout, os.Stderr)
@@ -23,6 +73,7 @@ func Run() {
 func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	// Reset flaggy for fresh parsing
 	flaggy.ResetParser()
+	activeProject = projectFromArgs(args[1:])
 
 	taskTypes := []string{"feature", "bug"}
 	if cfg, err := loadConfig(); err == nil && len(cfg.TaskTypes) > 0 {
@@ -39,6 +90,13 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	// Disable built-in version flag since we're using a version subcommand
 	flaggy.DefaultParser.DisableShowVersionWithVersion()
 
+	// The completion subcommand below replaces flaggy's static scripts
+	flaggy.DisableCompletion()
+
+	// Workspace project for all commands
+	var projectFlag string
+	flaggy.String(&projectFlag, "P", "project", "Workspace project to use (see mcp-workspace.yaml)")
+
 	// Version subcommand
 	versionCmd := flaggy.NewSubcommand("version")
 	versionCmd.Description = "Show version information"
@@ -47,32 +105,44 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	// List subcommand
 	listCmd := flaggy.NewSubcommand("list")
 	listCmd.Description = "List tasks with optional filters"
-	var listStatus, listPriority, listType string
+	list := listArgs{Interval: tui.DefaultRefreshInterval}
+	var listFormat, listColumns, listTemplate string
 	var listJSON bool
-	var listParent int
-	var listArchived bool
-	listCmd.String(&listStatus, "s", "status", "Filter by status (todo|in_progress|done)")
-	listCmd.String(&listPriority, "p", "priority", "Filter by priority (critical|high|medium|low)")
-	listCmd.String(&listType, "t", "type", fmt.Sprintf("Filter by type (%s)", strings.Join(taskTypes, "|")))
-	listCmd.Bool(&listJSON, "j", "json", "Output as JSON")
-	listCmd.Int(&listParent, "", "parent", "List subtasks of parent task ID (default: top-level tasks)")
-	listCmd.Bool(&listArchived, "a", "archived", "List archived tasks")
+	listCmd.String(&list.Status, "s", "status", "Filter by status (todo|in_progress|done)")
+	listCmd.String(&list.Priority, "p", "priority", "Filter by priority (critical|high|medium|low)")
+	listCmd.String(&list.Type, "t", "type", fmt.Sprintf("Filter by type (%s)", strings.Join(taskTypes, "|")))
+	listCmd.Bool(&listJSON, "j", "json", "Output as JSON (same as --format json)")
+	listCmd.String(&listFormat, "f", "format", fmt.Sprintf("Output format (%s)", strings.Join(ListFormats, "|")))
+	listCmd.String(&listColumns, "c", "columns", fmt.Sprintf("Comma-separated columns (%s)", strings.Join(ColumnNames(), ",")))
+	listCmd.String(&listTemplate, "", "template", "Go text/template executed for each task, e.g. '{{.ID}} {{.Title}}'")
+	listCmd.Int(&list.ParentID, "", "parent", "List subtasks of parent task ID (default: top-level tasks)")
+	listCmd.Bool(&list.Archived, "a", "archived", "List archived tasks")
+	listCmd.Bool(&list.Duplicates, "", "duplicates", "Include tasks marked as duplicates")
+	listCmd.Bool(&list.Tree, "", "tree", "Show subtasks indented below their parents; filters keep the parents of matches")
+	listCmd.Int(&list.Depth, "", "depth", "With --tree, collapse subtasks below this many levels (default: all)")
+	listCmd.Bool(&list.Watch, "w", "watch", "Re-render whenever tasks change, highlighting status changes; Ctrl-C to quit")
+	listCmd.Duration(&list.Interval, "", "interval", "With --watch, how often to check the tasks directory for changes")
 	flaggy.AttachSubcommand(listCmd, 1)
 
 	// Get subcommand
 	getCmd := flaggy.NewSubcommand("get")
 	getCmd.Description = "Get task details by ID"
 	var getIDStr string
-	var getJSON bool
+	var getJSON, getArchived, getWatch bool
+	getInterval := tui.DefaultRefreshInterval
 	getCmd.AddPositionalValue(&getIDStr, "id", 1, true, "Task ID")
 	getCmd.Bool(&getJSON, "j", "json", "Output as JSON")
+	getCmd.Bool(&getArchived, "a", "archived", "Get an archived task")
+	getCmd.Bool(&getWatch, "w", "watch", "Re-render whenever the task changes, highlighting status changes; Ctrl-C to quit")
+	getCmd.Duration(&getInterval, "", "interval", "With --watch, how often to check the tasks directory for changes")
 	flaggy.AttachSubcommand(getCmd, 1)
 
 	// Next subcommand
 	nextCmd := flaggy.NewSubcommand("next")
 	nextCmd.Description = "Get highest priority todo task"
-	var nextJSON bool
+	var nextJSON, nextAllProjects bool
 	nextCmd.Bool(&nextJSON, "j", "json", "Output as JSON")
+	nextCmd.Bool(&nextAllProjects, "", "all-projects", "Pick the next task across all workspace projects")
 	flaggy.AttachSubcommand(nextCmd, 1)
 
 	// Create subcommand
@@ -81,13 +151,15 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	var createTitle string
 	var createPriority = "medium"
 	var createType = defaultTaskType
-	var createDesc string
-	var createJSON bool
+	var createDesc, createDescFile string
+	var createJSON, createInteractive bool
 	var createParent int
-	createCmd.AddPositionalValue(&createTitle, "title", 1, true, "Task title")
+	createCmd.AddPositionalValue(&createTitle, "title", 1, false, "Task title (prompted for with --interactive)")
 	createCmd.String(&createPriority, "p", "priority", "Priority (default: medium)")
 	createCmd.String(&createType, "t", "type", fmt.Sprintf("Type (%s; default: %s)", strings.Join(taskTypes, "|"), defaultTaskType))
-	createCmd.String(&createDesc, "d", "description", "Task description")
+	createCmd.String(&createDesc, "d", "description", "Task description (- reads it from stdin)")
+	createCmd.String(&createDescFile, "", "description-file", "Read the task description from a file (- for stdin)")
+	createCmd.Bool(&createInteractive, "i", "interactive", "Prompt for title, type, priority, parent and description")
 	createCmd.Bool(&createJSON, "j", "json", "Output as JSON")
 	createCmd.Int(&createParent, "", "parent", "Parent task ID (creates a subtask)")
 	flaggy.AttachSubcommand(createCmd, 1)
@@ -97,13 +169,18 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	updateCmd.Description = "Update an existing task"
 	var updateIDStr string
 	var updateTitle, updateStatus, updatePriority, updateType, updateDesc string
-	var updateJSON bool
-	updateCmd.AddPositionalValue(&updateIDStr, "id", 1, true, "Task ID")
+	var updateIDs, updateWhere, updateDescFile string
+	var updateJSON, updateForce bool
+	updateCmd.AddPositionalValue(&updateIDStr, "id", 1, false, "Task ID (omit when using --ids or --where)")
 	updateCmd.String(&updateTitle, "", "title", "New title")
 	updateCmd.String(&updateStatus, "s", "status", "New status")
 	updateCmd.String(&updatePriority, "p", "priority", "New priority")
 	updateCmd.String(&updateType, "t", "type", fmt.Sprintf("New type (%s)", strings.Join(taskTypes, "|")))
-	updateCmd.String(&updateDesc, "d", "description", "New description")
+	updateCmd.String(&updateDesc, "d", "description", "New description (- reads it from stdin)")
+	updateCmd.String(&updateDescFile, "", "description-file", "Read the new description from a file (- for stdin)")
+	updateCmd.String(&updateIDs, "", "ids", "Comma-separated task IDs to update in one batch")
+	updateCmd.String(&updateWhere, "", "where", "Update all tasks matching a filter (e.g. status=todo,priority=low)")
+	updateCmd.Bool(&updateForce, "f", "force", "Change status without blocker and subtask checks (recorded in history)")
 	updateCmd.Bool(&updateJSON, "j", "json", "Output as JSON")
 	flaggy.AttachSubcommand(updateCmd, 1)
 
@@ -136,18 +213,190 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	completeCmd.Bool(&completeJSON, "j", "json", "Output as JSON")
 	flaggy.AttachSubcommand(completeCmd, 1)
 
+	// Reopen subcommand
+	reopenCmd := flaggy.NewSubcommand("reopen")
+	reopenCmd.Description = "Reopen a done or cancelled task (done -> todo)"
+	var reopenIDStr, reopenReason string
+	var reopenJSON bool
+	reopenCmd.AddPositionalValue(&reopenIDStr, "id", 1, true, "Task ID")
+	reopenCmd.String(&reopenReason, "r", "reason", "Why the task is reopened (required)")
+	reopenCmd.Bool(&reopenJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(reopenCmd, 1)
+
+	// Cancel subcommand
+	cancelCmd := flaggy.NewSubcommand("cancel")
+	cancelCmd.Description = "Cancel a task and its open subtasks (-> done, resolution cancelled)"
+	var cancelIDStr, cancelReason string
+	var cancelJSON bool
+	cancelCmd.AddPositionalValue(&cancelIDStr, "id", 1, true, "Task ID")
+	cancelCmd.String(&cancelReason, "r", "reason", "Why the task is cancelled (required)")
+	cancelCmd.Bool(&cancelJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(cancelCmd, 1)
+
 	// Archive subcommand
 	archiveCmd := flaggy.NewSubcommand("archive")
-	archiveCmd.Description = "Archive a completed task"
+	archiveCmd.Description = "Archive a completed task, or apply the auto_archive policy with --auto"
 	var archiveIDStr string
-	var archiveJSON bool
-	archiveCmd.AddPositionalValue(&archiveIDStr, "id", 1, true, "Task ID")
+	var archiveJSON, archiveAuto, archiveDryRun bool
+	archiveCmd.AddPositionalValue(&archiveIDStr, "id", 1, false, "Task ID")
+	archiveCmd.Bool(&archiveAuto, "", "auto", "Archive done tasks per the auto_archive policy, even if it is not enabled")
+	archiveCmd.Bool(&archiveDryRun, "", "dry-run", "With --auto, only list what would be archived")
 	archiveCmd.Bool(&archiveJSON, "j", "json", "Output as JSON")
 	flaggy.AttachSubcommand(archiveCmd, 1)
 
+	// Unarchive subcommand
+	unarchiveCmd := flaggy.NewSubcommand("unarchive")
+	unarchiveCmd.Description = "Move an archived task and its subtasks back to the active tasks"
+	var unarchiveIDStr string
+	var unarchiveJSON bool
+	unarchiveCmd.AddPositionalValue(&unarchiveIDStr, "id", 1, true, "Task ID")
+	unarchiveCmd.Bool(&unarchiveJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(unarchiveCmd, 1)
+
+	// Import-plan subcommand
+	importPlanCmd := flaggy.NewSubcommand("import-plan")
+	importPlanCmd.Description = "Create a task tree from a YAML or JSON plan document"
+	var importPlanFile string
+	var importPlanJSON bool
+	importPlanCmd.AddPositionalValue(&importPlanFile, "file", 1, true, "Plan document path (- for stdin)")
+	importPlanCmd.Bool(&importPlanJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(importPlanCmd, 1)
+
+	// Import subcommand
+	importCmd := flaggy.NewSubcommand("import")
+	importCmd.Description = "Import tasks from another tracker's export file"
+	var importFormat, importFile, importMapping string
+	var importJSON bool
+	importCmd.AddPositionalValue(&importFormat, "format", 1, true, fmt.Sprintf("Export format (%s)", strings.Join(importer.Names(), "|")))
+	importCmd.AddPositionalValue(&importFile, "file", 2, true, "Export file path (- for stdin)")
+	importCmd.String(&importMapping, "m", "mapping", "YAML file with field mapping overrides (columns, status, priority, type)")
+	importCmd.Bool(&importJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(importCmd, 1)
+
+	// Export subcommand
+	exportCmd := flaggy.NewSubcommand("export")
+	exportCmd.Description = "Export tasks as JSON, CSV or a static HTML report"
+	var exportFormat, exportOutput string
+	var exportArchived bool
+	exportCmd.AddPositionalValue(&exportFormat, "format", 1, true, fmt.Sprintf("Export format (%s)", strings.Join(exporter.Formats(), "|")))
+	exportCmd.String(&exportOutput, "o", "output", "Output file (default: stdout)")
+	exportCmd.Bool(&exportArchived, "a", "archived", "Include archived tasks")
+	flaggy.AttachSubcommand(exportCmd, 1)
+
+	// Deps subcommand
+	depsCmd := flaggy.NewSubcommand("deps")
+	depsCmd.Description = "Show transitive blockers and dependents of a task"
+	var depsIDStr string
+	var depsCritical, depsJSON bool
+	depsCmd.AddPositionalValue(&depsIDStr, "id", 1, false, "Task ID (omit with --critical-path)")
+	depsCmd.Bool(&depsCritical, "c", "critical-path", "Show the longest chain of open blocked_by tasks")
+	depsCmd.Bool(&depsJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(depsCmd, 1)
+
+	// Graph subcommand
+	graphCmd := flaggy.NewSubcommand("graph")
+	graphCmd.Description = "Render tasks, parent links and relations as a Graphviz DOT or Mermaid graph"
+	graphFormat := "mermaid"
+	var graphRoot int
+	var graphStatus, graphEdges, graphOutput string
+	graphCmd.String(&graphFormat, "f", "format", fmt.Sprintf("Output format (%s)", strings.Join(graph.Formats(), "|")))
+	graphCmd.Int(&graphRoot, "r", "root", "Only show this task, its subtasks and tasks directly related to them")
+	graphCmd.String(&graphStatus, "s", "status", "Comma-separated statuses to include")
+	graphCmd.String(&graphEdges, "e", "edges", "Comma-separated edge types to include (parent or relation types)")
+	graphCmd.String(&graphOutput, "o", "output", "Output file (default: stdout)")
+	flaggy.AttachSubcommand(graphCmd, 1)
+
+	// Duplicate subcommand
+	duplicateCmd := flaggy.NewSubcommand("duplicate")
+	duplicateCmd.Description = "Mark a task as duplicate of another and re-point relations to it"
+	var duplicateIDStr, duplicateOfStr string
+	var duplicateClose, duplicateMerge, duplicateJSON bool
+	duplicateCmd.AddPositionalValue(&duplicateIDStr, "id", 1, true, "Duplicate task ID")
+	duplicateCmd.AddPositionalValue(&duplicateOfStr, "canonical", 2, true, "Canonical task ID")
+	duplicateCmd.Bool(&duplicateClose, "c", "close", "Set the duplicate to done; --close=false keeps it open (default from config duplicates.auto_close)")
+	duplicateCmd.Bool(&duplicateMerge, "m", "merge", "Append description and tags to the canonical task; --merge=false skips it (default from config duplicates.merge)")
+	duplicateCmd.Bool(&duplicateJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(duplicateCmd, 1)
+
+	// Move subcommand
+	moveCmd := flaggy.NewSubcommand("move")
+	moveCmd.Description = "Reorder a task among its siblings"
+	var moveIDStr, moveBeforeStr, moveAfterStr string
+	var moveJSON bool
+	moveCmd.AddPositionalValue(&moveIDStr, "id", 1, true, "Task ID")
+	moveCmd.String(&moveBeforeStr, "b", "before", "Place the task directly before this sibling")
+	moveCmd.String(&moveAfterStr, "a", "after", "Place the task directly after this sibling")
+	moveCmd.Bool(&moveJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(moveCmd, 1)
+
+	// Reparent subcommand
+	reparentCmd := flaggy.NewSubcommand("reparent")
+	reparentCmd.Description = "Move a task with its subtasks under a new parent, or to top level"
+	var reparentIDStr, reparentParentStr string
+	var reparentJSON bool
+	reparentCmd.AddPositionalValue(&reparentIDStr, "id", 1, true, "Task ID")
+	reparentCmd.AddPositionalValue(&reparentParentStr, "parent", 2, false, "New parent task ID (omit to make the task top-level)")
+	reparentCmd.Bool(&reparentJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(reparentCmd, 1)
+
+	// Split subcommand
+	splitCmd := flaggy.NewSubcommand("split")
+	splitCmd.Description = "Split a task into subtasks by title, checklist item or ## section"
+	var splitIDStr, splitBy string
+	var splitTitles []string
+	var splitJSON bool
+	splitCmd.AddPositionalValue(&splitIDStr, "id", 1, true, "Task ID")
+	splitCmd.StringSlice(&splitTitles, "t", "title", "Subtask title (repeatable)")
+	splitCmd.String(&splitBy, "b", "by", "Derive subtasks from the description (checklist|sections)")
+	splitCmd.Bool(&splitJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(splitCmd, 1)
+
+	// Merge subcommand
+	mergeCmd := flaggy.NewSubcommand("merge")
+	mergeCmd.Description = "Fold sibling tasks into the first one and delete the others"
+	var mergeIDsStr string
+	var mergeJSON bool
+	mergeCmd.AddPositionalValue(&mergeIDsStr, "ids", 1, true, "Comma-separated task IDs; the first task is kept")
+	mergeCmd.Bool(&mergeJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(mergeCmd, 1)
+
+	// Edit subcommand
+	editCmd := flaggy.NewSubcommand("edit")
+	editCmd.Description = "Edit a task file in $EDITOR; the result is validated before it is saved"
+	var editIDStr string
+	var editJSON bool
+	editCmd.AddPositionalValue(&editIDStr, "id", 1, true, "Task ID")
+	editCmd.Bool(&editJSON, "j", "json", "Output as JSON")
+	flaggy.AttachSubcommand(editCmd, 1)
+
+	// TUI subcommand
+	tuiCmd := flaggy.NewSubcommand("tui")
+	tuiCmd.Description = "Interactive kanban board that refreshes while tasks change"
+	tuiInterval := tui.DefaultRefreshInterval
+	tuiCmd.Duration(&tuiInterval, "i", "interval", "How often to check the tasks directory for changes")
+	flaggy.AttachSubcommand(tuiCmd, 1)
+
+	// Completion subcommand
+	completionCmd := flaggy.NewSubcommand("completion")
+	completionCmd.Description = "Print a shell completion script that also completes task IDs and configured values"
+	var completionShell string
+	completionCmd.AddPositionalValue(&completionShell, "shell", 1, true, fmt.Sprintf("Shell (%s)", strings.Join(completionShells, "|")))
+	flaggy.AttachSubcommand(completionCmd, 1)
+
+	// Completion scripts call back with the words typed so far, which may
+	// include incomplete flags, so answer before parsing
+	if len(args) > 1 && args[1] == completeCommand {
+		for _, c := range completeArgs(&flaggy.DefaultParser.Subcommand, args[2:]) {
+			fmt.Fprintln(stdout, c)
+		}
+		return 0
+	}
+
 	// Parse with custom args
 	flaggy.ParseArgs(args[1:])
 
+	activeProject = projectFlag
+
 	// Handle subcommands
 	if versionCmd.Used {
 		fmt.Fprintf(stdout, "mcp-task-manager %s\n", Version)
@@ -155,70 +404,264 @@ func RunWithArgs(args []string, stdout, stderr io.Writer) int {
 	}
 
 	if listCmd.Used {
-		return cmdList(stdout, stderr, listJSON, listStatus, listPriority, listType, listParent, listArchived)
+		return cmdList(stdout, stderr, listJSON, listFormat, listColumns, listTemplate, list)
 	}
 
 	if getCmd.Used {
-		getID, err := strconv.Atoi(getIDStr)
+		getID, err := parseTaskRef(getIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %v\n", err)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
-		return cmdGet(stdout, stderr, getJSON, getID)
+		return cmdGet(stdout, stderr, getJSON, getID, getArchived, getWatch, getInterval)
 	}
 
 	if nextCmd.Used {
+		if nextAllProjects {
+			return cmdNextAcrossProjects(stdout, stderr, nextJSON)
+		}
 		return cmdNext(stdout, stderr, nextJSON)
 	}
 
 	if createCmd.Used {
-		return cmdCreate(stdout, stderr, createJSON, createTitle, createPriority, createType, createDesc, createParent)
+		if createInteractive && (createDesc == "-" || createDescFile == "-") {
+			fmt.Fprintln(stderr, "Error: the description cannot be read from stdin with --interactive")
+			return 1
+		}
+		desc, err := resolveDescription(createDesc, createDescFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		input := createInput{Title: createTitle, Priority: createPriority, Type: createType, ParentID: createParent}
+		if desc != nil {
+			input.Description = *desc
+		}
+		return cmdCreate(stdout, stderr, createJSON, createInteractive, input)
 	}
 
 	if updateCmd.Used {
-		updateID, err := strconv.Atoi(updateIDStr)
+		desc, err := resolveDescription(updateDesc, updateDescFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		if updateIDs != "" || updateWhere != "" {
+			if updateIDStr != "" {
+				fmt.Fprintln(stderr, "Error: task ID cannot be combined with --ids or --where")
+				return 1
+			}
+			if updateTitle != "" {
+				fmt.Fprintln(stderr, "Error: --title cannot be used in a batch update")
+				return 1
+			}
+			return cmdBatchUpdate(stdout, stderr, updateJSON, updateForce, updateIDs, updateWhere, updateStatus, updatePriority, updateType, desc)
+		}
+		updateID, err := parseTaskRef(updateIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %s\n", updateIDStr)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
-		return cmdUpdate(stdout, stderr, updateJSON, updateID, updateTitle, updateStatus, updatePriority, updateType, updateDesc)
+		return cmdUpdate(stdout, stderr, updateJSON, updateForce, updateID, updateTitle, updateStatus, updatePriority, updateType, desc)
 	}
 
 	if deleteCmd.Used {
-		deleteID, err := strconv.Atoi(deleteIDStr)
+		deleteID, err := parseTaskRef(deleteIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %s\n", deleteIDStr)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
 		return cmdDelete(stdout, stderr, deleteJSON, deleteID, deleteForce)
 	}
 
 	if startCmd.Used {
-		startID, err := strconv.Atoi(startIDStr)
+		startID, err := parseTaskRef(startIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %s\n", startIDStr)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
 		return cmdStart(stdout, stderr, startJSON, startID)
 	}
 
 	if completeCmd.Used {
-		completeID, err := strconv.Atoi(completeIDStr)
+		completeID, err := parseTaskRef(completeIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %s\n", completeIDStr)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
 		return cmdComplete(stdout, stderr, completeJSON, completeID)
 	}
 
+	if reopenCmd.Used {
+		reopenID, err := parseTaskRef(reopenIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdReopen(stdout, stderr, reopenJSON, reopenID, reopenReason)
+	}
+
+	if cancelCmd.Used {
+		cancelID, err := parseTaskRef(cancelIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdCancel(stdout, stderr, cancelJSON, cancelID, cancelReason)
+	}
+
 	if archiveCmd.Used {
-		archiveID, err := strconv.Atoi(archiveIDStr)
+		switch {
+		case archiveAuto && archiveIDStr != "":
+			fmt.Fprintln(stderr, "Error: use either a task ID or --auto, not both")
+			return 1
+		case archiveAuto:
+			return cmdAutoArchive(stdout, stderr, archiveJSON, archiveDryRun)
+		case archiveDryRun:
+			fmt.Fprintln(stderr, "Error: --dry-run requires --auto")
+			return 1
+		case archiveIDStr == "":
+			fmt.Fprintln(stderr, "Error: task ID or --auto is required")
+			return 1
+		}
+		archiveID, err := parseTaskRef(archiveIDStr)
 		if err != nil {
-			fmt.Fprintf(stderr, "Error: invalid task ID: %s\n", archiveIDStr)
+			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
 		return cmdArchive(stdout, stderr, archiveJSON, archiveID)
 	}
 
+	if unarchiveCmd.Used {
+		unarchiveID, err := parseTaskRef(unarchiveIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdUnarchive(stdout, stderr, unarchiveJSON, unarchiveID)
+	}
+
+	if importCmd.Used {
+		return cmdImport(stdout, stderr, importJSON, importFormat, importFile, importMapping)
+	}
+
+	if exportCmd.Used {
+		return cmdExport(stdout, stderr, exportFormat, exportOutput, exportArchived)
+	}
+
+	if depsCmd.Used {
+		if depsCritical {
+			if depsIDStr != "" {
+				fmt.Fprintln(stderr, "Error: task ID cannot be combined with --critical-path")
+				return 1
+			}
+			return cmdCriticalPath(stdout, stderr, depsJSON)
+		}
+		depsID, err := parseTaskRef(depsIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdDeps(stdout, stderr, depsJSON, depsID)
+	}
+
+	if graphCmd.Used {
+		return cmdGraph(stdout, stderr, graphFormat, graph.NewFilter(graphRoot, graphStatus, graphEdges), graphOutput)
+	}
+
+	if duplicateCmd.Used {
+		duplicateID, err := parseTaskRef(duplicateIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		canonicalID, err := parseTaskRef(duplicateOfStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		var closeDuplicate, merge *bool
+		if flagUsed(duplicateCmd, "c", "close") {
+			closeDuplicate = &duplicateClose
+		}
+		if flagUsed(duplicateCmd, "m", "merge") {
+			merge = &duplicateMerge
+		}
+		return cmdDuplicate(stdout, stderr, duplicateJSON, duplicateID, canonicalID, closeDuplicate, merge)
+	}
+
+	if moveCmd.Used {
+		ids := make([]int, 3)
+		for i, ref := range []string{moveIDStr, moveBeforeStr, moveAfterStr} {
+			if ref == "" {
+				continue
+			}
+			id, err := parseTaskRef(ref)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+				return 1
+			}
+			ids[i] = id
+		}
+		return cmdMove(stdout, stderr, moveJSON, ids[0], ids[1], ids[2])
+	}
+
+	if reparentCmd.Used {
+		id, err := parseTaskRef(reparentIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		var parentID *int
+		if reparentParentStr != "" {
+			pid, err := parseTaskRef(reparentParentStr)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+				return 1
+			}
+			parentID = &pid
+		}
+		return cmdReparent(stdout, stderr, reparentJSON, id, parentID)
+	}
+
+	if splitCmd.Used {
+		id, err := parseTaskRef(splitIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdSplit(stdout, stderr, splitJSON, id, splitTitles, splitBy)
+	}
+
+	if mergeCmd.Used {
+		ids, err := parseIDList(mergeIDsStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdMerge(stdout, stderr, mergeJSON, ids)
+	}
+
+	if importPlanCmd.Used {
+		return cmdImportPlan(stdout, stderr, importPlanJSON, importPlanFile)
+	}
+
+	if editCmd.Used {
+		id, err := parseTaskRef(editIDStr)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return cmdEdit(stdout, stderr, editJSON, id)
+	}
+
+	if completionCmd.Used {
+		return cmdCompletion(stdout, stderr, completionShell)
+	}
+
+	if tuiCmd.Used {
+		return cmdTUI(stderr, tuiInterval)
+	}
+
 	return 0
 }
@@ -2,11 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gpayer/mcp-task-manager/internal/task"
 	"github.com/integrii/flaggy"
 )
 
@@ -166,6 +171,37 @@ func TestUpdateCommand(t *testing.T) {
 	}
 }
 
+func TestUpdateCommandStatusChecks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Parent"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Child", "--parent", "1"}, &stdout, &stderr)
+
+	// Completing a parent with open subtasks is rejected
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "update", "1", "-s", "done"}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "incomplete subtask") {
+		t.Errorf("expected incomplete subtask error, got: %s", stderr.String())
+	}
+
+	// --force skips the check and is recorded in the history
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "update", "1", "-s", "done", "--force"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "force: todo -> done") {
+		t.Errorf("expected force history entry, got: %s", stdout.String())
+	}
+}
+
 func TestDeleteCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("MCP_TASKS_DIR", tmpDir)
@@ -454,6 +490,61 @@ func TestArchiveCommandNotDone(t *testing.T) {
 	}
 }
 
+func TestArchiveAutoCommand(t *testing.T) {
+	root := t.TempDir()
+	config := "auto_archive:\n  after_days: 0\n  rules:\n    - type: bug\n      never: true\n"
+	if err := os.WriteFile(filepath.Join(root, "mcp-tasks.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("MCP_TASKS_DIR", filepath.Join(root, "tasks"))
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Feature done"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Bug done", "--type", "bug"}, &stdout, &stderr)
+	for _, id := range []string{"1", "2"} {
+		RunWithArgs([]string{"mcp-task-manager", "start", id}, &stdout, &stderr)
+		RunWithArgs([]string{"mcp-task-manager", "complete", id}, &stdout, &stderr)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "archive", "--auto", "--dry-run"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	want := "Would archive 1 task(s):\n  #1 Feature done: done for 0 days (default: after 0 days)\n"
+	if stdout.String() != want {
+		t.Errorf("dry run output = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list", "--json"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Feature done") {
+		t.Error("a dry run should not archive")
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "archive", "--auto", "--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"archived": [`) || !strings.Contains(stdout.String(), `"id": 1`) {
+		t.Errorf("expected task 1 in JSON output, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "archive", "--auto"}, &stdout, &stderr)
+	if stdout.String() != "No tasks to archive.\n" {
+		t.Errorf("second run output = %q, want nothing to archive", stdout.String())
+	}
+
+	for _, args := range [][]string{{"archive"}, {"archive", "2", "--auto"}, {"archive", "2", "--dry-run"}} {
+		stderr.Reset()
+		if code := RunWithArgs(append([]string{"mcp-task-manager"}, args...), &stdout, &stderr); code != 1 {
+			t.Errorf("%v: expected exit code 1, got %d", args, code)
+		}
+	}
+}
+
 func TestListArchivedCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("MCP_TASKS_DIR", tmpDir)
@@ -506,6 +597,118 @@ func TestListArchivedCommandJSON(t *testing.T) {
 	}
 }
 
+func TestListArchivedCommandFilters(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived feature"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived bug", "--type", "bug"}, &stdout, &stderr)
+	for _, id := range []string{"1", "2"} {
+		RunWithArgs([]string{"mcp-task-manager", "start", id}, &stdout, &stderr)
+		RunWithArgs([]string{"mcp-task-manager", "complete", id}, &stdout, &stderr)
+		RunWithArgs([]string{"mcp-task-manager", "archive", id}, &stdout, &stderr)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "list", "--archived", "--type", "bug"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Archived bug") || strings.Contains(stdout.String(), "Archived feature") {
+		t.Errorf("expected only the archived bug, got: %s", stdout.String())
+	}
+}
+
+func TestGetArchivedCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived parent"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived child", "--parent", "1"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "start", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "complete", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "archive", "1"}, &stdout, &stderr)
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "get", "1", "--archived"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Archived parent") || !strings.Contains(stdout.String(), "#2 [done] Archived child") {
+		t.Errorf("expected archived task with subtask, got: %s", stdout.String())
+	}
+}
+
+func TestUnarchiveCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived parent"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived child", "--parent", "1"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "start", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "complete", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "archive", "1"}, &stdout, &stderr)
+
+	// Subtasks come back with their parent only
+	stdout.Reset()
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "unarchive", "2"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for archived subtask, got %d", code)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "unarchive", "1"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Task #1 unarchived.") || !strings.Contains(stdout.String(), "Subtask #2") {
+		t.Errorf("expected unarchive message with subtask, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Archived parent") {
+		t.Errorf("expected unarchived task in list, got: %s", stdout.String())
+	}
+}
+
+func TestGetShowsArchivedRelations(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	planPath := tmpDir + "/plan.yaml"
+	plan := "tasks:\n  - key: api\n    title: Build API\n  - key: ui\n    title: Build UI\n    blocked_by: [api]\n"
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "import-plan", planPath}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "start", "1"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "complete", "1"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "archive", "1"}, &stdout, &stderr)
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "get", "2"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Errorf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "blocked_by -> #1 (archived)") || strings.Contains(stdout.String(), "[BLOCKED]") {
+		t.Errorf("expected an unblocking archived relation, got: %s", stdout.String())
+	}
+}
+
 func TestListArchivedEmptyCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("MCP_TASKS_DIR", tmpDir)
@@ -545,3 +748,930 @@ func TestNextCommandNoProject(t *testing.T) {
 		t.Errorf("expected 'no tasks directory found' error, got: %s", stderr.String())
 	}
 }
+
+func TestImportPlanCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	planPath := tmpDir + "/plan.yaml"
+	plan := "tasks:\n  - key: api\n    title: Build API\n    subtasks:\n      - key: schema\n        title: Define schema\n  - key: ui\n    title: Build UI\n    blocked_by: [api]\n"
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithArgs([]string{"mcp-task-manager", "import-plan", planPath, "--json"}, &stdout, &stderr)
+
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"schema": 2`) {
+		t.Errorf("expected key mapping in output, got: %s", stdout.String())
+	}
+
+	// The dependent task should be reported as blocked
+	stdout.Reset()
+	stderr.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "get", "3"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "[BLOCKED]") {
+		t.Errorf("expected imported task to be blocked, got: %s", stdout.String())
+	}
+}
+
+func TestImportPlanCommandInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	planPath := tmpDir + "/plan.yaml"
+	plan := "tasks:\n  - key: a\n    title: A\n  - key: b\n    title: B\n    blocked_by: [missing]\n"
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithArgs([]string{"mcp-task-manager", "import-plan", planPath}, &stdout, &stderr)
+
+	if code != 1 {
+		t.Errorf("expected exit code 1 for invalid plan, got %d", code)
+	}
+
+	// Nothing should have been created
+	stdout.Reset()
+	stderr.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "No tasks") {
+		t.Errorf("expected no tasks after failed import, got: %s", stdout.String())
+	}
+}
+
+func TestUpdateCommandBatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "First", "-p", "low"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Second", "-p", "low"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Third", "-p", "high"}, &stdout, &stderr)
+
+	// Update by explicit IDs
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "update", "--ids", "1,3", "-t", "bug"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Updated 2 task(s)") {
+		t.Errorf("expected 2 updated tasks, got: %s", stdout.String())
+	}
+
+	// Update by filter
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "update", "--where", "priority=low", "-p", "critical"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list", "-p", "critical"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "First") || !strings.Contains(stdout.String(), "Second") || strings.Contains(stdout.String(), "Third") {
+		t.Errorf("expected First and Second to be critical, got: %s", stdout.String())
+	}
+
+	// A single task ID cannot be combined with batch selection
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "update", "1", "--ids", "2", "-p", "low"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1 when combining ID and --ids, got %d", code)
+	}
+}
+
+func TestImportCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	exportPath := tmpDir + "/issues.json"
+	export := `[{"number": 7, "title": "Imported issue", "body": "From GitHub", "state": "OPEN", "labels": [{"name": "bug"}]}]`
+	if err := os.WriteFile(exportPath, []byte(export), 0644); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithArgs([]string{"mcp-task-manager", "import", "github", exportPath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 created") {
+		t.Errorf("expected 1 created task, got: %s", stdout.String())
+	}
+
+	// Re-importing updates the existing task
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "import", "github", exportPath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "0 created, 1 updated") {
+		t.Errorf("expected 1 updated task, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "get", "1"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "github:7") || !strings.Contains(stdout.String(), "bug") {
+		t.Errorf("expected external ID and type in output, got: %s", stdout.String())
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Active task", "-d", "Body text"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Archived task"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "start", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "complete", "2"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "archive", "2"}, &stdout, &stderr)
+
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "export", "json"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Body text") || strings.Contains(stdout.String(), "Archived task") {
+		t.Errorf("expected active task with description only, got: %s", stdout.String())
+	}
+
+	outPath := tmpDir + "/report.html"
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "export", "html", "--archived", "-o", outPath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	if !strings.Contains(string(data), "Archived task") {
+		t.Errorf("expected archived task in HTML report")
+	}
+}
+
+func TestDepsCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	planPath := tmpDir + "/plan.yaml"
+	plan := "tasks:\n  - key: a\n    title: Schema\n  - key: b\n    title: Handlers\n    blocked_by: [a]\n  - key: c\n    title: UI\n    blocked_by: [b]\n"
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "import-plan", planPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("import-plan failed: %s", stderr.String())
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "deps", "2"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "#1 [todo] Schema") || !strings.Contains(stdout.String(), "#3 [todo] UI") {
+		t.Errorf("expected blocker and dependent in output, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "deps", "--critical-path"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Critical path (3 tasks)") {
+		t.Errorf("expected critical path of 3 tasks, got: %s", stdout.String())
+	}
+}
+
+func TestGraphCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	planPath := tmpDir + "/plan.yaml"
+	plan := "tasks:\n  - key: api\n    title: Build API\n    subtasks:\n      - key: schema\n        title: Define schema\n  - key: ui\n    title: Build UI\n    blocked_by: [api]\n"
+	if err := os.WriteFile(planPath, []byte(plan), 0644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "import-plan", planPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("import-plan failed: %s", stderr.String())
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "graph", "-f", "dot"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `t2 -> t1 [label="parent", style=dashed];`) || !strings.Contains(stdout.String(), `t3 -> t1 [label="blocked_by"];`) {
+		t.Errorf("expected parent and blocked_by edges, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "graph", "--edges", "blocked_by"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "parent") || !strings.Contains(stdout.String(), "t3 -->|blocked_by| t1") {
+		t.Errorf("expected only blocked_by edges in Mermaid output, got: %s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "graph", "-f", "svg"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for unknown format, got %d", code)
+	}
+}
+
+func TestDuplicateCommandOverridesConfigDefaults(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "mcp-tasks.yaml"), []byte("duplicates:\n  auto_close: true\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("MCP_TASKS_DIR", filepath.Join(root, "tasks"))
+
+	var stdout, stderr bytes.Buffer
+	for _, title := range []string{"One", "Two", "Three"} {
+		RunWithArgs([]string{"mcp-task-manager", "create", title}, &stdout, &stderr)
+	}
+	if code := RunWithArgs([]string{"mcp-task-manager", "duplicate", "1", "3", "--close=false"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if code := RunWithArgs([]string{"mcp-task-manager", "duplicate", "2", "3"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+
+	for id, want := range map[string]string{"1": "todo", "2": "done"} {
+		stdout.Reset()
+		RunWithArgs([]string{"mcp-task-manager", "get", id, "--json"}, &stdout, &stderr)
+		if !strings.Contains(stdout.String(), `"status": "`+want+`"`) {
+			t.Errorf("task %s: expected status %s, got: %s", id, want, stdout.String())
+		}
+	}
+}
+
+func TestDuplicateCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Login fails", "-t", "bug", "-d", "Seen on Safari"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Login broken", "-t", "bug"}, &stdout, &stderr)
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "duplicate", "1", "2", "--close", "--merge"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Marked task #1 as duplicate of #2") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	// Duplicates are hidden from the default list
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list"}, &stdout, &stderr)
+	if strings.Contains(stdout.String(), "Login fails") {
+		t.Errorf("expected duplicate to be hidden, got: %s", stdout.String())
+	}
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list", "--duplicates"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Login fails") {
+		t.Errorf("expected duplicate with --duplicates, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "get", "2"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Seen on Safari") {
+		t.Errorf("expected merged description, got: %s", stdout.String())
+	}
+}
+
+func TestWorkspaceProjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir+"/local/tasks")
+	workspace := tmpDir + "/mcp-workspace.yaml"
+	if err := os.WriteFile(workspace, []byte("projects:\n  api: api\n  web: web\n"), 0644); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+	t.Setenv("MCP_WORKSPACE", workspace)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "API task", "-p", "low", "--project", "api"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create in api failed: %s", stderr.String())
+	}
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "Web task", "-p", "high", "--project", "web"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create in web failed: %s", stderr.String())
+	}
+
+	// Project-qualified references select the project
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "get", "web#1"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Web task") {
+		t.Errorf("expected web task, got: %s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "get", "web#1", "--project", "api"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for conflicting project, got %d", code)
+	}
+
+	// The local project is untouched
+	stdout.Reset()
+	RunWithArgs([]string{"mcp-task-manager", "list"}, &stdout, &stderr)
+	if strings.Contains(stdout.String(), "API task") {
+		t.Errorf("expected no tasks in local project, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "next", "--all-projects", "--json"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"ref": "web#1"`) {
+		t.Errorf("expected high priority web task, got: %s", stdout.String())
+	}
+}
+
+func TestMoveCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	for _, title := range []string{"First", "Second", "Third"} {
+		if code := RunWithArgs([]string{"mcp-task-manager", "create", title}, &stdout, &stderr); code != 0 {
+			t.Fatalf("create failed: %s", stderr.String())
+		}
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "move", "3", "--before", "1"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Task #3 moved before #1.") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "list"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("list failed: %s", stderr.String())
+	}
+	out := stdout.String()
+	if strings.Index(out, "Third") > strings.Index(out, "First") {
+		t.Errorf("expected Third listed before First, got: %s", out)
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "move", "3"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 without --before/--after, got %d", code)
+	}
+}
+
+func TestReparentCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	for _, title := range []string{"Parent", "Stray"} {
+		if code := RunWithArgs([]string{"mcp-task-manager", "create", title}, &stdout, &stderr); code != 0 {
+			t.Fatalf("create failed: %s", stderr.String())
+		}
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "reparent", "2", "1"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Moved task #2 under #1.") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "get", "2"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("get failed: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Parent:      #1") {
+		t.Errorf("expected parent #1, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "reparent", "2"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("reparent to top level failed: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Moved task #2 to top level.") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "reparent", "1", "1"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for self-parenting, got %d", code)
+	}
+}
+
+func TestGetCommand_ShowsNestedSubtasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	for _, args := range [][]string{
+		{"create", "Epic"},
+		{"create", "Story", "--parent", "1"},
+		{"create", "Step", "--parent", "2"},
+	} {
+		if code := RunWithArgs(append([]string{"mcp-task-manager"}, args...), &stdout, &stderr); code != 0 {
+			t.Fatalf("%v failed: %s", args, stderr.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "get", "1"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("get failed: %s", stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "Subtasks (2):") || !strings.Contains(out, "\n  #2 [todo] Story\n    #3 [todo] Step\n") {
+		t.Errorf("expected indented subtask tree, got: %s", out)
+	}
+}
+
+func TestSplitAndMergeCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "Login", "-d", "- [ ] Form\n- [ ] Validation"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create failed: %s", stderr.String())
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "split", "1", "--by", "checklist"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Split task #1 into 2 subtask(s)") || !strings.Contains(stdout.String(), "#3 [todo] Validation") {
+		t.Errorf("unexpected split output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "merge", "2,3"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Merged #3 into task #2.") {
+		t.Errorf("unexpected merge output: %s", stdout.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "get", "3"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected merged task 3 to be gone, got exit code %d", code)
+	}
+}
+
+func TestReopenAndCancelCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "Flaky test"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create failed: %s", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "cancel", "1"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 without --reason, got %d", code)
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "cancel", "1", "--reason", "cannot reproduce"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Task #1 cancelled.") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "reopen", "1", "-r", "seen again"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("reopen failed: %s", stderr.String())
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "get", "1"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("get failed: %s", stderr.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "cancel: todo -> done (cannot reproduce)") || !strings.Contains(out, "reopen: done -> todo (seen again)") {
+		t.Errorf("expected history in output, got: %s", out)
+	}
+}
+
+// writeEditor creates a shell script usable as $EDITOR that applies a sed
+// expression to the edited file; the n-th run uses the n-th expression and
+// later runs reuse the last one
+func writeEditor(t *testing.T, exprs ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\nn=$(cat " + dir + "/runs 2>/dev/null || echo 0)\necho $((n+1)) > " + dir + "/runs\ncase $n in\n")
+	for i, expr := range exprs {
+		pattern := fmt.Sprint(i)
+		if i == len(exprs)-1 {
+			pattern = "*"
+		}
+		script.WriteString(fmt.Sprintf("%s) sed '%s' \"$1\" > \"$1.tmp\" && mv \"$1.tmp\" \"$1\" ;;\n", pattern, expr))
+	}
+	script.WriteString("esac\n")
+	path := filepath.Join(dir, "editor.sh")
+	if err := os.WriteFile(path, []byte(script.String()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return "sh " + path
+}
+
+func TestEditCommand(t *testing.T) {
+	t.Setenv("MCP_TASKS_DIR", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Plan", "-d", "Draft"}, &stdout, &stderr)
+
+	// A valid edit is saved and shown as a diff
+	t.Setenv("EDITOR", writeEditor(t, "s/^Draft$/Step one/"))
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "edit", "1"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "- Draft\n+ Step one") {
+		t.Errorf("expected diff in output, got: %s", stdout.String())
+	}
+
+	// An invalid edit re-opens the editor until it is fixed
+	t.Setenv("EDITOR", writeEditor(t, "s/^priority: medium/priority: urgent/", "s/^priority: urgent/priority: high/"))
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "edit", "1"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "invalid priority: urgent") {
+		t.Errorf("expected validation error, got: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "+ priority: high") || strings.Contains(stdout.String(), "edit error") {
+		t.Errorf("expected clean diff, got: %s", stdout.String())
+	}
+
+	// Saving the rejected file unchanged aborts
+	t.Setenv("EDITOR", writeEditor(t, "s/^status: todo/status: started/"))
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "edit", "1"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "edit aborted") {
+		t.Errorf("expected abort, got code %d, stderr: %s", code, stderr.String())
+	}
+
+	// Leaving the file untouched changes nothing
+	t.Setenv("EDITOR", "true")
+	stdout.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "edit", "1"}, &stdout, &stderr)
+	if code != 0 || !strings.Contains(stdout.String(), "No changes.") {
+		t.Errorf("expected no changes, got code %d, stdout: %s", code, stdout.String())
+	}
+}
+
+// setStdin replaces the CLI's stdin for the duration of the test
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+	old := stdin
+	stdin = strings.NewReader(input)
+	t.Cleanup(func() { stdin = old })
+}
+
+func TestDescriptionFromFileAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", dir)
+
+	descFile := filepath.Join(t.TempDir(), "plan.md")
+	os.WriteFile(descFile, []byte("## Plan\n\nFirst paragraph.\n\nSecond paragraph.\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	code := RunWithArgs([]string{"mcp-task-manager", "create", "From file", "--description-file", descFile}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "First paragraph.\n\nSecond paragraph.") {
+		t.Errorf("expected description from file, got: %s", stdout.String())
+	}
+
+	setStdin(t, "Piped description\n")
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "update", "1", "-d", "-"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Piped description") || strings.Contains(stdout.String(), "First paragraph") {
+		t.Errorf("expected description from stdin, got: %s", stdout.String())
+	}
+
+	// An empty file clears the description
+	emptyFile := filepath.Join(t.TempDir(), "empty.md")
+	os.WriteFile(emptyFile, nil, 0644)
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "update", "1", "--description-file", emptyFile, "-j"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "Piped description") {
+		t.Errorf("expected description to be cleared, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "create", "Both", "-d", "x", "--description-file", descFile}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "not both") {
+		t.Errorf("expected error for both sources, got code %d, stderr: %s", code, stderr.String())
+	}
+}
+
+func TestCreateInteractive(t *testing.T) {
+	t.Setenv("MCP_TASKS_DIR", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Login page"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Logout flow"}, &stdout, &stderr)
+
+	// Empty title is asked again, "b" completes to bug, "x" is rejected,
+	// "log" is ambiguous, "page" selects task 1
+	setStdin(t, "\nFix styles\nb\nx\nh\nlog\npage\nLine one\n\nLine two\n.\n")
+	stdout.Reset()
+	stderr.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "create", "-i", "-j"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s\nstdout: %s", code, stderr.String(), stdout.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"-> bug", `"x" is not one of`, "-> high", "#2 Logout flow", "-> #1 Login page", `"type": "bug"`, `"priority": "high"`, `"parent_id": 1`, `Line one\n\nLine two`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	// Running out of input fails without creating a task
+	setStdin(t, "Half done\n")
+	stderr.Reset()
+	code = RunWithArgs([]string{"mcp-task-manager", "create", "-i"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "input ended") {
+		t.Errorf("expected input error, got code %d, stderr: %s", code, stderr.String())
+	}
+}
+
+func TestCompletionCommand(t *testing.T) {
+	t.Setenv("MCP_TASKS_DIR", t.TempDir())
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var stdout, stderr bytes.Buffer
+		code := RunWithArgs([]string{"mcp-task-manager", "completion", shell}, &stdout, &stderr)
+		if code != 0 {
+			t.Fatalf("completion %s: expected exit code 0, got %d. stderr: %s", shell, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), "mcp-task-manager __complete") {
+			t.Errorf("completion %s: script should call __complete, got: %s", shell, stdout.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "completion", "tcsh"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for unsupported shell, got %d", code)
+	}
+}
+
+func TestCompleteCandidates(t *testing.T) {
+	t.Setenv("MCP_TASKS_DIR", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	RunWithArgs([]string{"mcp-task-manager", "create", "Alpha"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "create", "Beta"}, &stdout, &stderr)
+	RunWithArgs([]string{"mcp-task-manager", "start", "1"}, &stdout, &stderr)
+
+	complete := func(words ...string) string {
+		stdout.Reset()
+		RunWithArgs(append([]string{"mcp-task-manager", "__complete"}, words...), &stdout, &stderr)
+		return stdout.String()
+	}
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"rep"}, "reparent\tMove a task with its subtasks under a new parent, or to top level\n"},
+		{[]string{"start", ""}, "2\tBeta (todo)\n"},
+		{[]string{"complete", ""}, "1\tAlpha (in_progress)\n"},
+		{[]string{"list", "-s", "in"}, "in_progress\n"},
+		{[]string{"create", "x", "--type", ""}, "feature\nbug\n"},
+		{[]string{"merge", "2,"}, "2,1\tAlpha (in_progress)\n2,2\tBeta (todo)\n"},
+		{[]string{"split", "1", "--by", "s"}, "sections\n"},
+		{[]string{"reopen", "1", "--rea"}, "--reason\tWhy the task is reopened (required)\n"},
+		{[]string{"completion", "f"}, "fish\n"},
+	}
+	for _, tt := range tests {
+		if got := complete(tt.words...); got != tt.want {
+			t.Errorf("__complete %q = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestListCommandFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	for _, title := range []string{"First", "Second"} {
+		if code := RunWithArgs([]string{"mcp-task-manager", "create", title}, &stdout, &stderr); code != 0 {
+			t.Fatalf("create failed: %s", stderr.String())
+		}
+	}
+
+	stdout.Reset()
+	code := RunWithArgs([]string{"mcp-task-manager", "list", "--format", "csv", "--columns", "id,title,status"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d. stderr: %s", code, stderr.String())
+	}
+	if want := "id,title,status\n1,First,todo\n2,Second,todo\n"; stdout.String() != want {
+		t.Errorf("csv output = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "list", "--template", "#{{.ID}} {{.Title}}"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("list --template failed: %s", stderr.String())
+	}
+	if want := "#1 First\n#2 Second\n"; stdout.String() != want {
+		t.Errorf("template output = %q, want %q", stdout.String(), want)
+	}
+
+	t.Setenv("COLUMNS", "30")
+	stdout.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "list", "-c", "id,title"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("list -c failed: %s", stderr.String())
+	}
+	if strings.Contains(stdout.String(), "Status") || !strings.Contains(stdout.String(), "Second") {
+		t.Errorf("expected only id and title columns, got: %s", stdout.String())
+	}
+
+	for _, args := range [][]string{
+		{"list", "--format", "xml"},
+		{"list", "--columns", "id,owner"},
+		{"list", "--json", "--format", "csv"},
+		{"list", "--format", "template"},
+	} {
+		stderr.Reset()
+		if code := RunWithArgs(append([]string{"mcp-task-manager"}, args...), &stdout, &stderr); code != 1 {
+			t.Errorf("%v: expected exit code 1, got %d", args, code)
+		}
+		if !strings.Contains(stderr.String(), "Error:") {
+			t.Errorf("%v: expected error message, got: %s", args, stderr.String())
+		}
+	}
+}
+
+func TestListCommandTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	for _, args := range [][]string{
+		{"create", "Epic"},
+		{"create", "Other"},
+		{"create", "Story", "--parent", "1"},
+		{"create", "Bug", "--parent", "3", "-t", "bug"},
+	} {
+		if code := RunWithArgs(append([]string{"mcp-task-manager"}, args...), &stdout, &stderr); code != 0 {
+			t.Fatalf("%v failed: %s", args, stderr.String())
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list", "--tree"}, "○ #1 Epic\n└── ○ #3 Story\n    └── ○ #4 Bug\n○ #2 Other\n"},
+		{[]string{"list", "--tree", "-t", "bug"}, "○ #1 Epic\n└── ○ #3 Story\n    └── ○ #4 Bug\n"},
+		{[]string{"list", "--tree", "--depth", "1"}, "○ #1 Epic (+1 subtasks)\n○ #2 Other\n"},
+		{[]string{"list", "--tree", "--parent", "3"}, "○ #4 Bug\n"},
+	}
+	for _, tt := range tests {
+		stdout.Reset()
+		if code := RunWithArgs(append([]string{"mcp-task-manager"}, tt.args...), &stdout, &stderr); code != 0 {
+			t.Fatalf("%v failed: %s", tt.args, stderr.String())
+		}
+		if stdout.String() != tt.want {
+			t.Errorf("%v output =\n%s\nwant:\n%s", tt.args, stdout.String(), tt.want)
+		}
+	}
+
+	stderr.Reset()
+	if code := RunWithArgs([]string{"mcp-task-manager", "list", "--tree", "--format", "csv"}, &stdout, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for --tree with csv, got %d", code)
+	}
+}
+
+func TestRunWatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "Watched"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create failed: %s", stderr.String())
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	// The first frame starts the task from outside; the watch picks up the
+	// change and reports the previous status in the second frame
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var frames []map[int]task.Status
+	frame := func(svc *task.Service, changed map[int]task.Status) (string, error) {
+		frames = append(frames, changed)
+		if len(frames) == 1 {
+			if code := RunWithArgs([]string{"mcp-task-manager", "start", "1"}, &stdout, &stderr); code != 0 {
+				t.Errorf("start failed: %s", stderr.String())
+			}
+		} else {
+			cancel()
+		}
+		var buf bytes.Buffer
+		err := writeList(&buf, svc, ListOptions{Format: "table", Changed: changed}, listArgs{})
+		return buf.String(), err
+	}
+
+	var out bytes.Buffer
+	if err := runWatch(ctx, &out, cfg, "list", 10*time.Millisecond, frame); err != nil {
+		t.Fatalf("runWatch() error = %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("rendered %d frames, want 2", len(frames))
+	}
+	if len(frames[0]) != 0 || frames[1][1] != task.StatusTodo {
+		t.Errorf("changed = %v, want task 1 changed from todo in the second frame", frames)
+	}
+	if !strings.Contains(out.String(), "in_progress (was todo)") {
+		t.Errorf("expected highlighted status change, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), clearScreen) {
+		t.Error("expected no screen clearing when not writing to a terminal")
+	}
+}
+
+func TestRunWatch_UnchangedDirRendersOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MCP_TASKS_DIR", tmpDir)
+
+	var stdout, stderr bytes.Buffer
+	if code := RunWithArgs([]string{"mcp-task-manager", "create", "Idle"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("create failed: %s", stderr.String())
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	// Loading rebuilds the missing index, which must not count as a change
+	if err := os.Remove(filepath.Join(cfg.TasksDir(), ".index.json")); err != nil {
+		t.Fatalf("failed to remove index: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	frames := 0
+	frame := func(svc *task.Service, changed map[int]task.Status) (string, error) {
+		frames++
+		return "frame", nil
+	}
+
+	var out bytes.Buffer
+	if err := runWatch(ctx, &out, cfg, "list", 10*time.Millisecond, frame); err != nil {
+		t.Fatalf("runWatch() error = %v", err)
+	}
+	if frames != 1 {
+		t.Errorf("rendered %d frames, want 1", frames)
+	}
+}
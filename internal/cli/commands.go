@@ -1,16 +1,31 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/exporter"
+	"github.com/gpayer/mcp-task-manager/internal/graph"
+	"github.com/gpayer/mcp-task-manager/internal/importer"
 	"github.com/gpayer/mcp-task-manager/internal/storage"
 	"github.com/gpayer/mcp-task-manager/internal/task"
+	"github.com/gpayer/mcp-task-manager/internal/tui"
+	"gopkg.in/yaml.v3"
 )
 
 // loadConfig loads configuration only (does not create directories)
 func loadConfig() (*config.Config, error) {
+	if activeProject != "" {
+		return config.LoadProject(activeProject)
+	}
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
@@ -18,12 +33,41 @@ func loadConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
-// initServiceWithConfig initializes the task service with an already loaded config
+// initServiceWithConfig initializes the task service with an already loaded
+// config. In a workspace, relations into other projects resolve through it.
 func initServiceWithConfig(cfg *config.Config) (*task.Service, error) {
+	svc, err := newService(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := config.FindWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		svc.SetWorkspace(ws.ProjectOf(cfg), workspaceResolver(ws))
+	}
+	return svc, nil
+}
+
+// workspaceResolver opens the projects of a workspace
+func workspaceResolver(ws *config.Workspace) task.ProjectResolver {
+	return task.NewProjectResolver(func(name string) (*task.Service, error) {
+		cfg, err := ws.LoadProject(name)
+		if err != nil {
+			return nil, err
+		}
+		return newService(cfg)
+	})
+}
+
+// newService initializes the task service of a project on its own
+func newService(cfg *config.Config) (*task.Service, error) {
 	tasksDir := cfg.TasksDir()
 	mdStorage := storage.NewMarkdownStorage(tasksDir)
 	index := storage.NewIndex(tasksDir, mdStorage)
-	svc := task.NewService(mdStorage, mdStorage, index, cfg.TaskTypes, cfg)
+	index.SetRelationTypes(cfg.RelationTypes)
+	svc := task.NewService(mdStorage, storage.NewArchiveIndex(mdStorage), index, cfg.TaskTypes, cfg)
 
 	if err := svc.Initialize(); err != nil {
 		return nil, fmt.Errorf("failed to initialize: %w", err)
@@ -57,8 +101,33 @@ func checkProjectExists(stderr io.Writer, cfg *config.Config) int {
 	return 0
 }
 
+// listArgs holds the filters and view options of the list command
+type listArgs struct {
+	Status, Priority, Type string
+	ParentID               int
+	Archived, Duplicates   bool
+	Tree                   bool
+	Depth                  int
+	Watch                  bool
+	Interval               time.Duration
+}
+
 // cmdList handles the list command
-func cmdList(stdout, stderr io.Writer, jsonOutput bool, status, priority, taskType string, parentID int, archived bool) int {
+func cmdList(stdout, stderr io.Writer, jsonOutput bool, format, columns, tmpl string, args listArgs) int {
+	out, err := listOptions(stdout, jsonOutput, format, columns, tmpl)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if args.Tree && args.Archived {
+		fmt.Fprintf(stderr, "Error: --tree cannot be combined with --archived\n")
+		return 1
+	}
+	if args.Tree && (len(out.Columns) > 0 || !slices.Contains([]string{"table", "json", "yaml"}, out.Format)) {
+		fmt.Fprintf(stderr, "Error: --tree supports the table, json and yaml formats without --columns\n")
+		return 1
+	}
+
 	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
@@ -70,53 +139,94 @@ func cmdList(stdout, stderr io.Writer, jsonOutput bool, status, priority, taskTy
 		return code
 	}
 
+	render := func(svc *task.Service, changed map[int]task.Status) (string, error) {
+		var buf bytes.Buffer
+		out.Changed = changed
+		err := writeList(&buf, svc, out, args)
+		return buf.String(), err
+	}
+	if args.Watch {
+		return watchCommand(stdout, stderr, cfg, "list", args.Interval, render)
+	}
+
 	svc, err := initServiceWithConfig(cfg)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
-
-	if archived {
-		tasks, err := svc.ListArchived()
-		if err != nil {
-			fmt.Fprintf(stderr, "Error: %v\n", err)
-			return 1
-		}
-		if jsonOutput {
-			if tasks == nil {
-				tasks = []*task.Task{}
-			}
-			if err := FormatJSON(stdout, tasks); err != nil {
-				fmt.Fprintf(stderr, "Error: %v\n", err)
-				return 1
-			}
-		} else {
-			fmt.Fprint(stdout, FormatTaskTable(tasks, nil, nil))
-		}
-		return 0
+	text, err := render(svc, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
 	}
+	fmt.Fprint(stdout, text)
+	return 0
+}
 
+// writeList writes the tasks selected by args in the format of out
+func writeList(w io.Writer, svc *task.Service, out ListOptions, args listArgs) error {
 	var statusPtr *task.Status
 	var priorityPtr *task.Priority
 	var typePtr *string
 
-	if status != "" {
-		s := task.Status(status)
+	if args.Status != "" {
+		s := task.Status(args.Status)
 		statusPtr = &s
 	}
-	if priority != "" {
-		p := task.Priority(priority)
+	if args.Priority != "" {
+		p := task.Priority(args.Priority)
 		priorityPtr = &p
 	}
-	if taskType != "" {
-		typePtr = &taskType
+	if args.Type != "" {
+		typePtr = &args.Type
+	}
+
+	if args.Archived {
+		// All archived tasks unless --parent selects the subtasks of one
+		var parentPtr *int
+		if args.ParentID != 0 {
+			parentPtr = &args.ParentID
+		}
+		tasks, err := svc.ListArchived(statusPtr, priorityPtr, typePtr, parentPtr)
+		if err != nil {
+			return err
+		}
+		return WriteTaskList(w, tasks, out)
+	}
+
+	if args.Tree {
+		nodes, err := svc.Hierarchy(task.HierarchyFilter{
+			Status:            statusPtr,
+			Priority:          priorityPtr,
+			Type:              typePtr,
+			ParentID:          args.ParentID,
+			MaxDepth:          args.Depth,
+			IncludeDuplicates: args.Duplicates,
+		})
+		if err != nil {
+			return err
+		}
+		if nodes == nil {
+			nodes = []*task.HierarchyNode{}
+		}
+		switch out.Format {
+		case "json":
+			return FormatJSON(w, nodes)
+		case "yaml":
+			return writeYAML(w, nodes)
+		}
+		_, err = io.WriteString(w, FormatTaskHierarchy(nodes, &out))
+		return err
 	}
 
 	// parentID semantics:
 	// - Default (0): show top-level tasks only (parentID = 0)
 	// - Specified N: show subtasks of task N (parentID = N)
-	parentPtr := &parentID
+	parentPtr := &args.ParentID
 	tasks := svc.List(statusPtr, priorityPtr, typePtr, parentPtr)
+	if !args.Duplicates {
+		tasks = svc.WithoutDuplicates(tasks)
+	}
 
 	// Build subtask counts for each task
 	subtaskCounts := make(map[int]SubtaskCounts)
@@ -135,24 +245,13 @@ func cmdList(stdout, stderr io.Writer, jsonOutput bool, status, priority, taskTy
 		}
 	}
 
-	if jsonOutput {
-		// Ensure we always output a JSON array, even if empty
-		if tasks == nil {
-			tasks = []*task.Task{}
-		}
-		if err := FormatJSON(stdout, tasks); err != nil {
-			fmt.Fprintf(stderr, "Error: %v\n", err)
-			return 1
-		}
-	} else {
-		fmt.Fprint(stdout, FormatTaskTable(tasks, subtaskCounts, blockedTasks))
-	}
-
-	return 0
+	out.SubtaskCounts = subtaskCounts
+	out.Blocked = blockedTasks
+	return WriteTaskList(w, tasks, out)
 }
 
 // cmdGet handles the get command
-func cmdGet(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+func cmdGet(stdout, stderr io.Writer, jsonOutput bool, id int, archived, watch bool, interval time.Duration) int {
 	cfg, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
@@ -164,43 +263,58 @@ func cmdGet(stdout, stderr io.Writer, jsonOutput bool, id int) int {
 		return code
 	}
 
+	render := func(svc *task.Service, changed map[int]task.Status) (string, error) {
+		get := svc.GetWithSubtasks
+		if archived {
+			get = svc.GetArchived
+		}
+		t, subtasks, err := get(id)
+		if err != nil {
+			return "", err
+		}
+
+		if jsonOutput {
+			// Include subtasks in JSON output
+			type taskWithSubtasks struct {
+				*task.Task
+				Subtasks []*task.Task `json:"subtasks,omitempty"`
+			}
+			output := taskWithSubtasks{Task: t}
+			if len(subtasks) > 0 {
+				output.Subtasks = subtasks
+			}
+			var buf bytes.Buffer
+			err := FormatJSON(&buf, output)
+			return buf.String(), err
+		}
+		opts := &TaskDetailOptions{
+			Subtasks: subtasks,
+			Changed:  changed,
+			Archived: make(map[string]bool),
+		}
+		for _, ref := range svc.RelationRefs(t) {
+			opts.Archived[ref.Ref()] = ref.Archived
+		}
+		if !archived {
+			opts.Blocked, opts.Blockers = svc.IsBlocked(id)
+		}
+		return FormatTaskDetail(t, opts), nil
+	}
+	if watch {
+		return watchCommand(stdout, stderr, cfg, fmt.Sprintf("get %d", id), interval, render)
+	}
+
 	svc, err := initServiceWithConfig(cfg)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
-
-	t, subtasks, err := svc.GetWithSubtasks(id)
+	text, err := render(svc, nil)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
-
-	blocked, blockers := svc.IsBlocked(id)
-
-	if jsonOutput {
-		// Include subtasks in JSON output
-		type taskWithSubtasks struct {
-			*task.Task
-			Subtasks []*task.Task `json:"subtasks,omitempty"`
-		}
-		output := taskWithSubtasks{Task: t}
-		if len(subtasks) > 0 {
-			output.Subtasks = subtasks
-		}
-		if err := FormatJSON(stdout, output); err != nil {
-			fmt.Fprintf(stderr, "Error: %v\n", err)
-			return 1
-		}
-	} else {
-		opts := &TaskDetailOptions{
-			Subtasks: subtasks,
-			Blocked:  blocked,
-			Blockers: blockers,
-		}
-		fmt.Fprint(stdout, FormatTaskDetail(t, opts))
-	}
-
+	fmt.Fprint(stdout, text)
 	return 0
 }
 
@@ -245,20 +359,81 @@ func cmdNext(stdout, stderr io.Writer, jsonOutput bool) int {
 	return 0
 }
 
+// cmdNextAcrossProjects picks the next task across all workspace projects
+func cmdNextAcrossProjects(stdout, stderr io.Writer, jsonOutput bool) int {
+	ws, err := config.FindWorkspace()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if ws == nil {
+		fmt.Fprintf(stderr, "Error: no %s found (set MCP_WORKSPACE or create one)\n", config.WorkspaceFile)
+		return 1
+	}
+
+	services := make(map[string]*task.Service)
+	resolve := workspaceResolver(ws)
+	for _, name := range ws.Names() {
+		cfg, err := ws.LoadProject(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		// Projects without tasks yet have nothing to offer
+		if _, err := os.Stat(cfg.TasksDir()); err != nil {
+			continue
+		}
+		svc, err := resolve(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: project %s: %v\n", name, err)
+			return 1
+		}
+		services[name] = svc
+	}
+
+	t := task.NextAcrossProjects(services)
+	if t == nil {
+		if jsonOutput {
+			FormatJSON(stdout, map[string]string{"message": "No tasks available"})
+		} else {
+			fmt.Fprintln(stdout, "No tasks available.")
+		}
+		return 0
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, t); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprintf(stdout, "Project:     %s (%s)\n", t.Project, t.Ref)
+		fmt.Fprint(stdout, FormatTaskDetail(t.Task, nil))
+	}
+	return 0
+}
+
 // cmdCreate handles the create command
-func cmdCreate(stdout, stderr io.Writer, jsonOutput bool, title, priority, taskType, description string, parentID int) int {
-	svc, _, err := initService()
+func cmdCreate(stdout, stderr io.Writer, jsonOutput, interactive bool, in createInput) int {
+	svc, cfg, err := initService()
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
 
+	if interactive {
+		if in, err = promptCreate(stdout, svc, cfg.TaskTypes, in); err != nil {
+			fmt.Fprintf(stderr, "\nError: %v\n", err)
+			return 1
+		}
+	}
+
 	var parentPtr *int
-	if parentID > 0 {
-		parentPtr = &parentID
+	if in.ParentID > 0 {
+		parentPtr = &in.ParentID
 	}
 
-	t, err := svc.Create(title, description, task.Priority(priority), taskType, parentPtr)
+	t, err := svc.Create(in.Title, in.Description, task.Priority(in.Priority), in.Type, parentPtr)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
@@ -277,23 +452,20 @@ func cmdCreate(stdout, stderr io.Writer, jsonOutput bool, title, priority, taskT
 }
 
 // cmdUpdate handles the update command
-func cmdUpdate(stdout, stderr io.Writer, jsonOutput bool, id int, title, status, priority, taskType, description string) int {
+func cmdUpdate(stdout, stderr io.Writer, jsonOutput, force bool, id int, title, status, priority, taskType string, description *string) int {
 	svc, _, err := initService()
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	var titlePtr, descPtr, typePtr *string
+	var titlePtr, typePtr *string
 	var statusPtr *task.Status
 	var priorityPtr *task.Priority
 
 	if title != "" {
 		titlePtr = &title
 	}
-	if description != "" {
-		descPtr = &description
-	}
 	if status != "" {
 		s := task.Status(status)
 		statusPtr = &s
@@ -306,7 +478,7 @@ func cmdUpdate(stdout, stderr io.Writer, jsonOutput bool, id int, title, status,
 		typePtr = &taskType
 	}
 
-	t, err := svc.Update(id, titlePtr, descPtr, statusPtr, priorityPtr, typePtr)
+	t, err := svc.Update(id, titlePtr, description, statusPtr, priorityPtr, typePtr, force)
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
@@ -324,6 +496,81 @@ func cmdUpdate(stdout, stderr io.Writer, jsonOutput bool, id int, title, status,
 	return 0
 }
 
+// parseIDList parses a comma-separated list of task IDs
+func parseIDList(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task ID: %s", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// cmdBatchUpdate handles the update command with --ids or --where
+func cmdBatchUpdate(stdout, stderr io.Writer, jsonOutput, force bool, idList, where, status, priority, taskType string, description *string) int {
+	ids, err := parseIDList(idList)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if where != "" {
+		filter, err := task.ParseFilter(where)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		ids = append(ids, svc.FindTasks(filter)...)
+	}
+
+	var typePtr *string
+	var statusPtr *task.Status
+	var priorityPtr *task.Priority
+
+	if status != "" {
+		s := task.Status(status)
+		statusPtr = &s
+	}
+	if priority != "" {
+		p := task.Priority(priority)
+		priorityPtr = &p
+	}
+	if taskType != "" {
+		typePtr = &taskType
+	}
+
+	tasks, err := svc.BatchUpdate(ids, description, statusPtr, priorityPtr, typePtr, force)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, tasks); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprintf(stdout, "Updated %d task(s).\n", len(tasks))
+		fmt.Fprint(stdout, FormatTaskTable(tasks, nil, nil))
+	}
+
+	return 0
+}
+
 // cmdDelete handles the delete command
 func cmdDelete(stdout, stderr io.Writer, jsonOutput bool, id int, force bool) int {
 	svc, _, err := initService()
@@ -376,6 +623,56 @@ func cmdStart(stdout, stderr io.Writer, jsonOutput bool, id int) int {
 	return 0
 }
 
+// cmdReopen handles the reopen command
+func cmdReopen(stdout, stderr io.Writer, jsonOutput bool, id int, reason string) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.ReopenTask(id, reason)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatTransitionResult(fmt.Sprintf("Task #%d reopened.", id), result))
+	}
+	return 0
+}
+
+// cmdCancel handles the cancel command
+func cmdCancel(stdout, stderr io.Writer, jsonOutput bool, id int, reason string) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.CancelTask(id, reason)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatTransitionResult(fmt.Sprintf("Task #%d cancelled.", id), result))
+	}
+	return 0
+}
+
 // cmdArchive handles the archive command
 func cmdArchive(stdout, stderr io.Writer, jsonOutput bool, id int) int {
 	svc, _, err := initService()
@@ -402,28 +699,639 @@ func cmdArchive(stdout, stderr io.Writer, jsonOutput bool, id int) int {
 	return 0
 }
 
-// cmdComplete handles the complete command
-func cmdComplete(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+// cmdAutoArchive handles archive --auto
+func cmdAutoArchive(stdout, stderr io.Writer, jsonOutput, dryRun bool) int {
 	svc, _, err := initService()
 	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	if _, err := svc.CompleteTask(id); err != nil {
+	result, err := svc.AutoArchive(dryRun)
+	if err != nil {
 		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return 1
 	}
 
-	msg := fmt.Sprintf("Task #%d completed.", id)
 	if jsonOutput {
-		if err := FormatJSONMessage(stdout, msg, id); err != nil {
+		if err := FormatJSON(stdout, result); err != nil {
 			fmt.Fprintf(stderr, "Error: %v\n", err)
 			return 1
 		}
 	} else {
-		fmt.Fprintln(stdout, msg)
+		fmt.Fprint(stdout, FormatAutoArchiveResult(result))
 	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(stderr, "Error: failed to archive %s\n", e)
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
 
+// cmdUnarchive handles the unarchive command
+func cmdUnarchive(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.UnarchiveTask(id)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatUnarchiveResult(result))
+	}
+	return 0
+}
+
+// cmdComplete handles the complete command
+func cmdComplete(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if _, err := svc.CompleteTask(id); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	msg := fmt.Sprintf("Task #%d completed.", id)
+	if jsonOutput {
+		if err := FormatJSONMessage(stdout, msg, id); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprintln(stdout, msg)
+	}
+
+	return 0
+}
+
+// readInput reads a file, or stdin when path is "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// cmdImportPlan handles the import-plan command
+func cmdImportPlan(stdout, stderr io.Writer, jsonOutput bool, path string) int {
+	data, err := readInput(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	tree, err := task.ParseTaskTree(data)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.CreateTaskTree(tree)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatTaskTreeResult(result))
+	}
+
+	return 0
+}
+
+// cmdImport handles the import command
+func cmdImport(stdout, stderr io.Writer, jsonOutput bool, format, path, mappingPath string) int {
+	var override *config.ImportMapping
+	if mappingPath != "" {
+		data, err := os.ReadFile(mappingPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		override = &config.ImportMapping{}
+		if err := yaml.Unmarshal(data, override); err != nil {
+			fmt.Fprintf(stderr, "Error: invalid mapping file: %v\n", err)
+			return 1
+		}
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	svc, cfg, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	records, err := importer.Read(format, bytes.NewReader(data), cfg, override)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.Import(records)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(stderr, "Warning: %s\n", w)
+	}
+	fmt.Fprintf(stdout, "Imported %d task(s): %d created, %d updated",
+		len(result.Created)+len(result.Updated), len(result.Created), len(result.Updated))
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(stdout, ", %d archived skipped", len(result.Skipped))
+	}
+	fmt.Fprintln(stdout, ".")
+	return 0
+}
+
+// cmdExport handles the export command
+func cmdExport(stdout, stderr io.Writer, format, outputPath string, includeArchived bool) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Check project exists for read operation
+	if code := checkProjectExists(stderr, cfg); code != 0 {
+		return code
+	}
+
+	svc, err := initServiceWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var archived []*task.Task
+	if includeArchived {
+		archived, err = svc.AllArchived()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	entries := exporter.Entries(svc.AllTasks(), archived)
+
+	if outputPath == "" {
+		if err := exporter.Write(format, stdout, entries); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.Write(format, &buf, entries); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Exported %d task(s) to %s.\n", len(entries), outputPath)
+	return 0
+}
+
+func cmdDeps(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Check project exists for read operation
+	if code := checkProjectExists(stderr, cfg); code != 0 {
+		return code
+	}
+
+	svc, err := initServiceWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	graph, err := svc.GetDependencyGraph(id)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, graph); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatDependencyGraph(graph))
+	}
+	return 0
+}
+
+func cmdCriticalPath(stdout, stderr io.Writer, jsonOutput bool) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Check project exists for read operation
+	if code := checkProjectExists(stderr, cfg); code != 0 {
+		return code
+	}
+
+	svc, err := initServiceWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	path := svc.CriticalPath()
+	if jsonOutput {
+		if err := FormatJSON(stdout, path); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatCriticalPath(path))
+	}
+	return 0
+}
+
+func cmdGraph(stdout, stderr io.Writer, format string, filter task.GraphFilter, outputPath string) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Check project exists for read operation
+	if code := checkProjectExists(stderr, cfg); code != 0 {
+		return code
+	}
+
+	svc, err := initServiceWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	g, err := svc.TaskGraph(filter)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var buf bytes.Buffer
+	if err := graph.Render(&buf, format, g); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if outputPath == "" {
+		stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Wrote graph of %d task(s) to %s.\n", len(g.Tasks), outputPath)
+	return 0
+}
+
+func cmdDuplicate(stdout, stderr io.Writer, jsonOutput bool, id, canonical int, closeDuplicate, merge *bool) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Flags given on the command line override the configured defaults
+	opts := svc.DefaultDuplicateOptions()
+	if closeDuplicate != nil {
+		opts.Close = *closeDuplicate
+	}
+	if merge != nil {
+		opts.Merge = *merge
+	}
+	result, err := svc.MarkDuplicate(id, canonical, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatDuplicateResult(result))
+	}
+	return 0
+}
+
+// cmdMove handles the move command
+func cmdMove(stdout, stderr io.Writer, jsonOutput bool, id, before, after int) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if _, err := svc.MoveTask(id, before, after); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	msg := fmt.Sprintf("Task #%d moved after #%d.", id, after)
+	if before != 0 {
+		msg = fmt.Sprintf("Task #%d moved before #%d.", id, before)
+	}
+	if jsonOutput {
+		if err := FormatJSONMessage(stdout, msg, id); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprintln(stdout, msg)
+	}
+	return 0
+}
+
+// cmdReparent handles the reparent command
+func cmdReparent(stdout, stderr io.Writer, jsonOutput bool, id int, parentID *int) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.SetParent(id, parentID)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatReparentResult(result))
+	}
+	return 0
+}
+
+// cmdSplit handles the split command
+func cmdSplit(stdout, stderr io.Writer, jsonOutput bool, id int, titles []string, by string) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.SplitTask(id, task.SplitOptions{Titles: titles, By: by})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatSplitResult(result))
+	}
+	return 0
+}
+
+// cmdMerge handles the merge command
+func cmdMerge(stdout, stderr io.Writer, jsonOutput bool, ids []int) int {
+	svc, _, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := svc.MergeTasks(ids)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		if err := FormatJSON(stdout, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		fmt.Fprint(stdout, FormatMergeResult(result))
+	}
+	return 0
+}
+
+// editErrorPrefix marks the lines cmdEdit adds to report a rejected edit
+const editErrorPrefix = "# edit error: "
+
+// editorCommand returns the command that opens path in $EDITOR (vi if unset)
+func editorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd
+}
+
+// withEditError adds an error comment to the frontmatter of an edited task,
+// replacing any earlier one
+func withEditError(data []byte, err error) []byte {
+	lines := strings.Split(string(stripEditError(data)), "\n")
+	comment := []string{
+		editErrorPrefix + strings.ReplaceAll(err.Error(), "\n", " "),
+		editErrorPrefix + "fix the task and save again, or quit without saving to abort",
+	}
+	at := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		at = 1
+	}
+	lines = append(lines[:at], append(comment, lines[at:]...)...)
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// stripEditError removes the lines added by withEditError
+func stripEditError(data []byte) []byte {
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, editErrorPrefix) {
+			kept = append(kept, line)
+		}
+	}
+	return []byte(strings.Join(kept, "\n"))
+}
+
+// cmdEdit handles the edit command: the task file is opened in $EDITOR and
+// saved back through the service, re-opening the editor until the edit is
+// valid or left unchanged
+func cmdEdit(stdout, stderr io.Writer, jsonOutput bool, id int) int {
+	svc, cfg, err := initService()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	mdStorage := storage.NewMarkdownStorage(cfg.TasksDir())
+	if mdStorage.IsArchived(id) {
+		fmt.Fprintf(stderr, "Error: task %d is archived and cannot be edited\n", id)
+		return 1
+	}
+	original, err := svc.Get(id)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	before, err := mdStorage.Marshal(original)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("task-%03d-*.md", id))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	written := before
+	for {
+		if err := os.WriteFile(path, written, 0644); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		if err := editorCommand(path).Run(); err != nil {
+			fmt.Fprintf(stderr, "Error: editor failed: %v\n", err)
+			return 1
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		if bytes.Equal(data, before) {
+			fmt.Fprintln(stdout, "No changes.")
+			return 0
+		}
+		if !bytes.Equal(written, before) && bytes.Equal(data, written) {
+			fmt.Fprintf(stderr, "Error: edit aborted, task %d unchanged\n", id)
+			return 1
+		}
+
+		edited, err := mdStorage.Parse(stripEditError(data))
+		var saved *task.Task
+		changed := false
+		if err == nil {
+			saved, changed, err = svc.ApplyEdit(id, edited)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			written = withEditError(data, err)
+			continue
+		}
+		if !changed {
+			fmt.Fprintln(stdout, "No changes.")
+			return 0
+		}
+
+		if jsonOutput {
+			if err := FormatJSON(stdout, saved); err != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+		after, err := mdStorage.Marshal(saved)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Task #%d updated:\n", id)
+		fmt.Fprint(stdout, FormatDiff(string(before), string(after)))
+		return 0
+	}
+}
+
+// cmdTUI handles the tui command
+func cmdTUI(stderr io.Writer, interval time.Duration) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Check project exists for read operation
+	if code := checkProjectExists(stderr, cfg); code != 0 {
+		return code
+	}
+
+	err = tui.Run(tui.Options{
+		TasksDir: cfg.TasksDir(),
+		Load:     func() (*task.Service, error) { return initServiceWithConfig(cfg) },
+		Interval: interval,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
 	return 0
 }
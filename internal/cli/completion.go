@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/exporter"
+	"github.com/gpayer/mcp-task-manager/internal/graph"
+	"github.com/gpayer/mcp-task-manager/internal/importer"
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+	"github.com/integrii/flaggy"
+)
+
+// completeCommand is the hidden command the completion scripts call with the
+// words typed so far; it prints one "value<TAB>description" line per candidate
+const completeCommand = "__complete"
+
+// completionShells are the shells completion scripts are generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+const bashCompletion = `# bash completion for mcp-task-manager
+_mcp_task_manager_complete() {
+    local IFS=$'\n'
+    COMPREPLY=( $(mcp-task-manager __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1) )
+}
+complete -o default -F _mcp_task_manager_complete mcp-task-manager
+`
+
+const zshCompletion = `#compdef mcp-task-manager
+
+_mcp_task_manager() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(mcp-task-manager __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        if [[ $line == *$'\t'* ]]; then
+            candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${line//:/\\:}")
+        fi
+    done
+    if (( ${#candidates} )); then
+        _describe 'values' candidates
+    else
+        _files
+    fi
+}
+
+compdef _mcp_task_manager mcp-task-manager
+`
+
+const fishCompletion = `# fish completion for mcp-task-manager
+function __mcp_task_manager_complete
+    set -l words (commandline -opc) (commandline -ct)
+    mcp-task-manager __complete $words[2..-1] 2>/dev/null
+end
+complete -c mcp-task-manager -f -a '(__mcp_task_manager_complete)'
+complete -c mcp-task-manager -n 'test -z "$(__mcp_task_manager_complete)"' -F
+`
+
+// cmdCompletion handles the completion command
+func cmdCompletion(stdout, stderr io.Writer, shell string) int {
+	switch shell {
+	case "bash":
+		fmt.Fprint(stdout, bashCompletion)
+	case "zsh":
+		fmt.Fprint(stdout, zshCompletion)
+	case "fish":
+		fmt.Fprint(stdout, fishCompletion)
+	default:
+		fmt.Fprintf(stderr, "Error: unsupported shell: %s (use %s)\n", shell, strings.Join(completionShells, "|"))
+		return 1
+	}
+	return 0
+}
+
+// completeArgs returns the candidates for the last of words, given the words
+// before it, as "value\tdescription" lines
+func completeArgs(root *flaggy.Subcommand, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur, typed := words[len(words)-1], words[:len(words)-1]
+
+	var sc *flaggy.Subcommand
+	var valueFlag *flaggy.Flag
+	var positionals []string
+	for i := 0; i < len(typed); i++ {
+		w := typed[i]
+		switch {
+		case strings.HasPrefix(w, "-") && w != "-":
+			f := findFlag(w, sc, root)
+			if f == nil || strings.Contains(w, "=") || !takesValue(f) {
+				continue
+			}
+			if i == len(typed)-1 {
+				valueFlag = f
+			}
+			i++ // Skip the flag's value
+		case sc == nil:
+			sc = findSubcommand(root, w)
+		default:
+			positionals = append(positionals, w)
+		}
+	}
+
+	var candidates []string
+	switch {
+	case valueFlag != nil:
+		candidates = completeValue(sc, valueFlag.LongName, cur)
+	case strings.HasPrefix(cur, "-"):
+		for _, cmd := range []*flaggy.Subcommand{sc, root} {
+			if cmd == nil {
+				continue
+			}
+			for _, f := range cmd.Flags {
+				if f.Hidden {
+					continue
+				}
+				if f.LongName != "" {
+					candidates = append(candidates, "--"+f.LongName+"\t"+f.Description)
+				}
+				if f.ShortName != "" {
+					candidates = append(candidates, "-"+f.ShortName+"\t"+f.Description)
+				}
+			}
+		}
+	case sc == nil:
+		for _, cmd := range root.Subcommands {
+			if !cmd.Hidden {
+				candidates = append(candidates, cmd.Name+"\t"+cmd.Description)
+			}
+		}
+	default:
+		for _, pv := range sc.PositionalFlags {
+			if pv.Position == len(positionals)+1 {
+				candidates = completeValue(sc, pv.Name, cur)
+			}
+		}
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// findFlag looks up a flag given as -s, --long or --long=value
+func findFlag(word string, cmds ...*flaggy.Subcommand) *flaggy.Flag {
+	name, _, _ := strings.Cut(strings.TrimLeft(word, "-"), "=")
+	for _, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		for _, f := range cmd.Flags {
+			if f.HasName(name) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func findSubcommand(root *flaggy.Subcommand, name string) *flaggy.Subcommand {
+	for _, cmd := range root.Subcommands {
+		if cmd.Name == name || (cmd.ShortName != "" && cmd.ShortName == name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// takesValue reports whether a flag is followed by a value (all but bool flags)
+func takesValue(f *flaggy.Flag) bool {
+	_, isBool := f.AssignmentVar.(*bool)
+	return !isBool
+}
+
+// completeValue returns candidates for a flag or positional value. Values
+// in comma-separated lists are completed after the last comma.
+func completeValue(sc *flaggy.Subcommand, name, cur string) []string {
+	cmdName := ""
+	if sc != nil {
+		cmdName = sc.Name
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	var values []string
+	switch name {
+	case "status":
+		values = []string{string(task.StatusTodo), string(task.StatusInProgress), string(task.StatusDone)}
+	case "priority":
+		values = []string{string(task.PriorityCritical), string(task.PriorityHigh), string(task.PriorityMedium), string(task.PriorityLow)}
+	case "type":
+		values = cfg.TaskTypes
+	case "edges":
+		values = append([]string{task.EdgeParent}, cfg.RelationTypeNames()...)
+	case "by":
+		values = []string{task.SplitByChecklist, task.SplitBySections}
+	case "shell":
+		values = completionShells
+	case "project":
+		if ws, err := config.FindWorkspace(); err == nil && ws != nil {
+			values = ws.Names()
+		}
+	case "format":
+		switch cmdName {
+		case "export":
+			values = exporter.Formats()
+		case "import":
+			values = importer.Names()
+		default:
+			values = graph.Formats()
+		}
+	case "columns":
+		values = ColumnNames()
+	case "id", "ids", "canonical", "parent", "before", "after", "root":
+		return withListPrefix(cur, taskIDCandidates(cfg, cmdName))
+	}
+	return withListPrefix(cur, values)
+}
+
+// withListPrefix prepends the completed part of a comma-separated list
+func withListPrefix(cur string, values []string) []string {
+	prefix := ""
+	if i := strings.LastIndex(cur, ","); i >= 0 {
+		prefix = cur[:i+1]
+	}
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = prefix + v
+	}
+	return result
+}
+
+// taskIDCandidates lists task IDs from the index with their titles, limited
+// to the statuses a command accepts
+func taskIDCandidates(cfg *config.Config, cmdName string) []string {
+	if cmdName == "unarchive" {
+		return archivedIDCandidates(cfg)
+	}
+	data, err := os.ReadFile(filepath.Join(cfg.TasksDir(), ".index.json"))
+	if err != nil {
+		return nil
+	}
+	var index storage.IndexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil
+	}
+	accepts := func(s task.Status) bool {
+		switch cmdName {
+		case "start":
+			return s == task.StatusTodo
+		case "complete":
+			return s == task.StatusInProgress
+		case "reopen", "archive":
+			return s == task.StatusDone
+		case "cancel":
+			return s != task.StatusDone
+		}
+		return true
+	}
+	sort.Slice(index.Tasks, func(i, j int) bool { return index.Tasks[i].ID < index.Tasks[j].ID })
+	var candidates []string
+	for _, e := range index.Tasks {
+		if accepts(e.Status) {
+			candidates = append(candidates, strconv.Itoa(e.ID)+"\t"+e.Title+" ("+string(e.Status)+")")
+		}
+	}
+	return candidates
+}
+
+// archivedIDCandidates lists the top-level archived tasks from the archive
+// index; archived subtasks are unarchived with their parent
+func archivedIDCandidates(cfg *config.Config) []string {
+	data, err := os.ReadFile(filepath.Join(cfg.TasksDir(), "archive", ".index.json"))
+	if err != nil {
+		return nil
+	}
+	var index storage.ArchiveIndexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil
+	}
+	archived := make(map[int]bool, len(index.Tasks))
+	for _, e := range index.Tasks {
+		archived[e.ID] = true
+	}
+	sort.Slice(index.Tasks, func(i, j int) bool { return index.Tasks[i].ID < index.Tasks[j].ID })
+	var candidates []string
+	for _, e := range index.Tasks {
+		if e.ParentID == nil || !archived[*e.ParentID] {
+			candidates = append(candidates, strconv.Itoa(e.ID)+"\t"+e.Title+" ("+string(e.Status)+")")
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,612 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+	"gopkg.in/yaml.v3"
+)
+
+// ListFormats are the output formats of the list command
+var ListFormats = []string{"table", "json", "yaml", "csv", "markdown", "template"}
+
+// DefaultColumns are the columns shown when --columns is not given
+var DefaultColumns = []string{"id", "title", "status", "priority", "type", "subtasks"}
+
+// ListOptions controls how WriteTaskList renders tasks
+type ListOptions struct {
+	Format        string   // One of ListFormats; empty means table
+	Columns       []string // Empty means DefaultColumns, or whole tasks for json and yaml
+	Template      string   // text/template executed once per task by the template format
+	Width         int      // Table width titles are truncated to fit; 0 truncates them at 40 characters
+	Color         bool     // Colour status, priority and blocked markers in tables
+	SubtaskCounts map[int]SubtaskCounts
+	Blocked       map[int]bool
+	Changed       map[int]task.Status // Previous status of tasks to highlight (watch mode)
+}
+
+// listColumn describes one column of a task list
+type listColumn struct {
+	Header string
+	value  func(t *task.Task, opts *ListOptions) any // Typed value for json and yaml
+	text   func(t *task.Task, opts *ListOptions) string
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
+
+var listColumns = map[string]listColumn{
+	"id": {
+		Header: "ID",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.ID },
+		text:   func(t *task.Task, _ *ListOptions) string { return strconv.Itoa(t.ID) },
+	},
+	"title": {
+		Header: "Title",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.Title },
+		text:   func(t *task.Task, _ *ListOptions) string { return t.Title },
+	},
+	"status": {
+		Header: "Status",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.Status },
+		text: func(t *task.Task, _ *ListOptions) string {
+			if t.Resolution != "" {
+				return string(t.Status) + " (" + t.Resolution + ")"
+			}
+			return string(t.Status)
+		},
+	},
+	"priority": {
+		Header: "Priority",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.Priority },
+		text:   func(t *task.Task, _ *ListOptions) string { return string(t.Priority) },
+	},
+	"type": {
+		Header: "Type",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.Type },
+		text:   func(t *task.Task, _ *ListOptions) string { return t.Type },
+	},
+	"tags": {
+		Header: "Tags",
+		value: func(t *task.Task, _ *ListOptions) any {
+			if t.Tags == nil {
+				return []string{}
+			}
+			return t.Tags
+		},
+		text: func(t *task.Task, _ *ListOptions) string { return strings.Join(t.Tags, ", ") },
+	},
+	"parent": {
+		Header: "Parent",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.ParentID },
+		text: func(t *task.Task, _ *ListOptions) string {
+			if t.ParentID == nil {
+				return ""
+			}
+			return strconv.Itoa(*t.ParentID)
+		},
+	},
+	"subtasks": {
+		Header: "Subtasks",
+		value: func(t *task.Task, opts *ListOptions) any {
+			c := opts.SubtaskCounts[t.ID]
+			return map[string]int{"done": c.Done, "total": c.Total}
+		},
+		text: func(t *task.Task, opts *ListOptions) string {
+			if c, ok := opts.SubtaskCounts[t.ID]; ok && c.Total > 0 {
+				return fmt.Sprintf("[%d/%d]", c.Done, c.Total)
+			}
+			return ""
+		},
+	},
+	"blocked": {
+		Header: "Blocked",
+		value:  func(t *task.Task, opts *ListOptions) any { return opts.Blocked[t.ID] },
+		text:   func(t *task.Task, opts *ListOptions) string { return strconv.FormatBool(opts.Blocked[t.ID]) },
+	},
+	"external": {
+		Header: "External",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.ExternalID },
+		text:   func(t *task.Task, _ *ListOptions) string { return t.ExternalID },
+	},
+	"created": {
+		Header: "Created",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.CreatedAt },
+		text:   func(t *task.Task, _ *ListOptions) string { return formatTime(t.CreatedAt) },
+	},
+	"updated": {
+		Header: "Updated",
+		value:  func(t *task.Task, _ *ListOptions) any { return t.UpdatedAt },
+		text:   func(t *task.Task, _ *ListOptions) string { return formatTime(t.UpdatedAt) },
+	},
+}
+
+// ColumnNames returns the names accepted by --columns
+func ColumnNames() []string {
+	return []string{"id", "title", "status", "priority", "type", "tags", "parent", "subtasks", "blocked", "external", "created", "updated"}
+}
+
+// parseColumns splits a comma-separated column list
+func parseColumns(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var columns []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := listColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column: %q (available: %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+// listOptions resolves the output flags of the list command; --json is short
+// for --format json, and --template alone selects the template format
+func listOptions(w io.Writer, jsonOutput bool, format, columns, tmpl string) (ListOptions, error) {
+	switch {
+	case jsonOutput && format != "" && format != "json":
+		return ListOptions{}, fmt.Errorf("--json cannot be combined with --format %s", format)
+	case jsonOutput:
+		format = "json"
+	case format == "" && tmpl != "":
+		format = "template"
+	case format == "":
+		format = "table"
+	}
+	if !slices.Contains(ListFormats, format) {
+		return ListOptions{}, fmt.Errorf("unknown format: %q (use %s)", format, strings.Join(ListFormats, "|"))
+	}
+	if format == "template" && tmpl == "" {
+		return ListOptions{}, fmt.Errorf("--format template requires --template")
+	}
+	cols, err := parseColumns(columns)
+	if err != nil {
+		return ListOptions{}, err
+	}
+	opts := ListOptions{Format: format, Columns: cols, Template: tmpl}
+	if format == "table" {
+		opts.Width, opts.Color = terminalOutput(w)
+	}
+	return opts, nil
+}
+
+// TemplateTask is the data a --template is executed with for each task
+type TemplateTask struct {
+	*task.Task
+	Blocked  bool
+	Subtasks SubtaskCounts
+}
+
+var templateFuncs = template.FuncMap{
+	"join":  func(elems []string, sep string) string { return strings.Join(elems, sep) },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"date":  formatTime,
+}
+
+// WriteTaskList writes tasks in the format and with the columns of opts
+func WriteTaskList(w io.Writer, tasks []*task.Task, opts ListOptions) error {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+	switch opts.Format {
+	case "", "table":
+		_, err := io.WriteString(w, formatTable(tasks, columns, &opts))
+		return err
+	case "json", "yaml":
+		var data any = tasks
+		if tasks == nil {
+			data = []*task.Task{}
+		}
+		if len(opts.Columns) > 0 {
+			records := make([]taskRecord, len(tasks))
+			for i, t := range tasks {
+				for _, name := range columns {
+					records[i] = append(records[i], recordField{name, listColumns[name].value(t, &opts)})
+				}
+			}
+			data = records
+		}
+		if opts.Format == "json" {
+			return FormatJSON(w, data)
+		}
+		return writeYAML(w, data)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(columns)
+		for _, t := range tasks {
+			row := make([]string, len(columns))
+			for i, name := range columns {
+				row[i] = listColumns[name].text(t, &opts)
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	case "markdown":
+		_, err := io.WriteString(w, formatMarkdownTable(tasks, columns, &opts))
+		return err
+	case "template":
+		if opts.Template == "" {
+			return fmt.Errorf("--format template requires --template")
+		}
+		tmpl, err := template.New("task").Funcs(templateFuncs).Parse(opts.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		for _, t := range tasks {
+			var buf bytes.Buffer
+			data := TemplateTask{Task: t, Blocked: opts.Blocked[t.ID], Subtasks: opts.SubtaskCounts[t.ID]}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return fmt.Errorf("template failed for task %d: %w", t.ID, err)
+			}
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteByte('\n')
+			}
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format: %q (use %s)", opts.Format, strings.Join(ListFormats, "|"))
+}
+
+// recordField is one column of a taskRecord
+type recordField struct {
+	Key   string
+	Value any
+}
+
+// taskRecord is a task reduced to the selected columns, marshaled in column order
+type taskRecord []recordField
+
+func (r taskRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes data as YAML with the field names and order of its JSON
+// form (task descriptions are not part of the YAML frontmatter fields)
+func writeYAML(w io.Writer, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML; parsing it keeps key order, then block style is restored
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	var unstyle func(n *yaml.Node)
+	unstyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			unstyle(c)
+		}
+	}
+	unstyle(&doc)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ANSI colours used in tables
+const (
+	colorRed     = "31"
+	colorGreen   = "32"
+	colorYellow  = "33"
+	colorBold    = "1"
+	colorDim     = "2"
+	colorReverse = "7"
+)
+
+func colorize(s string, codes ...string) string {
+	if s == "" || len(codes) == 0 {
+		return s
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + s + "\x1b[0m"
+}
+
+func statusColor(t *task.Task) []string {
+	switch {
+	case t.Resolution != "":
+		return []string{colorDim}
+	case t.Status == task.StatusInProgress:
+		return []string{colorYellow}
+	case t.Status == task.StatusDone:
+		return []string{colorGreen}
+	}
+	return nil
+}
+
+func priorityColor(p task.Priority) []string {
+	switch p {
+	case task.PriorityCritical:
+		return []string{colorBold, colorRed}
+	case task.PriorityHigh:
+		return []string{colorRed}
+	case task.PriorityLow:
+		return []string{colorDim}
+	}
+	return nil
+}
+
+// tableCell is a cell's plain text, used for widths, and what is printed
+type tableCell struct {
+	text   string
+	styled string
+}
+
+// changedMarker notes the previous status of a task highlighted in watch mode
+func changedMarker(opts *ListOptions, id int) string {
+	if old, ok := opts.Changed[id]; ok {
+		return " (was " + string(old) + ")"
+	}
+	return ""
+}
+
+// humanCell is a column's text in tables, with the blocked and changed
+// markers and colours
+func humanCell(name string, t *task.Task, opts *ListOptions) tableCell {
+	text := listColumns[name].text(t, opts)
+	c := tableCell{text: text, styled: text}
+	if name == "status" {
+		changed := changedMarker(opts, t.ID)
+		blocked := ""
+		if opts.Blocked[t.ID] {
+			blocked = " [BLOCKED]"
+		}
+		c.text += changed + blocked
+		c.styled = c.text
+		if opts.Color {
+			c.styled = colorize(text, statusColor(t)...)
+			if changed != "" {
+				c.styled = colorize(text+changed, colorReverse)
+			}
+			if blocked != "" {
+				c.styled += " " + colorize("[BLOCKED]", colorRed)
+			}
+		}
+		return c
+	}
+	if !opts.Color {
+		return c
+	}
+	switch name {
+	case "priority":
+		c.styled = colorize(text, priorityColor(t.Priority)...)
+	case "blocked":
+		if opts.Blocked[t.ID] {
+			c.styled = colorize(text, colorRed)
+		}
+	}
+	return c
+}
+
+// truncate shortens s to at most width runes, ending with "..."
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string([]rune(s)[:width])
+	}
+	return string([]rune(s)[:width-3]) + "..."
+}
+
+const (
+	tableGap      = 3  // Spaces between columns
+	maxTitleWidth = 40 // Title width when the table width is unknown
+	minTitleWidth = 10 // Narrowest title a narrow terminal shrinks it to
+)
+
+// formatTable aligns the columns, shrinking titles to fit opts.Width
+func formatTable(tasks []*task.Task, columns []string, opts *ListOptions) string {
+	if len(tasks) == 0 {
+		return "No tasks found."
+	}
+
+	rows := make([][]tableCell, len(tasks))
+	widths := make([]int, len(columns))
+	titleCol := -1
+	for i, name := range columns {
+		widths[i] = len(listColumns[name].Header)
+		if name == "title" {
+			titleCol = i
+		}
+	}
+	for r, t := range tasks {
+		rows[r] = make([]tableCell, len(columns))
+		for i, name := range columns {
+			rows[r][i] = humanCell(name, t, opts)
+			widths[i] = max(widths[i], utf8.RuneCountInString(rows[r][i].text))
+		}
+	}
+
+	if titleCol >= 0 {
+		limit := maxTitleWidth
+		if opts.Width > 0 {
+			others := tableGap * (len(columns) - 1)
+			for i, w := range widths {
+				if i != titleCol {
+					others += w
+				}
+			}
+			limit = max(opts.Width-others, minTitleWidth)
+		}
+		if widths[titleCol] > limit {
+			widths[titleCol] = limit
+			for _, row := range rows {
+				row[titleCol].text = truncate(row[titleCol].text, limit)
+				row[titleCol].styled = row[titleCol].text
+			}
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []tableCell) {
+		var line strings.Builder
+		for i, c := range cells {
+			line.WriteString(c.styled)
+			if i < len(cells)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c.text)+tableGap))
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	header := make([]tableCell, len(columns))
+	for i, name := range columns {
+		h := listColumns[name].Header
+		header[i] = tableCell{text: h, styled: h}
+		if opts.Color {
+			header[i].styled = colorize(h, colorBold)
+		}
+	}
+	writeRow(header)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
+
+// formatMarkdownTable formats tasks as a GitHub-flavoured Markdown table
+func formatMarkdownTable(tasks []*task.Task, columns []string, opts *ListOptions) string {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	var sb strings.Builder
+	headers := make([]string, len(columns))
+	for i, name := range columns {
+		headers[i] = listColumns[name].Header
+	}
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	plain := *opts
+	plain.Color = false
+	for _, t := range tasks {
+		cells := make([]string, len(columns))
+		for i, name := range columns {
+			cells[i] = escape.Replace(humanCell(name, t, &plain).text)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return sb.String()
+}
+
+// terminalOutput reports the width of w and whether to use colour, when w
+// is a terminal. COLUMNS overrides the width; NO_COLOR disables colour.
+func terminalOutput(w io.Writer) (width int, color bool) {
+	if isTerminal(w) {
+		width, _, _ = term.GetSize(w.(*os.File).Fd())
+		color = os.Getenv("NO_COLOR") == ""
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		width = n
+	}
+	return width, color
+}
+
+// statusGlyph is the symbol for a task's status in tree output
+func statusGlyph(t *task.Task) string {
+	switch {
+	case t.Resolution != "":
+		return "✗"
+	case t.Status == task.StatusInProgress:
+		return "◐"
+	case t.Status == task.StatusDone:
+		return "●"
+	}
+	return "○"
+}
+
+// FormatTaskHierarchy draws tasks with their subtasks indented below them.
+// Titles are shortened to fit opts.Width; context tasks, shown only for a
+// matching descendant, are dimmed when opts.Color is set.
+func FormatTaskHierarchy(nodes []*task.HierarchyNode, opts *ListOptions) string {
+	if len(nodes) == 0 {
+		return "No tasks found."
+	}
+	var sb strings.Builder
+	var write func(nodes []*task.HierarchyNode, indent string, top bool)
+	write = func(nodes []*task.HierarchyNode, indent string, top bool) {
+		for i, n := range nodes {
+			branch, next := "", ""
+			if !top {
+				branch, next = "├── ", "│   "
+				if i == len(nodes)-1 {
+					branch, next = "└── ", "    "
+				}
+			}
+			glyph, title := statusGlyph(n.Task), n.Title
+			blocked, collapsed := "", ""
+			if n.Blocked {
+				blocked = " [BLOCKED]"
+			}
+			if n.Collapsed > 0 {
+				collapsed = fmt.Sprintf(" (+%d subtasks)", n.Collapsed)
+			}
+			changed := changedMarker(opts, n.ID)
+			head := fmt.Sprintf("%s%s%s #%d ", indent, branch, glyph, n.ID)
+			if opts.Width > 0 {
+				room := opts.Width - utf8.RuneCountInString(head+changed+blocked+collapsed)
+				title = truncate(title, max(room, minTitleWidth))
+			}
+			if opts.Color {
+				glyph = colorize(glyph, statusColor(n.Task)...)
+				if n.Context {
+					title = colorize(title, colorDim)
+				}
+				if n.Blocked {
+					blocked = " " + colorize("[BLOCKED]", colorRed)
+				}
+				if changed != "" {
+					changed = " " + colorize(strings.TrimPrefix(changed, " "), colorReverse)
+				}
+				head = fmt.Sprintf("%s%s%s #%d ", indent, branch, glyph, n.ID)
+			}
+			sb.WriteString(head + title + changed + blocked + collapsed + "\n")
+			write(n.Subtasks, indent+next, false)
+		}
+	}
+	write(nodes, "", true)
+	return sb.String()
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(f.Fd())
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// stdin is read for "-" arguments and interactive prompts; replaced in tests
+var stdin io.Reader = os.Stdin
+
+// resolveDescription returns the description given with -d or
+// --description-file; "-" reads it from stdin. It returns nil if no
+// description was given; an empty file or stdin yields an empty description.
+func resolveDescription(description, path string) (*string, error) {
+	switch {
+	case description != "" && path != "":
+		return nil, fmt.Errorf("use either --description or --description-file, not both")
+	case path != "":
+	case description == "-":
+		path = "-"
+	case description == "":
+		return nil, nil
+	default:
+		return &description, nil
+	}
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read description: %w", err)
+	}
+	desc := strings.TrimSpace(string(data))
+	return &desc, nil
+}
+
+// createInput holds the fields of a task to create
+type createInput struct {
+	Title       string
+	Priority    string
+	Type        string
+	Description string
+	ParentID    int
+}
+
+// prompter asks questions and reads the answers line by line
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask reads one answer; an empty answer selects def
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("input ended before the task was complete")
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// completions returns the options an answer refers to: an exact match, or
+// all options starting with it
+func completions(options []string, answer string) []string {
+	var matches []string
+	for _, o := range options {
+		if strings.EqualFold(o, answer) {
+			return []string{o}
+		}
+		if strings.HasPrefix(strings.ToLower(o), strings.ToLower(answer)) {
+			matches = append(matches, o)
+		}
+	}
+	return matches
+}
+
+// choose asks for one of options, completing unique prefixes
+func (p *prompter) choose(question string, options []string, def string) (string, error) {
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		matches := completions(options, answer)
+		switch len(matches) {
+		case 1:
+			if matches[0] != answer {
+				fmt.Fprintf(p.out, "  -> %s\n", matches[0])
+			}
+			return matches[0], nil
+		case 0:
+			fmt.Fprintf(p.out, "  %q is not one of: %s\n", answer, strings.Join(options, ", "))
+		default:
+			fmt.Fprintf(p.out, "  %q matches %s\n", answer, strings.Join(matches, ", "))
+		}
+	}
+}
+
+// chooseParent asks for a parent task by ID or part of its title; 0 means none
+func (p *prompter) chooseParent(svc *task.Service, def int) (int, error) {
+	defText := ""
+	if def > 0 {
+		defText = strconv.Itoa(def)
+	}
+	for {
+		answer, err := p.ask("Parent (ID or part of the title, ? to list, 0 for none)", defText)
+		if err != nil {
+			return 0, err
+		}
+		if answer == "" || answer == "0" {
+			return 0, nil
+		}
+		if id, err := strconv.Atoi(strings.TrimPrefix(answer, "#")); err == nil {
+			if _, err := svc.Get(id); err != nil {
+				fmt.Fprintf(p.out, "  %v\n", err)
+				continue
+			}
+			return id, nil
+		}
+
+		var matches []*task.Task
+		for _, t := range svc.AllTasks() {
+			if t.Status == task.StatusDone {
+				continue
+			}
+			if answer == "?" || strings.Contains(strings.ToLower(t.Title), strings.ToLower(answer)) {
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) == 1 && answer != "?" {
+			fmt.Fprintf(p.out, "  -> #%d %s\n", matches[0].ID, matches[0].Title)
+			return matches[0].ID, nil
+		}
+		if len(matches) == 0 {
+			fmt.Fprintf(p.out, "  no open task matches %q\n", answer)
+			continue
+		}
+		for _, t := range matches {
+			fmt.Fprintf(p.out, "  #%d %s\n", t.ID, t.Title)
+		}
+	}
+}
+
+// readDescription reads lines up to a line containing only "." or the end of input
+func (p *prompter) readDescription() string {
+	fmt.Fprintln(p.out, `Description (end with a line containing only "."):`)
+	var lines []string
+	for {
+		line, err := p.in.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			break
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// promptCreate asks for the fields of a new task, offering the values given
+// as flags as defaults
+func promptCreate(out io.Writer, svc *task.Service, taskTypes []string, in createInput) (createInput, error) {
+	p := &prompter{in: bufio.NewReader(stdin), out: out}
+	for {
+		title, err := p.ask("Title", in.Title)
+		if err != nil {
+			return in, err
+		}
+		if title != "" {
+			in.Title = title
+			break
+		}
+	}
+	var err error
+	if in.Type, err = p.choose("Type", taskTypes, in.Type); err != nil {
+		return in, err
+	}
+	priorities := []string{string(task.PriorityCritical), string(task.PriorityHigh), string(task.PriorityMedium), string(task.PriorityLow)}
+	if in.Priority, err = p.choose("Priority", priorities, in.Priority); err != nil {
+		return in, err
+	}
+	if in.ParentID, err = p.chooseParent(svc, in.ParentID); err != nil {
+		return in, err
+	}
+	if in.Description == "" {
+		in.Description = p.readDescription()
+	}
+	return in, nil
+}
@@ -5,16 +5,26 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/gpayer/mcp-task-manager/internal/task"
 )
 
 // TaskDetailOptions holds optional display information for FormatTaskDetail
 type TaskDetailOptions struct {
-	Subtasks []*task.Task
+	Subtasks []*task.Task // All levels, each parent before its own subtasks
 	Blocked  bool
 	Blockers []task.BlockingInfo
+	Changed  map[int]task.Status // Previous status of tasks to highlight (watch mode)
+	Archived map[string]bool     // Relation targets that are archived, by reference (#3, api#3)
+}
+
+// changed returns the previous status of a task whose status changed
+func (o *TaskDetailOptions) changed(id int) (task.Status, bool) {
+	if o == nil {
+		return "", false
+	}
+	old, ok := o.Changed[id]
+	return old, ok
 }
 
 // FormatTaskDetail formats a single task for human-readable output
@@ -23,12 +33,24 @@ func FormatTaskDetail(t *task.Task, opts *TaskDetailOptions) string {
 	sb.WriteString(fmt.Sprintf("Task #%d\n", t.ID))
 	sb.WriteString(fmt.Sprintf("Title:       %s\n", t.Title))
 	status := string(t.Status)
+	if t.Resolution != "" {
+		status += " (" + t.Resolution + ")"
+	}
+	if old, ok := opts.changed(t.ID); ok {
+		status += " (was " + string(old) + ")"
+	}
 	if opts != nil && opts.Blocked {
 		status += " [BLOCKED]"
 	}
 	sb.WriteString(fmt.Sprintf("Status:      %s\n", status))
 	sb.WriteString(fmt.Sprintf("Priority:    %s\n", t.Priority))
 	sb.WriteString(fmt.Sprintf("Type:        %s\n", t.Type))
+	if len(t.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("Tags:        %s\n", strings.Join(t.Tags, ", ")))
+	}
+	if t.ExternalID != "" {
+		sb.WriteString(fmt.Sprintf("External:    %s\n", t.ExternalID))
+	}
 	if t.ParentID != nil {
 		sb.WriteString(fmt.Sprintf("Parent:      #%d\n", *t.ParentID))
 	}
@@ -37,22 +59,47 @@ func FormatTaskDetail(t *task.Task, opts *TaskDetailOptions) string {
 	if len(t.Relations) > 0 {
 		sb.WriteString("\nRelations:\n")
 		for _, rel := range t.Relations {
-			sb.WriteString(fmt.Sprintf("  %s -> #%d\n", rel.Type, rel.Task))
+			line := fmt.Sprintf("  %s -> %s", rel.Type, rel.Ref())
+			if opts != nil && opts.Archived[rel.Ref()] {
+				line += " (archived)"
+			}
+			sb.WriteString(line + "\n")
 		}
 	}
 	if opts != nil && opts.Blocked && len(opts.Blockers) > 0 {
 		sb.WriteString("\nBlocked by:\n")
 		for _, b := range opts.Blockers {
-			sb.WriteString(fmt.Sprintf("  #%d [%s] %s\n", b.TaskID, b.Status, b.Title))
+			sb.WriteString(fmt.Sprintf("  %s [%s] %s\n", b.Ref(), b.Status, b.Title))
+		}
+	}
+	if len(t.History) > 0 {
+		sb.WriteString("\nHistory:\n")
+		for _, h := range t.History {
+			sb.WriteString(fmt.Sprintf("  %s %s: %s -> %s", h.At.Format("2006-01-02 15:04:05"), h.Action, h.From, h.To))
+			if h.Reason != "" {
+				sb.WriteString(" (" + h.Reason + ")")
+			}
+			sb.WriteString("\n")
 		}
 	}
 	if t.Description != "" {
 		sb.WriteString(fmt.Sprintf("\nDescription:\n%s\n", t.Description))
 	}
 	if opts != nil && len(opts.Subtasks) > 0 {
+		// Subtasks come parents first, so each one's depth follows from its parent
 		sb.WriteString(fmt.Sprintf("\nSubtasks (%d):\n", len(opts.Subtasks)))
+		depths := map[int]int{t.ID: 0}
 		for _, sub := range opts.Subtasks {
-			sb.WriteString(fmt.Sprintf("  #%d [%s] %s\n", sub.ID, sub.Status, sub.Title))
+			depth := 1
+			if sub.ParentID != nil {
+				depth = depths[*sub.ParentID] + 1
+			}
+			depths[sub.ID] = depth
+			line := fmt.Sprintf("%s#%d [%s] %s", strings.Repeat("  ", depth), sub.ID, sub.Status, sub.Title)
+			if old, ok := opts.changed(sub.ID); ok {
+				line += " (was " + string(old) + ")"
+			}
+			sb.WriteString(line + "\n")
 		}
 	}
 	return sb.String()
@@ -68,32 +115,223 @@ type SubtaskCounts struct {
 // subtaskCounts is a map of task ID to subtask counts (can be nil)
 // blockedTasks is a set of task IDs that are blocked (can be nil)
 func FormatTaskTable(tasks []*task.Task, subtaskCounts map[int]SubtaskCounts, blockedTasks map[int]bool) string {
-	if len(tasks) == 0 {
-		return "No tasks found."
+	return formatTable(tasks, DefaultColumns, &ListOptions{SubtaskCounts: subtaskCounts, Blocked: blockedTasks})
+}
+
+// FormatTaskTreeResult formats the tasks created from a plan document
+func FormatTaskTreeResult(result *task.TaskTreeResult) string {
+	keysByID := make(map[int]string, len(result.IDs))
+	for key, id := range result.IDs {
+		keysByID[id] = key
+	}
+	created := make(map[int]bool, len(result.Tasks))
+	for _, t := range result.Tasks {
+		created[t.ID] = true
 	}
 
 	var sb strings.Builder
-	w := tabwriter.NewWriter(&sb, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tTitle\tStatus\tPriority\tType\tSubtasks")
-	for _, t := range tasks {
-		title := t.Title
-		if len(title) > 40 {
-			title = title[:37] + "..."
+	sb.WriteString(fmt.Sprintf("Created %d task(s):\n", len(result.Tasks)))
+	for _, t := range result.Tasks {
+		indent := "  "
+		if t.ParentID != nil && created[*t.ParentID] {
+			indent = "    "
+		}
+		line := fmt.Sprintf("%s#%d %s", indent, t.ID, t.Title)
+		if key, ok := keysByID[t.ID]; ok {
+			line += fmt.Sprintf(" (%s)", key)
 		}
-		statusStr := string(t.Status)
-		if blockedTasks != nil && blockedTasks[t.ID] {
-			statusStr += " [BLOCKED]"
+		sb.WriteString(line + "\n")
+	}
+	for _, p := range result.UpdatedParents {
+		sb.WriteString(fmt.Sprintf("  Parent #%d is now %s\n", p.ID, p.Status))
+	}
+	return sb.String()
+}
+
+// FormatDependencyGraph formats the transitive blockers and dependents of a task
+func FormatDependencyGraph(g *task.DependencyGraph) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Dependencies of task #%d\n", g.TaskID))
+	writeNodes := func(label string, nodes []task.DependencyNode) {
+		sb.WriteString(fmt.Sprintf("\n%s (%d):\n", label, len(nodes)))
+		if len(nodes) == 0 {
+			sb.WriteString("  (none)\n")
+		}
+		for _, n := range nodes {
+			sb.WriteString(fmt.Sprintf("%s#%d [%s] %s\n", strings.Repeat("  ", n.Depth), n.TaskID, n.Status, n.Title))
+		}
+	}
+	writeNodes("Blocked by", g.Blockers)
+	writeNodes("Blocking", g.Dependents)
+	return sb.String()
+}
+
+// FormatCriticalPath formats the longest chain of open blocked_by tasks
+func FormatCriticalPath(path []task.DependencyNode) string {
+	if len(path) == 0 {
+		return "No blocked_by chains among open tasks\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Critical path (%d tasks):\n", len(path)))
+	for i, n := range path {
+		sb.WriteString(fmt.Sprintf("  %d. #%d [%s] %s\n", i+1, n.TaskID, n.Status, n.Title))
+	}
+	return sb.String()
+}
+
+// FormatDuplicateResult formats the outcome of marking a task as duplicate
+func FormatDuplicateResult(r *task.DuplicateResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Marked task #%d as duplicate of #%d.\n", r.Duplicate.ID, r.Canonical.ID))
+	for _, e := range r.Repointed {
+		sb.WriteString(fmt.Sprintf("  Re-pointed: #%d %s -> #%d\n", e.Source, e.Type, e.Target))
+	}
+	for _, e := range r.Dropped {
+		sb.WriteString(fmt.Sprintf("  Dropped:    #%d %s -> #%d (already covered)\n", e.Source, e.Type, e.Target))
+	}
+	if r.Merged {
+		sb.WriteString(fmt.Sprintf("  Merged description and tags into #%d\n", r.Canonical.ID))
+	}
+	if r.Closed {
+		sb.WriteString(fmt.Sprintf("  Closed #%d\n", r.Duplicate.ID))
+	}
+	return sb.String()
+}
+
+// FormatReparentResult formats the outcome of moving a task to a new parent
+func FormatReparentResult(r *task.ReparentResult) string {
+	var sb strings.Builder
+	if r.Task.ParentID != nil {
+		sb.WriteString(fmt.Sprintf("Moved task #%d under #%d.\n", r.Task.ID, *r.Task.ParentID))
+	} else {
+		sb.WriteString(fmt.Sprintf("Moved task #%d to top level.\n", r.Task.ID))
+	}
+	for _, p := range r.UpdatedParents {
+		sb.WriteString(fmt.Sprintf("  Parent #%d is now %s\n", p.ID, p.Status))
+	}
+	return sb.String()
+}
+
+// FormatUnarchiveResult formats the tasks and relations restored from the archive
+func FormatUnarchiveResult(r *task.UnarchiveResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Task #%d unarchived.\n", r.Tasks[0].ID))
+	for _, sub := range r.Tasks[1:] {
+		sb.WriteString(fmt.Sprintf("  Subtask #%d [%s] %s\n", sub.ID, sub.Status, sub.Title))
+	}
+	for _, e := range r.Restored {
+		sb.WriteString(fmt.Sprintf("  Restored: #%d %s -> #%d\n", e.Source, e.Type, e.Target))
+	}
+	for _, e := range r.Dropped {
+		sb.WriteString(fmt.Sprintf("  Dropped:  #%d %s -> #%d\n", e.Source, e.Type, e.Target))
+	}
+	return sb.String()
+}
+
+// FormatAutoArchiveResult formats the tasks an auto-archive run archived or would archive
+func FormatAutoArchiveResult(r *task.AutoArchiveResult) string {
+	if len(r.Archived) == 0 {
+		return "No tasks to archive.\n"
+	}
+	var sb strings.Builder
+	if r.DryRun {
+		sb.WriteString(fmt.Sprintf("Would archive %d task(s):\n", len(r.Archived)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Archived %d task(s):\n", len(r.Archived)))
+	}
+	for _, e := range r.Archived {
+		sb.WriteString(fmt.Sprintf("  #%d %s: %s", e.ID, e.Title, e.Reason))
+		if e.Subtasks > 0 {
+			sb.WriteString(fmt.Sprintf(", with %d subtask(s)", e.Subtasks))
 		}
-		// Show subtask count if this task has subtasks
-		subtaskStr := ""
-		if subtaskCounts != nil {
-			if counts, ok := subtaskCounts[t.ID]; ok && counts.Total > 0 {
-				subtaskStr = fmt.Sprintf("[%d/%d]", counts.Done, counts.Total)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// FormatSplitResult formats the subtasks created by splitting a task
+func FormatSplitResult(r *task.SplitResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Split task #%d into %d subtask(s):\n", r.Parent.ID, len(r.Subtasks)))
+	for _, sub := range r.Subtasks {
+		sb.WriteString(fmt.Sprintf("  #%d [%s] %s\n", sub.ID, sub.Status, sub.Title))
+	}
+	return sb.String()
+}
+
+// FormatMergeResult formats the outcome of merging tasks
+func FormatMergeResult(r *task.MergeResult) string {
+	var sb strings.Builder
+	merged := make([]string, len(r.Merged))
+	for i, id := range r.Merged {
+		merged[i] = fmt.Sprintf("#%d", id)
+	}
+	sb.WriteString(fmt.Sprintf("Merged %s into task #%d.\n", strings.Join(merged, ", "), r.Task.ID))
+	for _, e := range r.Repointed {
+		sb.WriteString(fmt.Sprintf("  Re-pointed: #%d %s -> #%d\n", e.Source, e.Type, e.Target))
+	}
+	for _, e := range r.Dropped {
+		sb.WriteString(fmt.Sprintf("  Dropped:    #%d %s -> #%d\n", e.Source, e.Type, e.Target))
+	}
+	for _, id := range r.Subtasks {
+		sb.WriteString(fmt.Sprintf("  Moved subtask #%d\n", id))
+	}
+	return sb.String()
+}
+
+// FormatTransitionResult formats the outcome of reopening or cancelling a task
+func FormatTransitionResult(msg string, r *task.TransitionResult) string {
+	var sb strings.Builder
+	sb.WriteString(msg + "\n")
+	for _, id := range r.Cancelled {
+		sb.WriteString(fmt.Sprintf("  Cancelled subtask #%d\n", id))
+	}
+	for _, p := range r.UpdatedParents {
+		sb.WriteString(fmt.Sprintf("  Parent #%d is now %s\n", p.ID, p.Status))
+	}
+	for _, id := range r.Reblocked {
+		sb.WriteString(fmt.Sprintf("  Task #%d is blocked again\n", id))
+	}
+	for _, id := range r.Unblocked {
+		sb.WriteString(fmt.Sprintf("  Task #%d is no longer blocked\n", id))
+	}
+	return sb.String()
+}
+
+// FormatDiff lists the lines removed from before ("- ") and added in after ("+ ")
+func FormatDiff(before, after string) string {
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
 			}
 		}
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, title, statusStr, t.Priority, t.Type, subtaskStr)
 	}
-	w.Flush()
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
 	return sb.String()
 }
 
@@ -153,3 +153,101 @@ func TestFormatJSONMessage(t *testing.T) {
 		t.Error("expected id field")
 	}
 }
+
+func TestWriteTaskListFormats(t *testing.T) {
+	parent := 1
+	tasks := []*task.Task{
+		{ID: 1, Title: "Parent", Status: task.StatusInProgress, Priority: task.PriorityHigh, Type: "feature", Tags: []string{"ui", "api"}},
+		{ID: 2, Title: "A | B", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "bug", ParentID: &parent},
+	}
+	blocked := map[int]bool{2: true}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want string
+	}{
+		{"csv", ListOptions{Format: "csv", Columns: []string{"id", "title", "tags", "blocked"}},
+			"id,title,tags,blocked\n1,Parent,\"ui, api\",false\n2,A | B,,true\n"},
+		{"markdown", ListOptions{Format: "markdown", Columns: []string{"id", "title", "status"}},
+			"| ID | Title | Status |\n| --- | --- | --- |\n| 1 | Parent | in_progress |\n| 2 | A \\| B | todo [BLOCKED] |\n"},
+		{"json columns", ListOptions{Format: "json", Columns: []string{"title", "id", "parent"}},
+			"[\n  {\n    \"title\": \"Parent\",\n    \"id\": 1,\n    \"parent\": null\n  },\n  {\n    \"title\": \"A | B\",\n    \"id\": 2,\n    \"parent\": 1\n  }\n]\n"},
+		{"yaml columns", ListOptions{Format: "yaml", Columns: []string{"id", "tags"}},
+			"- id: 1\n  tags:\n    - ui\n    - api\n- id: 2\n  tags: []\n"},
+		{"template", ListOptions{Format: "template", Template: `{{.ID}} {{upper .Title}} {{join .Tags "+"}}{{if .Blocked}} blocked{{end}}`},
+			"1 PARENT ui+api\n2 A | B  blocked\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Blocked = blocked
+			var buf bytes.Buffer
+			if err := WriteTaskList(&buf, tasks, tt.opts); err != nil {
+				t.Fatalf("WriteTaskList() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteTaskList() =\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTaskList(&buf, tasks, ListOptions{Format: "template", Template: "{{.Missing}}"}); err == nil {
+		t.Error("expected error for template referring to an unknown field")
+	}
+}
+
+func TestFormatTableWidthAndColor(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: 1, Title: "A title long enough to be shortened on narrow terminals", Status: task.StatusDone, Priority: task.PriorityCritical, Type: "feature"},
+	}
+	columns := []string{"id", "title", "status", "priority"}
+
+	narrow := formatTable(tasks, columns, &ListOptions{Width: 40})
+	for _, line := range strings.Split(strings.TrimSpace(narrow), "\n") {
+		if len(line) > 40 {
+			t.Errorf("line %q is wider than 40 characters", line)
+		}
+	}
+	if !strings.Contains(narrow, "A title long...") {
+		t.Errorf("expected shortened title, got:\n%s", narrow)
+	}
+
+	wide := formatTable(tasks, columns, &ListOptions{Width: 200})
+	if !strings.Contains(wide, tasks[0].Title) {
+		t.Errorf("expected full title on a wide terminal, got:\n%s", wide)
+	}
+	if strings.Contains(wide, "\x1b[") {
+		t.Error("expected no colour codes without Color")
+	}
+
+	colored := formatTable(tasks, columns, &ListOptions{Width: 200, Color: true, Blocked: map[int]bool{1: true}})
+	if !strings.Contains(colored, "\x1b[32mdone\x1b[0m \x1b[31m[BLOCKED]\x1b[0m") || !strings.Contains(colored, "\x1b[1;31mcritical\x1b[0m") {
+		t.Errorf("expected coloured status and priority, got:\n%q", colored)
+	}
+}
+
+func TestFormatTaskHierarchy(t *testing.T) {
+	nodes := []*task.HierarchyNode{
+		{Task: &task.Task{ID: 1, Title: "Epic", Status: task.StatusInProgress}, Subtasks: []*task.HierarchyNode{
+			{Task: &task.Task{ID: 2, Title: "Done part", Status: task.StatusDone}},
+			{Task: &task.Task{ID: 3, Title: "Open part", Status: task.StatusTodo}, Blocked: true, Subtasks: []*task.HierarchyNode{
+				{Task: &task.Task{ID: 4, Title: "Dropped", Status: task.StatusDone, Resolution: task.ResolutionCancelled}},
+			}},
+		}},
+		{Task: &task.Task{ID: 5, Title: "Later", Status: task.StatusTodo}, Collapsed: 2},
+	}
+
+	want := "◐ #1 Epic\n" +
+		"├── ● #2 Done part\n" +
+		"└── ○ #3 Open part [BLOCKED]\n" +
+		"    └── ✗ #4 Dropped\n" +
+		"○ #5 Later (+2 subtasks)\n"
+	if got := FormatTaskHierarchy(nodes, &ListOptions{}); got != want {
+		t.Errorf("FormatTaskHierarchy() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := FormatTaskHierarchy(nil, &ListOptions{}); got != "No tasks found." {
+		t.Errorf("FormatTaskHierarchy(nil) = %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchFrame renders one frame from a freshly loaded service. changed maps
+// tasks whose status changed since the previous frame to their old status.
+type watchFrame func(svc *task.Service, changed map[int]task.Status) (string, error)
+
+// watchCommand runs runWatch until Ctrl-C or SIGTERM
+func watchCommand(stdout, stderr io.Writer, cfg *config.Config, title string, interval time.Duration, frame watchFrame) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err := runWatch(ctx, stdout, cfg, title, interval, frame)
+	if isTerminal(stdout) {
+		fmt.Fprintln(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runWatch renders a frame, then renders again whenever the tasks directory
+// changes, until ctx is done. Terminals are cleared before each frame.
+func runWatch(ctx context.Context, w io.Writer, cfg *config.Config, title string, interval time.Duration, frame watchFrame) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	clear := isTerminal(w)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var statuses map[int]task.Status
+	signature := ""
+	for {
+		if statuses == nil || storage.DirSignature(cfg.TasksDir()) != signature {
+			svc, err := initServiceWithConfig(cfg)
+			if err != nil {
+				return err
+			}
+			// Loading may rewrite the index, so the signature is taken afterwards
+			signature = storage.DirSignature(cfg.TasksDir())
+			current := make(map[int]task.Status)
+			changed := make(map[int]task.Status)
+			for _, t := range svc.List(nil, nil, nil, nil) {
+				current[t.ID] = t.Status
+				if old, ok := statuses[t.ID]; ok && old != t.Status {
+					changed[t.ID] = old
+				}
+			}
+			statuses = current
+
+			text, err := frame(svc, changed)
+			if err != nil {
+				return err
+			}
+			if clear {
+				fmt.Fprint(w, clearScreen)
+			}
+			fmt.Fprintf(w, "Every %s: %s (Ctrl-C to quit)   %s\n\n%s", interval, title, time.Now().Format("15:04:05"), text)
+			if !strings.HasSuffix(text, "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,29 +1,162 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // AutoArchiveConfig holds configuration for automatic task archiving
 type AutoArchiveConfig struct {
-	Enabled   bool `yaml:"enabled"`
-	AfterDays int  `yaml:"after_days"`
+	Enabled   bool              `yaml:"enabled"`              // Run on startup and after completing or cancelling tasks
+	AfterDays int               `yaml:"after_days"`           // For done tasks no rule matches
+	Basis     string            `yaml:"basis,omitempty"`      // AutoArchiveBasisUpdatedAt (default) or AutoArchiveBasisCompletedAt
+	MaxActive int               `yaml:"max_active,omitempty"` // Archive the oldest done tasks early while more tasks are active; 0 = no limit
+	Rules     []AutoArchiveRule `yaml:"rules,omitempty"`      // First matching rule wins
 }
 
+// Auto-archive bases: the timestamp the age of a done task is measured from
+const (
+	AutoArchiveBasisUpdatedAt   = "updated_at"
+	AutoArchiveBasisCompletedAt = "completed_at" // Falls back to updated_at for tasks completed before it was recorded
+)
+
+// AutoArchiveRule overrides after_days for done tasks of a type and/or with a tag
+type AutoArchiveRule struct {
+	Type      string `yaml:"type,omitempty"`
+	Tag       string `yaml:"tag,omitempty"`
+	AfterDays int    `yaml:"after_days"`
+	Never     bool   `yaml:"never,omitempty"` // Never archive these automatically
+}
+
+// Matches reports whether a task with taskType and tags is subject to the rule
+func (r AutoArchiveRule) Matches(taskType string, tags []string) bool {
+	if r.Type != "" && r.Type != taskType {
+		return false
+	}
+	return r.Tag == "" || slices.Contains(tags, r.Tag)
+}
+
+// String describes the tasks the rule applies to, e.g. "type=bug"
+func (r AutoArchiveRule) String() string {
+	var parts []string
+	if r.Type != "" {
+		parts = append(parts, "type="+r.Type)
+	}
+	if r.Tag != "" {
+		parts = append(parts, "tag="+r.Tag)
+	}
+	if len(parts) == 0 {
+		return "all tasks"
+	}
+	return strings.Join(parts, ",")
+}
+
+// Validate rejects rules that would archive tasks unintentionally: a rule
+// needs a type or tag to select tasks, and positive after_days unless never
+// is set
+func (c AutoArchiveConfig) Validate() error {
+	for i, r := range c.Rules {
+		if r.Type == "" && r.Tag == "" {
+			return fmt.Errorf("invalid auto_archive rule %d: type or tag is required", i+1)
+		}
+		if !r.Never && r.AfterDays <= 0 {
+			return fmt.Errorf("invalid auto_archive rule %d (%s): after_days must be positive, or set never", i+1, r)
+		}
+	}
+	return nil
+}
+
+// RuleFor returns the first rule matching a task, or nil if after_days applies
+func (c AutoArchiveConfig) RuleFor(taskType string, tags []string) *AutoArchiveRule {
+	for i := range c.Rules {
+		if c.Rules[i].Matches(taskType, tags) {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ImportMapping customises how an importer maps external fields onto tasks.
+// Columns maps task fields (id, parent, title, description, status, priority,
+// type, labels) to source column names; the other maps translate external
+// values (matched case-insensitively) to task values.
+type ImportMapping struct {
+	Columns  map[string]string `yaml:"columns,omitempty"`
+	Status   map[string]string `yaml:"status,omitempty"`
+	Priority map[string]string `yaml:"priority,omitempty"`
+	Type     map[string]string `yaml:"type,omitempty"`
+}
+
+// RelationType describes how a relation type behaves. In YAML an entry may be
+// given as a plain name; the built-in types then keep their default behaviour.
+type RelationType struct {
+	Name        string   `yaml:"name"`
+	Symmetric   bool     `yaml:"symmetric,omitempty"`    // "A r B" implies "B r A"
+	Blocking    bool     `yaml:"blocking,omitempty"`     // Source cannot be worked on until target is done
+	Duplicate   bool     `yaml:"duplicate,omitempty"`    // Source duplicates target and is hidden from listings and get_next_task
+	Inverse     string   `yaml:"inverse,omitempty"`      // Name for reading the relation from target to source (e.g. blocks)
+	SourceTypes []string `yaml:"source_types,omitempty"` // Allowed source task types (empty = any)
+	TargetTypes []string `yaml:"target_types,omitempty"` // Allowed target task types (empty = any)
+}
+
+// UnmarshalYAML accepts either a relation type name or a full mapping
+func (r *RelationType) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*r = defaultRelationType(value.Value)
+		return nil
+	}
+	type plain RelationType
+	return value.Decode((*plain)(r))
+}
+
+// defaultRelationType returns the built-in behaviour for name, or a plain
+// non-blocking, non-symmetric type if name is not built in
+func defaultRelationType(name string) RelationType {
+	for _, rt := range DefaultRelationTypes {
+		if rt.Name == name {
+			return rt
+		}
+	}
+	return RelationType{Name: name}
+}
+
+// DuplicatesConfig holds defaults for marking tasks as duplicates
+type DuplicatesConfig struct {
+	AutoClose bool `yaml:"auto_close"` // Set the duplicate to done
+	Merge     bool `yaml:"merge"`      // Append the duplicate's description and tags to the canonical task
+}
+
+// HierarchyConfig limits how deeply tasks may be nested
+type HierarchyConfig struct {
+	MaxDepth int `yaml:"max_depth"` // Levels including top-level tasks; 0 = unlimited
+}
+
+// DefaultMaxDepth allows epics containing features containing implementation steps
+const DefaultMaxDepth = 3
+
 // Config holds application configuration
 type Config struct {
-	TaskTypes     []string          `yaml:"task_types"`
-	RelationTypes []string          `yaml:"relation_types,omitempty"`
-	AutoArchive   AutoArchiveConfig `yaml:"auto_archive"`
-	DataDir       string            `yaml:"-"` // Set from env or default
-	ProjectFound  bool              `yaml:"-"` // Whether an existing project was discovered
+	TaskTypes     []string                 `yaml:"task_types"`
+	RelationTypes []RelationType           `yaml:"relation_types,omitempty"`
+	AutoArchive   AutoArchiveConfig        `yaml:"auto_archive"`
+	Duplicates    DuplicatesConfig         `yaml:"duplicates"`
+	Hierarchy     HierarchyConfig          `yaml:"hierarchy"`
+	Import        map[string]ImportMapping `yaml:"import,omitempty"` // Keyed by importer name
+	DataDir       string                   `yaml:"-"`                // Set from env or default
+	ProjectFound  bool                     `yaml:"-"`                // Whether an existing project was discovered
 }
 
 // DefaultRelationTypes returns the default relation types
-var DefaultRelationTypes = []string{"blocked_by", "relates_to", "duplicate_of"}
+var DefaultRelationTypes = []RelationType{
+	{Name: "blocked_by", Blocking: true},
+	{Name: "relates_to", Symmetric: true},
+	{Name: "duplicate_of", Duplicate: true},
+}
 
 // DefaultConfig returns configuration with defaults
 func DefaultConfig() *Config {
@@ -34,7 +167,9 @@ func DefaultConfig() *Config {
 		AutoArchive: AutoArchiveConfig{
 			Enabled:   false,
 			AfterDays: 30,
+			Basis:     AutoArchiveBasisUpdatedAt,
 		},
+		Hierarchy: HierarchyConfig{MaxDepth: DefaultMaxDepth},
 	}
 }
 
@@ -51,6 +186,9 @@ func Load() (*Config, error) {
 		if data, err := os.ReadFile(configPath); err == nil {
 			yaml.Unmarshal(data, cfg)
 		}
+		if err := cfg.AutoArchive.Validate(); err != nil {
+			return nil, err
+		}
 		return cfg, nil
 	}
 
@@ -61,13 +199,7 @@ func Load() (*Config, error) {
 	}
 
 	if projectRoot != "" {
-		cfg.DataDir = filepath.Join(projectRoot, "tasks")
-		cfg.ProjectFound = true
-		// Try to load config from project root
-		configPath := filepath.Join(projectRoot, "mcp-tasks.yaml")
-		if data, err := os.ReadFile(configPath); err == nil {
-			yaml.Unmarshal(data, cfg)
-		}
+		loadFromRoot(cfg, projectRoot)
 	} else {
 		// No project found - use cwd default
 		cfg.DataDir = "./tasks"
@@ -78,9 +210,23 @@ func Load() (*Config, error) {
 		}
 	}
 
+	if err := cfg.AutoArchive.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+// loadFromRoot points cfg at the project in projectRoot and applies its config file
+func loadFromRoot(cfg *Config, projectRoot string) {
+	cfg.DataDir = filepath.Join(projectRoot, "tasks")
+	cfg.ProjectFound = true
+	// Try to load config from project root
+	configPath := filepath.Join(projectRoot, "mcp-tasks.yaml")
+	if data, err := os.ReadFile(configPath); err == nil {
+		yaml.Unmarshal(data, cfg)
+	}
+}
+
 // TasksDir returns the full path to the tasks directory
 func (c *Config) TasksDir() string {
 	if filepath.IsAbs(c.DataDir) {
@@ -100,14 +246,48 @@ func (c *Config) IsValidTaskType(t string) bool {
 	return false
 }
 
-// IsValidRelationType checks if relation type is in configured list
+// IsValidRelationType checks if relation type (or an inverse name) is in configured list
 func (c *Config) IsValidRelationType(t string) bool {
-	for _, valid := range c.RelationTypes {
-		if t == valid {
-			return true
+	_, _, ok := c.LookupRelationType(t)
+	return ok
+}
+
+// LookupRelationType finds the relation type named name. If name is the
+// inverse name of a type, that type is returned with inverse set.
+func (c *Config) LookupRelationType(name string) (rt RelationType, inverse bool, ok bool) {
+	for _, candidate := range c.RelationTypes {
+		if candidate.Name == name {
+			return candidate, false, true
+		}
+		if candidate.Inverse != "" && candidate.Inverse == name {
+			return candidate, true, true
 		}
 	}
-	return false
+	return RelationType{}, false, false
+}
+
+// DuplicateRelationType returns the name of the first relation type marking
+// duplicates, or "" if none is configured
+func (c *Config) DuplicateRelationType() string {
+	for _, rt := range c.RelationTypes {
+		if rt.Duplicate {
+			return rt.Name
+		}
+	}
+	return ""
+}
+
+// RelationTypeNames returns all names accepted for relation types, including
+// inverse names, in configuration order
+func (c *Config) RelationTypeNames() []string {
+	var names []string
+	for _, rt := range c.RelationTypes {
+		names = append(names, rt.Name)
+		if rt.Inverse != "" {
+			names = append(names, rt.Inverse)
+		}
+	}
+	return names
 }
 
 // FindProjectRoot searches for an existing project root by looking for
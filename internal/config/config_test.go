@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -395,6 +399,12 @@ func TestDefaultConfig_AutoArchive(t *testing.T) {
 	}
 }
 
+func TestDefaultConfig_Hierarchy(t *testing.T) {
+	if got := DefaultConfig().Hierarchy.MaxDepth; got != DefaultMaxDepth {
+		t.Errorf("DefaultConfig().Hierarchy.MaxDepth = %d, want %d", got, DefaultMaxDepth)
+	}
+}
+
 func TestLoad_AutoArchiveFromYAML(t *testing.T) {
 	// Create temp directory with config file containing auto_archive section
 	tmpDir := t.TempDir()
@@ -405,6 +415,13 @@ func TestLoad_AutoArchiveFromYAML(t *testing.T) {
 auto_archive:
   enabled: true
   after_days: 60
+  basis: completed_at
+  max_active: 200
+  rules:
+    - type: bug
+      after_days: 7
+    - tag: keep
+      never: true
 `
 	configPath := filepath.Join(tmpDir, "mcp-tasks.yaml")
 	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -436,6 +453,48 @@ auto_archive:
 	if cfg.AutoArchive.AfterDays != 60 {
 		t.Errorf("Load() AutoArchive.AfterDays = %d, want 60", cfg.AutoArchive.AfterDays)
 	}
+
+	if cfg.AutoArchive.Basis != AutoArchiveBasisCompletedAt || cfg.AutoArchive.MaxActive != 200 {
+		t.Errorf("Load() AutoArchive basis = %q, max_active = %d", cfg.AutoArchive.Basis, cfg.AutoArchive.MaxActive)
+	}
+
+	// First matching rule wins
+	if r := cfg.AutoArchive.RuleFor("bug", []string{"keep"}); r == nil || r.AfterDays != 7 {
+		t.Errorf("RuleFor(bug, keep) = %+v, want the bug rule", r)
+	}
+	if r := cfg.AutoArchive.RuleFor("feature", []string{"keep"}); r == nil || !r.Never {
+		t.Errorf("RuleFor(feature, keep) = %+v, want the never rule", r)
+	}
+	if r := cfg.AutoArchive.RuleFor("feature", nil); r != nil {
+		t.Errorf("RuleFor(feature) = %+v, want nil", r)
+	}
+}
+
+func TestLoad_RejectsInvalidAutoArchiveRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"missing after_days", "    - type: bug\n", "after_days must be positive"},
+		{"negative after_days", "    - tag: old\n      after_days: -1\n", "after_days must be positive"},
+		{"no type or tag", "    - after_days: 7\n", "type or tag is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configContent := "auto_archive:\n  enabled: true\n  rules:\n" + tt.rules
+			if err := os.WriteFile(filepath.Join(tmpDir, "mcp-tasks.yaml"), []byte(configContent), 0644); err != nil {
+				t.Fatalf("failed to create config file: %v", err)
+			}
+			t.Setenv("MCP_TASKS_DIR", filepath.Join(tmpDir, "tasks"))
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
 }
 
 func TestLoad_AutoArchiveDefaults_WhenNotInYAML(t *testing.T) {
@@ -505,3 +564,44 @@ func TestLoad_EnvVarLoadsConfigFromParentDir(t *testing.T) {
 		t.Error("Load() should have loaded config from parent of MCP_TASKS_DIR")
 	}
 }
+
+func TestRelationTypes_FromYAML(t *testing.T) {
+	content := `relation_types:
+  - blocked_by
+  - relates_to
+  - name: duplicate_of
+    source_types: [bug]
+    target_types: [bug]
+  - name: depends_on
+    blocking: true
+    inverse: required_by
+`
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(content), cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	// Plain names keep their built-in behaviour
+	if rt, _, _ := cfg.LookupRelationType("blocked_by"); !rt.Blocking {
+		t.Error("blocked_by should be blocking")
+	}
+	if rt, _, _ := cfg.LookupRelationType("relates_to"); !rt.Symmetric {
+		t.Error("relates_to should be symmetric")
+	}
+
+	rt, inverse, ok := cfg.LookupRelationType("required_by")
+	if !ok || !inverse || rt.Name != "depends_on" || !rt.Blocking {
+		t.Errorf("LookupRelationType(required_by) = %+v, %v, %v", rt, inverse, ok)
+	}
+	if rt, _, _ := cfg.LookupRelationType("duplicate_of"); len(rt.SourceTypes) != 1 || rt.SourceTypes[0] != "bug" {
+		t.Errorf("duplicate_of SourceTypes = %v, want [bug]", rt.SourceTypes)
+	}
+
+	want := []string{"blocked_by", "relates_to", "duplicate_of", "depends_on", "required_by"}
+	if got := cfg.RelationTypeNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RelationTypeNames() = %v, want %v", got, want)
+	}
+	if !cfg.IsValidRelationType("required_by") || cfg.IsValidRelationType("blocks") {
+		t.Error("IsValidRelationType() should accept inverse names only when configured")
+	}
+}
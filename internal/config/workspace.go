@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile is the name of the file listing the projects of a workspace
+const WorkspaceFile = "mcp-workspace.yaml"
+
+// Workspace lists projects that can be addressed by name, e.g. with
+// --project api or the task reference api#42
+type Workspace struct {
+	Projects map[string]string `yaml:"projects"` // Name -> project root, relative to the workspace file
+	Dir      string            `yaml:"-"`        // Directory containing the workspace file
+}
+
+// FindWorkspace loads the workspace file named by MCP_WORKSPACE, or the first
+// mcp-workspace.yaml found from cwd upwards. Returns nil if there is none.
+func FindWorkspace() (*Workspace, error) {
+	if path := os.Getenv("MCP_WORKSPACE"); path != "" {
+		return LoadWorkspace(path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dir := cwd
+	for {
+		path := filepath.Join(dir, WorkspaceFile)
+		if _, err := os.Stat(path); err == nil {
+			return LoadWorkspace(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspace reads a workspace file
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+	ws := &Workspace{}
+	if err := yaml.Unmarshal(data, ws); err != nil {
+		return nil, fmt.Errorf("invalid workspace file %s: %w", path, err)
+	}
+	for name := range ws.Projects {
+		if name == "" || strings.ContainsAny(name, "# ") {
+			return nil, fmt.Errorf("invalid project name in %s: %q", path, name)
+		}
+	}
+	abs, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	ws.Dir = abs
+	return ws, nil
+}
+
+// Names returns the project names, sorted
+func (w *Workspace) Names() []string {
+	names := make([]string, 0, len(w.Projects))
+	for name := range w.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProject returns the configuration of the named project
+func (w *Workspace) LoadProject(name string) (*Config, error) {
+	path, ok := w.Projects[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown project: %s (available: %s)", name, strings.Join(w.Names(), ", "))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(w.Dir, path)
+	}
+	cfg := DefaultConfig()
+	loadFromRoot(cfg, path)
+	if err := cfg.AutoArchive.Validate(); err != nil {
+		return nil, fmt.Errorf("project %s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// ProjectOf returns the name of the project whose tasks cfg points at, or
+// "" if it is not part of the workspace
+func (w *Workspace) ProjectOf(cfg *Config) string {
+	dir := filepath.Clean(cfg.TasksDir())
+	for _, name := range w.Names() {
+		project, err := w.LoadProject(name)
+		if err == nil && filepath.Clean(project.TasksDir()) == dir {
+			return name
+		}
+	}
+	return ""
+}
+
+// LoadProject finds the workspace and returns the configuration of the named project
+func LoadProject(name string) (*Config, error) {
+	ws, err := FindWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if ws == nil {
+		return nil, fmt.Errorf("project %s requested but no %s found (set MCP_WORKSPACE or create one)", name, WorkspaceFile)
+	}
+	return ws.LoadProject(name)
+}
+
+// ParseTaskRef parses a task reference: a plain ID ("42", "#42") or a
+// project-qualified reference ("api#42"). project is empty for plain IDs.
+func ParseTaskRef(ref string) (project string, id int, err error) {
+	idPart := ref
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		project, idPart = ref[:i], ref[i+1:]
+	}
+	id, err = strconv.Atoi(idPart)
+	if err != nil || id <= 0 {
+		return "", 0, fmt.Errorf("invalid task reference: %s", ref)
+	}
+	return project, id, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, WorkspaceFile)
+	content := "projects:\n  api: services/api\n  web: /srv/web\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+	apiRoot := filepath.Join(tmpDir, "services", "api")
+	if err := os.MkdirAll(apiRoot, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(apiRoot, "mcp-tasks.yaml"), []byte("task_types: [story]\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	t.Setenv("MCP_WORKSPACE", path)
+	ws, err := FindWorkspace()
+	if err != nil {
+		t.Fatalf("FindWorkspace() error = %v", err)
+	}
+	if got := ws.Names(); len(got) != 2 || got[0] != "api" || got[1] != "web" {
+		t.Errorf("Names() = %v, want [api web]", got)
+	}
+
+	cfg, err := ws.LoadProject("api")
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if cfg.DataDir != filepath.Join(apiRoot, "tasks") {
+		t.Errorf("DataDir = %q, want %q", cfg.DataDir, filepath.Join(apiRoot, "tasks"))
+	}
+	if len(cfg.TaskTypes) != 1 || cfg.TaskTypes[0] != "story" {
+		t.Errorf("TaskTypes = %v, want project config [story]", cfg.TaskTypes)
+	}
+
+	cfg, _ = ws.LoadProject("web")
+	if cfg.DataDir != "/srv/web/tasks" {
+		t.Errorf("DataDir = %q, want absolute project path", cfg.DataDir)
+	}
+
+	if _, err := ws.LoadProject("docs"); err == nil {
+		t.Error("LoadProject() should fail for unknown project")
+	}
+}
+
+func TestParseTaskRef(t *testing.T) {
+	tests := []struct {
+		ref     string
+		project string
+		id      int
+		wantErr bool
+	}{
+		{ref: "42", id: 42},
+		{ref: "#42", id: 42},
+		{ref: "api#42", project: "api", id: 42},
+		{ref: "api#", wantErr: true},
+		{ref: "api", wantErr: true},
+		{ref: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		project, id, err := ParseTaskRef(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTaskRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if project != tt.project || id != tt.id {
+			t.Errorf("ParseTaskRef(%q) = %q, %d, want %q, %d", tt.ref, project, id, tt.project, tt.id)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"id", "parent_id", "title", "status", "priority", "type", "tags",
+	"external_id", "relations", "archived", "created_at", "updated_at", "description",
+}
+
+// writeCSV writes one row per task. Multi-valued fields are joined with ';'
+// and relations are written as type:target pairs.
+func writeCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		parentID := ""
+		if e.ParentID != nil {
+			parentID = strconv.Itoa(*e.ParentID)
+		}
+		relations := make([]string, 0, len(e.Relations))
+		for _, rel := range e.Relations {
+			if rel.Project != "" {
+				relations = append(relations, fmt.Sprintf("%s:%s", rel.Type, rel.Ref()))
+				continue
+			}
+			relations = append(relations, fmt.Sprintf("%s:%d", rel.Type, rel.Task))
+		}
+		row := []string{
+			strconv.Itoa(e.ID),
+			parentID,
+			e.Title,
+			string(e.Status),
+			string(e.Priority),
+			e.Type,
+			strings.Join(e.Tags, ";"),
+			e.ExternalID,
+			strings.Join(relations, ";"),
+			strconv.FormatBool(e.Archived),
+			e.CreatedAt.Format(time.RFC3339),
+			e.UpdatedAt.Format(time.RFC3339),
+			e.Description,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
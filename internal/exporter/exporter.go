@@ -0,0 +1,61 @@
+// Package exporter writes tasks to files for sharing with people who do not
+// run the task manager.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// Entry is an exported task together with its archive state
+type Entry struct {
+	*task.Task
+	Archived bool `json:"archived,omitempty"`
+}
+
+// WriteFunc writes entries in a specific format
+type WriteFunc func(w io.Writer, entries []Entry) error
+
+var writers = map[string]WriteFunc{
+	"json": writeJSON,
+	"csv":  writeCSV,
+	"html": writeHTML,
+}
+
+// Formats returns the supported export formats, sorted
+func Formats() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Entries combines active and archived tasks into entries sorted by ID
+func Entries(active, archived []*task.Task) []Entry {
+	entries := make([]Entry, 0, len(active)+len(archived))
+	for _, t := range active {
+		entries = append(entries, Entry{Task: t})
+	}
+	for _, t := range archived {
+		entries = append(entries, Entry{Task: t, Archived: true})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// Write writes entries to w in the given format
+func Write(format string, w io.Writer, entries []Entry) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unknown export format: %s (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return write(w, entries)
+}
@@ -0,0 +1,116 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+func testEntries() []Entry {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	parentID := 1
+	active := []*task.Task{
+		{ID: 2, ParentID: &parentID, Title: "Child", Description: "Line one\nLine two", Status: task.StatusDone,
+			Priority: task.PriorityLow, Type: "bug", Tags: []string{"a", "b"},
+			Relations: []task.Relation{{Type: "blocked_by", Task: 3}}, CreatedAt: now, UpdatedAt: now},
+		{ID: 1, Title: "Parent <b>", Status: task.StatusInProgress, Priority: task.PriorityHigh, Type: "feature", CreatedAt: now, UpdatedAt: now},
+	}
+	archived := []*task.Task{
+		{ID: 3, Title: "Old blocker", Status: task.StatusDone, Priority: task.PriorityMedium, Type: "feature", CreatedAt: now, UpdatedAt: now},
+	}
+	return Entries(active, archived)
+}
+
+func TestEntries_SortedWithArchiveFlag(t *testing.T) {
+	entries := testEntries()
+	if len(entries) != 3 {
+		t.Fatalf("len(entries) = %d, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if e.ID != i+1 {
+			t.Errorf("entries[%d].ID = %d, want %d", i, e.ID, i+1)
+		}
+	}
+	if entries[0].Archived || !entries[2].Archived {
+		t.Error("only the archived task should be flagged as archived")
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("json", &buf, testEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded[1]["description"] != "Line one\nLine two" {
+		t.Errorf("description = %v, want full description", decoded[1]["description"])
+	}
+	if _, ok := decoded[1]["relations"]; !ok {
+		t.Error("expected relations in JSON export")
+	}
+	if decoded[2]["archived"] != true {
+		t.Errorf("archived = %v, want true", decoded[2]["archived"])
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("csv", &buf, testEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("len(rows) = %d, want header + 3", len(rows))
+	}
+	child := rows[2]
+	if child[1] != "1" || child[6] != "a;b" || child[8] != "blocked_by:3" {
+		t.Errorf("child row = %v, want parent 1, tags a;b, relation blocked_by:3", child)
+	}
+	if child[len(child)-1] != "Line one\nLine two" {
+		t.Errorf("description = %q, want multi-line description", child[len(child)-1])
+	}
+}
+
+func TestWrite_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("html", &buf, testEntries()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<details id="task-1"`,
+		`status-in_progress`,
+		`href="#task-3"`,
+		`Parent &lt;b&gt;`,
+		`[1/1]`,
+		`(archived)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML output missing %q", want)
+		}
+	}
+	// The subtask is nested inside its parent's details element
+	if strings.Index(out, `id="task-2"`) > strings.Index(out, `id="task-3"`) {
+		t.Error("subtask should be rendered inside its parent before the next root")
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	if err := Write("xml", &bytes.Buffer{}, nil); err == nil {
+		t.Error("Write() with unknown format should fail")
+	}
+}
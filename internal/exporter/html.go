@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// htmlNode is a task in the rendered parent/subtask tree
+type htmlNode struct {
+	Entry
+	Children []*htmlNode
+	Done     int
+}
+
+// htmlReport is the data passed to the HTML template
+type htmlReport struct {
+	Generated string
+	Roots     []*htmlNode
+	Total     int
+	Counts    map[task.Status]int
+	Titles    map[int]string
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Task Report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+h1 { margin-bottom: 0.25rem; }
+.summary { color: #666; margin-bottom: 1.5rem; }
+details { margin: 0.25rem 0 0.25rem 1.25rem; border-left: 3px solid #ddd; padding-left: 0.5rem; }
+details > summary { cursor: pointer; list-style-position: outside; }
+details:target > summary { background: #fff6bf; }
+.status { display: inline-block; min-width: 6.5em; padding: 0 0.4em; border-radius: 3px; font-size: 0.85em; text-align: center; color: #fff; }
+.status-todo { background: #6c757d; }
+.status-in_progress { background: #0d6efd; }
+.status-done { background: #198754; }
+.priority { font-size: 0.85em; color: #666; }
+.priority-critical { color: #dc3545; font-weight: bold; }
+.priority-high { color: #fd7e14; }
+.archived { opacity: 0.6; }
+.meta { font-size: 0.85em; color: #666; margin: 0.25rem 0; }
+.description { white-space: pre-wrap; background: #f8f9fa; padding: 0.5rem; margin: 0.25rem 0; }
+.relations { margin: 0.25rem 0; padding-left: 1.25rem; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Task Report</h1>
+<div class="summary">Generated {{.Generated}} &middot; {{.Total}} task(s):
+{{range $status, $count := .Counts}}{{$count}} {{$status}} {{end}}</div>
+{{range .Roots}}{{template "node" (nodeData . $.Titles)}}{{end}}
+</body>
+</html>
+{{define "node"}}{{$n := .Node}}<details id="task-{{$n.ID}}"{{if $n.Archived}} class="archived"{{end}}{{if ne $n.Status "done"}} open{{end}}>
+<summary><span class="status status-{{$n.Status}}">{{$n.Status}}</span>
+#{{$n.ID}} {{$n.Title}}
+<span class="priority priority-{{$n.Priority}}">{{$n.Priority}}</span>
+{{if $n.Children}}<span class="meta">[{{$n.Done}}/{{len $n.Children}}]</span>{{end}}
+{{if $n.Archived}}<span class="meta">(archived)</span>{{end}}</summary>
+<div class="meta">{{$n.Type}}{{range $n.Tags}} &middot; {{.}}{{end}}{{if $n.ExternalID}} &middot; {{$n.ExternalID}}{{end}} &middot; updated {{$n.UpdatedAt.Format "2006-01-02"}}</div>
+{{if $n.Relations}}<ul class="relations">{{range $n.Relations}}<li>{{.Type}} {{if .Project}}{{.Ref}}{{else}}<a href="#task-{{.Task}}">#{{.Task}}{{with index $.Titles .Task}} {{.}}{{end}}</a>{{end}}</li>{{end}}</ul>{{end}}
+{{if $n.Description}}<div class="description">{{$n.Description}}</div>{{end}}
+{{range $n.Children}}{{template "node" (nodeData . $.Titles)}}{{end}}
+</details>
+{{end}}`
+
+// nodeTemplateData carries a node plus the shared title lookup into the recursive template
+type nodeTemplateData struct {
+	Node   *htmlNode
+	Titles map[int]string
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"nodeData": func(n *htmlNode, titles map[int]string) nodeTemplateData {
+		return nodeTemplateData{Node: n, Titles: titles}
+	},
+}).Parse(htmlTemplate))
+
+// writeHTML writes a self-contained HTML report with a collapsible
+// parent/subtask tree, status colours and links between related tasks
+func writeHTML(w io.Writer, entries []Entry) error {
+	report := htmlReport{
+		Generated: time.Now().Format("2006-01-02 15:04"),
+		Total:     len(entries),
+		Counts:    make(map[task.Status]int),
+		Titles:    make(map[int]string),
+	}
+
+	nodes := make(map[int]*htmlNode, len(entries))
+	for _, e := range entries {
+		nodes[e.ID] = &htmlNode{Entry: e}
+		report.Counts[e.Status]++
+		report.Titles[e.ID] = e.Title
+	}
+
+	// Entries are sorted by ID, so children keep their order
+	for _, e := range entries {
+		n := nodes[e.ID]
+		if e.ParentID != nil {
+			if parent, ok := nodes[*e.ParentID]; ok {
+				parent.Children = append(parent.Children, n)
+				if e.Status == task.StatusDone {
+					parent.Done++
+				}
+				continue
+			}
+		}
+		report.Roots = append(report.Roots, n)
+	}
+
+	return reportTemplate.Execute(w, report)
+}
@@ -0,0 +1,13 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// writeJSON writes all task fields, including descriptions and relations
+func writeJSON(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
@@ -0,0 +1,146 @@
+// Package graph renders task graphs as Graphviz DOT or Mermaid flowcharts.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// renderers maps format names to their render functions
+var renderers = map[string]func(io.Writer, *task.TaskGraph) error{
+	"dot":     writeDOT,
+	"mermaid": writeMermaid,
+}
+
+// Formats returns the supported output format names, sorted
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render writes g to w in the named format
+func Render(w io.Writer, format string, g *task.TaskGraph) error {
+	render, ok := renderers[format]
+	if !ok {
+		return fmt.Errorf("unknown graph format: %s (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return render(w, g)
+}
+
+// statusColors are the node fill colours per status
+var statusColors = map[task.Status]string{
+	task.StatusTodo:       "#e9ecef",
+	task.StatusInProgress: "#cfe2ff",
+	task.StatusDone:       "#d1e7dd",
+}
+
+func writeDOT(w io.Writer, g *task.TaskGraph) error {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+
+	var sb strings.Builder
+	sb.WriteString("digraph tasks {\n")
+	sb.WriteString("  rankdir=BT;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	sb.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, t := range g.Tasks {
+		sb.WriteString(fmt.Sprintf("  t%d [label=\"#%d %s\\n(%s)\", fillcolor=\"%s\"];\n",
+			t.ID, t.ID, quote.Replace(t.Title), t.Status, statusColors[t.Status]))
+	}
+	for _, t := range g.Projects {
+		sb.WriteString(fmt.Sprintf("  %s [label=\"%s %s\\n(%s)\", fillcolor=\"%s\", style=\"rounded,filled,dashed\"];\n",
+			nodeID(t.Project, t.ID), quote.Replace(t.Ref), quote.Replace(t.Title), t.Status, statusColors[t.Status]))
+	}
+	for _, e := range g.Edges {
+		attrs := []string{fmt.Sprintf("label=\"%s\"", quote.Replace(e.Type))}
+		if e.Type == task.EdgeParent {
+			attrs = append(attrs, "style=dashed")
+		}
+		if e.Undirected {
+			attrs = append(attrs, "dir=none")
+		}
+		sb.WriteString(fmt.Sprintf("  t%d -> %s [%s];\n", e.Source, nodeID(e.TargetProject, e.Target), strings.Join(attrs, ", ")))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeMermaid(w io.Writer, g *task.TaskGraph) error {
+	// Mermaid labels use HTML entity codes for characters with syntactic meaning
+	quote := strings.NewReplacer(`"`, "#quot;", "#", "#35;", "|", "#124;", "\n", " ")
+
+	var sb strings.Builder
+	sb.WriteString("flowchart BT\n")
+	for _, t := range g.Tasks {
+		sb.WriteString(fmt.Sprintf("  t%d[\"#35;%d %s<br/>(%s)\"]:::%s\n",
+			t.ID, t.ID, quote.Replace(t.Title), t.Status, t.Status))
+	}
+	for _, t := range g.Projects {
+		sb.WriteString(fmt.Sprintf("  %s[\"%s %s<br/>(%s)\"]:::%s\n",
+			nodeID(t.Project, t.ID), quote.Replace(t.Ref), quote.Replace(t.Title), t.Status, t.Status))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		switch {
+		case e.Type == task.EdgeParent:
+			arrow = "-.->"
+		case e.Undirected:
+			arrow = "---"
+		}
+		sb.WriteString(fmt.Sprintf("  t%d %s|%s| %s\n", e.Source, arrow, quote.Replace(e.Type), nodeID(e.TargetProject, e.Target)))
+	}
+	for _, status := range []task.Status{task.StatusTodo, task.StatusInProgress, task.StatusDone} {
+		sb.WriteString(fmt.Sprintf("  classDef %s fill:%s\n", status, statusColors[status]))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// nodeID returns the node name of a task: t42 in the graph's own project,
+// p_api_42 for a task in another project
+func nodeID(project string, id int) string {
+	if project == "" {
+		return fmt.Sprintf("t%d", id)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, project)
+	return fmt.Sprintf("p_%s_%d", name, id)
+}
+
+// NewFilter builds a graph filter from command-line style arguments: a root
+// task ID (0 for all tasks) and comma-separated statuses and edge types
+func NewFilter(rootID int, statuses, edgeTypes string) task.GraphFilter {
+	var f task.GraphFilter
+	if rootID != 0 {
+		f.RootID = &rootID
+	}
+	for _, s := range splitList(statuses) {
+		f.Statuses = append(f.Statuses, task.Status(s))
+	}
+	f.EdgeTypes = splitList(edgeTypes)
+	return f
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
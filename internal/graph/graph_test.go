@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+func testGraph() *task.TaskGraph {
+	return &task.TaskGraph{
+		Tasks: []*task.Task{
+			{ID: 1, Title: `Build "API"`, Status: task.StatusInProgress},
+			{ID: 2, Title: "Schema", Status: task.StatusDone},
+			{ID: 3, Title: "Docs | notes", Status: task.StatusTodo},
+		},
+		Projects: []*task.ProjectTask{
+			{Project: "api", Ref: "api#7", Task: &task.Task{ID: 7, Title: "Auth", Status: task.StatusTodo}},
+		},
+		Edges: []task.GraphEdge{
+			{Source: 2, Target: 1, Type: task.EdgeParent},
+			{Source: 3, Target: 2, Type: "blocked_by"},
+			{Source: 1, Target: 3, Type: "relates_to", Undirected: true},
+			{Source: 3, Target: 7, TargetProject: "api", Type: "blocked_by"},
+		},
+	}
+}
+
+func TestRenderDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, "dot", testGraph()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph tasks {",
+		`t1 [label="#1 Build \"API\"\n(in_progress)"`,
+		`t2 -> t1 [label="parent", style=dashed];`,
+		`t3 -> t2 [label="blocked_by"];`,
+		`t1 -> t3 [label="relates_to", dir=none];`,
+		`p_api_7 [label="api#7 Auth\n(todo)", fillcolor="#e9ecef", style="rounded,filled,dashed"];`,
+		`t3 -> p_api_7 [label="blocked_by"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, "mermaid", testGraph()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart BT",
+		`t1["#35;1 Build #quot;API#quot;<br/>(in_progress)"]:::in_progress`,
+		`t3["#35;3 Docs #124; notes<br/>(todo)"]:::todo`,
+		"t2 -.->|parent| t1",
+		"t3 -->|blocked_by| t2",
+		"t1 ---|relates_to| t3",
+		`p_api_7["api#35;7 Auth<br/>(todo)"]:::todo`,
+		"t3 -->|blocked_by| p_api_7",
+		"classDef done fill:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if err := Render(&bytes.Buffer{}, "svg", testGraph()); err == nil {
+		t.Error("Render() should reject unknown formats")
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	f := NewFilter(3, "todo, in_progress", "parent,blocked_by,")
+	if f.RootID == nil || *f.RootID != 3 {
+		t.Errorf("RootID = %v, want 3", f.RootID)
+	}
+	if len(f.Statuses) != 2 || f.Statuses[1] != task.StatusInProgress {
+		t.Errorf("Statuses = %v", f.Statuses)
+	}
+	if len(f.EdgeTypes) != 2 {
+		t.Errorf("EdgeTypes = %v", f.EdgeTypes)
+	}
+	if NewFilter(0, "", "").RootID != nil {
+		t.Error("root 0 should mean no root filter")
+	}
+}
@@ -0,0 +1,189 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// csvImporter reads CSV exports whose columns are located by header name.
+// Importers differ only in their name and default mapping.
+type csvImporter struct {
+	name    string
+	mapping config.ImportMapping
+}
+
+func (c csvImporter) Name() string { return c.name }
+
+func (c csvImporter) DefaultMapping() config.ImportMapping { return c.mapping }
+
+// Parse reads the CSV export. Columns maps record fields (id, alt_id, parent,
+// title, description, status, priority, type, labels) to header names. Label
+// cells may hold comma-separated values, and a header may repeat (as in Jira
+// exports, which emit one "Labels" column per label).
+func (c csvImporter) Parse(r io.Reader, columns map[string]string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	positions := make(map[string][]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		positions[key] = append(positions[key], i)
+	}
+	if _, ok := positions[strings.ToLower(columns["id"])]; !ok {
+		return nil, fmt.Errorf("missing ID column %q", columns["id"])
+	}
+	if _, ok := positions[strings.ToLower(columns["title"])]; !ok {
+		return nil, fmt.Errorf("missing title column %q", columns["title"])
+	}
+
+	cells := func(row []string, field string) []string {
+		var values []string
+		for _, i := range positions[strings.ToLower(columns[field])] {
+			if i < len(row) {
+				values = append(values, row[i])
+			}
+		}
+		return values
+	}
+	cell := func(row []string, field string) string {
+		values := cells(row, field)
+		if len(values) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(values[0])
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rec := Record{
+			ID:          cell(row, "id"),
+			AltID:       cell(row, "alt_id"),
+			ParentID:    cell(row, "parent"),
+			Title:       cell(row, "title"),
+			Description: cell(row, "description"),
+			State:       cell(row, "status"),
+			Priority:    cell(row, "priority"),
+			Type:        cell(row, "type"),
+		}
+		for _, value := range cells(row, "labels") {
+			for _, label := range strings.Split(value, ",") {
+				if label = strings.TrimSpace(label); label != "" {
+					rec.Labels = append(rec.Labels, label)
+				}
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// newJiraImporter reads Jira's "Export CSV (all fields)" format
+func newJiraImporter() csvImporter {
+	return csvImporter{
+		name: "jira",
+		mapping: config.ImportMapping{
+			Columns: map[string]string{
+				"id":          "Issue key",
+				"alt_id":      "Issue id",
+				"parent":      "Parent id",
+				"title":       "Summary",
+				"description": "Description",
+				"status":      "Status",
+				"priority":    "Priority",
+				"type":        "Issue Type",
+				"labels":      "Labels",
+			},
+			Status: map[string]string{
+				"backlog":                  "todo",
+				"open":                     "todo",
+				"to do":                    "todo",
+				"selected for development": "todo",
+				"reopened":                 "todo",
+				"in progress":              "in_progress",
+				"in review":                "in_progress",
+				"done":                     "done",
+				"closed":                   "done",
+				"resolved":                 "done",
+			},
+			Priority: map[string]string{
+				"blocker":  "critical",
+				"highest":  "critical",
+				"critical": "critical",
+				"high":     "high",
+				"major":    "high",
+				"medium":   "medium",
+				"low":      "low",
+				"minor":    "low",
+				"lowest":   "low",
+				"trivial":  "low",
+			},
+			Type: map[string]string{
+				"bug":         "bug",
+				"story":       "feature",
+				"task":        "feature",
+				"sub-task":    "feature",
+				"subtask":     "feature",
+				"epic":        "feature",
+				"new feature": "feature",
+				"improvement": "feature",
+			},
+		},
+	}
+}
+
+// newLinearImporter reads Linear's CSV export format
+func newLinearImporter() csvImporter {
+	return csvImporter{
+		name: "linear",
+		mapping: config.ImportMapping{
+			Columns: map[string]string{
+				"id":          "ID",
+				"parent":      "Parent issue",
+				"title":       "Title",
+				"description": "Description",
+				"status":      "Status",
+				"priority":    "Priority",
+				"labels":      "Labels",
+			},
+			Status: map[string]string{
+				"triage":      "todo",
+				"backlog":     "todo",
+				"todo":        "todo",
+				"in progress": "in_progress",
+				"in review":   "in_progress",
+				"done":        "done",
+				"canceled":    "done",
+				"cancelled":   "done",
+				"duplicate":   "done",
+			},
+			Priority: map[string]string{
+				"urgent":      "critical",
+				"high":        "high",
+				"medium":      "medium",
+				"low":         "low",
+				"no priority": "medium",
+			},
+			Type: map[string]string{
+				"bug":     "bug",
+				"feature": "feature",
+			},
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// githubImporter reads the JSON produced by
+// `gh issue list --json number,title,body,state,labels`
+type githubImporter struct{}
+
+// githubIssue is the subset of `gh issue list --json` fields that is imported
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Parent *struct {
+		Number int `json:"number"`
+	} `json:"parent,omitempty"`
+}
+
+func (githubImporter) Name() string { return "github" }
+
+func (githubImporter) DefaultMapping() config.ImportMapping {
+	return config.ImportMapping{
+		Status: map[string]string{
+			"open":   "todo",
+			"closed": "done",
+		},
+		Priority: map[string]string{
+			"p0":                 "critical",
+			"p1":                 "high",
+			"p2":                 "medium",
+			"p3":                 "low",
+			"priority: critical": "critical",
+			"priority: high":     "high",
+			"priority: medium":   "medium",
+			"priority: low":      "low",
+		},
+		Type: map[string]string{
+			"bug":         "bug",
+			"enhancement": "feature",
+		},
+	}
+}
+
+// Parse reads a JSON array of issues. The column mapping does not apply to
+// the fixed GitHub JSON layout and is ignored.
+func (githubImporter) Parse(r io.Reader, _ map[string]string) ([]Record, error) {
+	var issues []githubIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(issues))
+	for _, issue := range issues {
+		rec := Record{
+			ID:          strconv.Itoa(issue.Number),
+			Title:       issue.Title,
+			Description: issue.Body,
+			State:       issue.State,
+		}
+		for _, label := range issue.Labels {
+			rec.Labels = append(rec.Labels, label.Name)
+		}
+		if issue.Parent != nil && issue.Parent.Number > 0 {
+			rec.ParentID = strconv.Itoa(issue.Parent.Number)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
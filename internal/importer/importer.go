@@ -0,0 +1,210 @@
+// Package importer converts export files from other issue trackers into
+// task import records.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// Record is an issue as read from an export file, before mapping
+type Record struct {
+	ID          string
+	AltID       string // Secondary identifier parents may refer to (e.g. Jira's numeric issue id)
+	ParentID    string
+	Title       string
+	Description string
+	State       string
+	Priority    string
+	Type        string
+	Labels      []string
+}
+
+// Importer reads an export file of a specific tracker
+type Importer interface {
+	// Name is the importer name used on the command line and in config
+	Name() string
+	// DefaultMapping returns the built-in field and value mapping
+	DefaultMapping() config.ImportMapping
+	// Parse reads records using the given column mapping
+	Parse(r io.Reader, columns map[string]string) ([]Record, error)
+}
+
+var registry = make(map[string]Importer)
+
+// Register makes an importer available by name
+func Register(imp Importer) {
+	registry[imp.Name()] = imp
+}
+
+// Lookup returns the importer registered under name
+func Lookup(name string) (Importer, bool) {
+	imp, ok := registry[name]
+	return imp, ok
+}
+
+// Names returns the names of all registered importers, sorted
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(githubImporter{})
+	Register(newJiraImporter())
+	Register(newLinearImporter())
+}
+
+// MergeMapping returns base with the entries of override added on top
+func MergeMapping(base, override config.ImportMapping) config.ImportMapping {
+	return config.ImportMapping{
+		Columns:  mergeMap(base.Columns, override.Columns),
+		Status:   mergeMap(base.Status, override.Status),
+		Priority: mergeMap(base.Priority, override.Priority),
+		Type:     mergeMap(base.Type, override.Type),
+	}
+}
+
+func mergeMap(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[strings.ToLower(k)] = v
+	}
+	for k, v := range override {
+		merged[strings.ToLower(k)] = v
+	}
+	return merged
+}
+
+// Read parses an export file with the named importer and maps the records
+// onto task fields. Mapping entries from cfg (and then override) are applied
+// on top of the importer defaults. External IDs are prefixed with the
+// importer name so records from different trackers never collide.
+func Read(name string, r io.Reader, cfg *config.Config, override *config.ImportMapping) ([]task.ImportRecord, error) {
+	imp, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown importer: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	mapping := MergeMapping(imp.DefaultMapping(), config.ImportMapping{})
+	if cfg != nil {
+		if m, ok := cfg.Import[name]; ok {
+			mapping = MergeMapping(mapping, m)
+		}
+	}
+	if override != nil {
+		mapping = MergeMapping(mapping, *override)
+	}
+
+	records, err := imp.Parse(r, mapping.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("%s import: %w", name, err)
+	}
+
+	var validTypes []string
+	if cfg != nil {
+		validTypes = cfg.TaskTypes
+	}
+	return Convert(name, records, mapping, validTypes)
+}
+
+// Convert maps raw records onto task import records
+func Convert(source string, records []Record, mapping config.ImportMapping, validTypes []string) ([]task.ImportRecord, error) {
+	// Parents may be referenced by their alternative ID
+	idByAlt := make(map[string]string)
+	for _, rec := range records {
+		if rec.AltID != "" {
+			idByAlt[rec.AltID] = rec.ID
+		}
+	}
+
+	result := make([]task.ImportRecord, 0, len(records))
+	for i, rec := range records {
+		if rec.ID == "" {
+			return nil, fmt.Errorf("record %d has no ID", i+1)
+		}
+		out := task.ImportRecord{
+			ExternalID:  source + ":" + rec.ID,
+			Title:       rec.Title,
+			Description: rec.Description,
+			Status:      mapStatus(rec.State, mapping.Status),
+			Priority:    mapPriority(rec.Priority, rec.Labels, mapping.Priority),
+			Type:        mapType(rec.Type, rec.Labels, mapping.Type, validTypes),
+			Tags:        rec.Labels,
+		}
+		if rec.ParentID != "" {
+			parent := rec.ParentID
+			if id, ok := idByAlt[parent]; ok {
+				parent = id
+			}
+			out.ParentExternalID = source + ":" + parent
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
+
+// lookup finds value in a lower-cased mapping
+func lookup(mapping map[string]string, value string) (string, bool) {
+	v, ok := mapping[strings.ToLower(strings.TrimSpace(value))]
+	return v, ok
+}
+
+func mapStatus(state string, mapping map[string]string) task.Status {
+	if v, ok := lookup(mapping, state); ok && task.IsValidStatus(v) {
+		return task.Status(v)
+	}
+	if task.IsValidStatus(state) {
+		return task.Status(state)
+	}
+	return task.StatusTodo
+}
+
+func mapPriority(priority string, labels []string, mapping map[string]string) task.Priority {
+	if v, ok := lookup(mapping, priority); ok && task.IsValidPriority(v) {
+		return task.Priority(v)
+	}
+	if task.IsValidPriority(strings.ToLower(priority)) {
+		return task.Priority(strings.ToLower(priority))
+	}
+	for _, label := range labels {
+		if v, ok := lookup(mapping, label); ok && task.IsValidPriority(v) {
+			return task.Priority(v)
+		}
+	}
+	return task.PriorityMedium
+}
+
+func mapType(issueType string, labels []string, mapping map[string]string, validTypes []string) string {
+	isValid := func(t string) bool {
+		for _, valid := range validTypes {
+			if t == valid {
+				return true
+			}
+		}
+		return false
+	}
+
+	candidates := append([]string{issueType}, labels...)
+	for _, c := range candidates {
+		if v, ok := lookup(mapping, c); ok && isValid(v) {
+			return v
+		}
+		if isValid(strings.ToLower(c)) {
+			return strings.ToLower(c)
+		}
+	}
+	if len(validTypes) > 0 {
+		return validTypes[0]
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+func testConfig() *config.Config {
+	return config.DefaultConfig()
+}
+
+func TestRead_GitHub(t *testing.T) {
+	input := `[
+  {"number": 12, "title": "Crash on start", "body": "Stack trace", "state": "OPEN",
+   "labels": [{"name": "bug"}, {"name": "P1"}]},
+  {"number": 13, "title": "Dark mode", "body": "", "state": "CLOSED",
+   "labels": [{"name": "enhancement"}], "parent": {"number": 12}}
+]`
+	records, err := Read("github", strings.NewReader(input), testConfig(), nil)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(records))
+	}
+
+	crash := records[0]
+	if crash.ExternalID != "github:12" {
+		t.Errorf("ExternalID = %q, want github:12", crash.ExternalID)
+	}
+	if crash.Status != task.StatusTodo || crash.Priority != task.PriorityHigh || crash.Type != "bug" {
+		t.Errorf("crash = %s/%s/%s, want todo/high/bug", crash.Status, crash.Priority, crash.Type)
+	}
+	if len(crash.Tags) != 2 {
+		t.Errorf("Tags = %v, want labels", crash.Tags)
+	}
+
+	dark := records[1]
+	if dark.Status != task.StatusDone || dark.Type != "feature" || dark.Priority != task.PriorityMedium {
+		t.Errorf("dark = %s/%s/%s, want done/feature/medium", dark.Status, dark.Priority, dark.Type)
+	}
+	if dark.ParentExternalID != "github:12" {
+		t.Errorf("ParentExternalID = %q, want github:12", dark.ParentExternalID)
+	}
+}
+
+func TestRead_Jira(t *testing.T) {
+	input := "Summary,Issue key,Issue id,Parent id,Issue Type,Status,Priority,Labels,Labels\n" +
+		"Login epic,PROJ-1,10001,,Epic,In Progress,Highest,auth,\n" +
+		"Fix token refresh,PROJ-2,10002,10001,Bug,To Do,Minor,auth,backend\n"
+
+	records, err := Read("jira", strings.NewReader(input), testConfig(), nil)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(records))
+	}
+	if records[0].Status != task.StatusInProgress || records[0].Priority != task.PriorityCritical || records[0].Type != "feature" {
+		t.Errorf("epic = %s/%s/%s, want in_progress/critical/feature", records[0].Status, records[0].Priority, records[0].Type)
+	}
+	fix := records[1]
+	if fix.ParentExternalID != "jira:PROJ-1" {
+		t.Errorf("ParentExternalID = %q, want jira:PROJ-1 (resolved from issue id)", fix.ParentExternalID)
+	}
+	if fix.Type != "bug" || fix.Priority != task.PriorityLow {
+		t.Errorf("fix = %s/%s, want bug/low", fix.Type, fix.Priority)
+	}
+	if strings.Join(fix.Tags, ",") != "auth,backend" {
+		t.Errorf("Tags = %v, want [auth backend] from repeated Labels columns", fix.Tags)
+	}
+}
+
+func TestRead_LinearWithMapping(t *testing.T) {
+	input := "ID,Title,Description,Status,Priority,Labels,Parent issue\n" +
+		"ENG-1,Parent,,Backlog,Urgent,\"Feature, Infra\",\n" +
+		"ENG-2,Child,Details,Shipped,Low,Bug,ENG-1\n"
+
+	cfg := testConfig()
+	cfg.Import = map[string]config.ImportMapping{
+		"linear": {Status: map[string]string{"Shipped": "done"}},
+	}
+	override := &config.ImportMapping{Priority: map[string]string{"low": "high"}}
+
+	records, err := Read("linear", strings.NewReader(input), cfg, override)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if records[0].Priority != task.PriorityCritical || records[0].Type != "feature" {
+		t.Errorf("parent = %s/%s, want critical/feature", records[0].Priority, records[0].Type)
+	}
+	if records[1].Status != task.StatusDone {
+		t.Errorf("child status = %s, want done (config mapping)", records[1].Status)
+	}
+	if records[1].Priority != task.PriorityHigh {
+		t.Errorf("child priority = %s, want high (override mapping)", records[1].Priority)
+	}
+	if records[1].ParentExternalID != "linear:ENG-1" {
+		t.Errorf("ParentExternalID = %q, want linear:ENG-1", records[1].ParentExternalID)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	if _, err := Read("trello", strings.NewReader(""), testConfig(), nil); err == nil {
+		t.Error("Read() with unknown importer should fail")
+	}
+	if _, err := Read("jira", strings.NewReader("Foo,Bar\n1,2\n"), testConfig(), nil); err == nil {
+		t.Error("Read() with missing columns should fail")
+	}
+	if _, err := Read("github", strings.NewReader("{not json"), testConfig(), nil); err == nil {
+		t.Error("Read() with invalid JSON should fail")
+	}
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// ArchiveEntry is an archived task's metadata in the archive index
+type ArchiveEntry struct {
+	IndexEntry
+	ArchivedAt time.Time           `json:"archived_at"`
+	Stripped   []task.RelationEdge `json:"stripped_relations,omitempty"` // Relations earlier versions removed from other tasks when archiving
+}
+
+// ArchiveIndexFile is the on-disk format of the archive index
+type ArchiveIndexFile struct {
+	Tasks []*ArchiveEntry `json:"tasks"`
+}
+
+// ArchiveIndex keeps the metadata of archived tasks in archive/.index.json,
+// so listing them does not read every archived file. It implements
+// task.ArchiveStorage on top of MarkdownStorage.
+type ArchiveIndex struct {
+	storage *MarkdownStorage
+	entries map[int]*ArchiveEntry
+	modTime time.Time // Modification time of the index file the entries match
+}
+
+// NewArchiveIndex creates the archive index for a storage's archive directory
+func NewArchiveIndex(storage *MarkdownStorage) *ArchiveIndex {
+	return &ArchiveIndex{storage: storage}
+}
+
+func (a *ArchiveIndex) dir() string {
+	return filepath.Join(a.storage.dir, "archive")
+}
+
+func (a *ArchiveIndex) indexPath() string {
+	return filepath.Join(a.dir(), ".index.json")
+}
+
+// load reads the index on first use and again when another process changed
+// it or the archived files. It is rebuilt from the archived files when
+// missing, corrupt or older than them; recorded stripped relations of tasks
+// still archived are kept.
+func (a *ArchiveIndex) load() error {
+	if a.entries != nil && !a.changedOnDisk() {
+		return nil
+	}
+	a.entries = make(map[int]*ArchiveEntry)
+	a.modTime = time.Time{}
+
+	var indexFile ArchiveIndexFile
+	data, err := os.ReadFile(a.indexPath())
+	if err == nil && json.Unmarshal(data, &indexFile) == nil {
+		for _, e := range indexFile.Tasks {
+			a.entries[e.ID] = e
+		}
+		if !a.isStale() {
+			a.modTime = a.indexModTime()
+			return nil
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tasks, err := a.storage.LoadAllArchived()
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 && len(a.entries) == 0 {
+		return nil
+	}
+	previous := a.entries
+	a.entries = make(map[int]*ArchiveEntry, len(tasks))
+	for _, t := range tasks {
+		entry := &ArchiveEntry{IndexEntry: *taskToEntry(t), ArchivedAt: t.UpdatedAt}
+		if old, ok := previous[t.ID]; ok {
+			entry.ArchivedAt, entry.Stripped = old.ArchivedAt, old.Stripped
+		}
+		a.entries[t.ID] = entry
+	}
+	return a.save()
+}
+
+// changedOnDisk reports whether the index file was rewritten since it was
+// read or the archived files changed after it
+func (a *ArchiveIndex) changedOnDisk() bool {
+	return !a.indexModTime().Equal(a.modTime) || a.isStale()
+}
+
+// indexModTime returns the index file's modification time, zero if missing
+func (a *ArchiveIndex) indexModTime() time.Time {
+	info, err := os.Stat(a.indexPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// isStale reports whether archived files were added, removed or changed
+// after the index was written
+func (a *ArchiveIndex) isStale() bool {
+	indexInfo, err := os.Stat(a.indexPath())
+	if err != nil {
+		return true
+	}
+	files, err := os.ReadDir(a.dir())
+	if err != nil {
+		return true
+	}
+	count := 0
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".md") {
+			continue
+		}
+		count++
+		info, err := f.Info()
+		if err != nil || info.ModTime().After(indexInfo.ModTime()) {
+			return true
+		}
+	}
+	return count != len(a.entries)
+}
+
+func (a *ArchiveIndex) save() error {
+	if err := os.MkdirAll(a.dir(), 0755); err != nil {
+		return err
+	}
+	indexFile := ArchiveIndexFile{Tasks: make([]*ArchiveEntry, 0, len(a.entries))}
+	for _, e := range a.entries {
+		indexFile.Tasks = append(indexFile.Tasks, e)
+	}
+	sort.Slice(indexFile.Tasks, func(i, j int) bool { return indexFile.Tasks[i].ID < indexFile.Tasks[j].ID })
+	data, err := json.MarshalIndent(indexFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := a.indexPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, a.indexPath()); err != nil {
+		return err
+	}
+	a.modTime = a.indexModTime()
+	return nil
+}
+
+// Archive moves a task file to the archive and adds it to the index
+func (a *ArchiveIndex) Archive(id int) error {
+	if err := a.load(); err != nil {
+		return err
+	}
+	if err := a.storage.Archive(id); err != nil {
+		return err
+	}
+	t, err := a.storage.LoadArchived(id)
+	if err != nil {
+		return err
+	}
+	a.entries[id] = &ArchiveEntry{IndexEntry: *taskToEntry(t), ArchivedAt: time.Now().UTC()}
+	return a.save()
+}
+
+// Unarchive moves a task file back to the tasks directory and returns the
+// relations earlier versions removed from other tasks when archiving it
+func (a *ArchiveIndex) Unarchive(id int) ([]task.RelationEdge, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	entry, ok := a.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("archived task not found: %d", id)
+	}
+	if err := a.storage.Unarchive(id); err != nil {
+		return nil, err
+	}
+	delete(a.entries, id)
+	return entry.Stripped, a.save()
+}
+
+// LoadArchived reads an archived task with its description
+func (a *ArchiveIndex) LoadArchived(id int) (*task.Task, error) {
+	return a.storage.LoadArchived(id)
+}
+
+// IsArchived reports whether a task is in the archive
+func (a *ArchiveIndex) IsArchived(id int) bool {
+	if err := a.load(); err != nil {
+		return a.storage.IsArchived(id)
+	}
+	_, ok := a.entries[id]
+	return ok
+}
+
+// FilterArchived returns archived tasks matching the given criteria, sorted
+// by ID and without descriptions.
+// parentID: nil = all tasks, 0 = top-level only, >0 = subtasks of that parent
+func (a *ArchiveIndex) FilterArchived(status *task.Status, priority *task.Priority, taskType *string, parentID *int) ([]*task.Task, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	var result []*task.Task
+	for _, e := range a.entries {
+		if status != nil && e.Status != *status {
+			continue
+		}
+		if priority != nil && e.Priority != *priority {
+			continue
+		}
+		if taskType != nil && e.Type != *taskType {
+			continue
+		}
+		if parentID != nil {
+			if *parentID == 0 && e.ParentID != nil {
+				continue
+			}
+			if *parentID != 0 && (e.ParentID == nil || *e.ParentID != *parentID) {
+				continue
+			}
+		}
+		result = append(result, entryToTask(&e.IndexEntry))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// NextID returns the ID after the highest archived one, so that new tasks
+// do not reuse the IDs of archived tasks
+func (a *ArchiveIndex) NextID() int {
+	maxID := 0
+	if err := a.load(); err == nil {
+		for id := range a.entries {
+			maxID = max(maxID, id)
+		}
+	}
+	return maxID + 1
+}
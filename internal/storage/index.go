@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gpayer/mcp-task-manager/internal/config"
 	"github.com/gpayer/mcp-task-manager/internal/task"
 )
 
@@ -47,14 +49,21 @@ func getGitCommit(dir string) (string, error) {
 
 // IndexEntry contains task metadata without description (stored in index)
 type IndexEntry struct {
-	ID        int           `json:"id"`
-	ParentID  *int          `json:"parent_id,omitempty"`
-	Title     string        `json:"title"`
-	Status    task.Status   `json:"status"`
-	Priority  task.Priority `json:"priority"`
-	Type      string        `json:"type"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	ID          int           `json:"id"`
+	ParentID    *int          `json:"parent_id,omitempty"`
+	Title       string        `json:"title"`
+	Status      task.Status   `json:"status"`
+	Priority    task.Priority `json:"priority"`
+	Type        string        `json:"type"`
+	Tags        []string      `json:"tags,omitempty"`
+	ExternalID  string        `json:"external_id,omitempty"`
+	Order       int           `json:"order,omitempty"`
+	Resolution  string        `json:"resolution,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	// Relations to tasks in other workspace projects, which have no edges
+	ProjectRelations []task.Relation `json:"project_relations,omitempty"`
 }
 
 // IndexFile is the on-disk format for the index
@@ -62,43 +71,61 @@ type IndexFile struct {
 	GitCommit string              `json:"git_commit"`
 	Tasks     []*IndexEntry       `json:"tasks"`
 	Relations []task.RelationEdge `json:"relations,omitempty"`
+	Symmetric []string            `json:"symmetric"` // Symmetric relation types reverse edges were generated for (missing = defaults)
 }
 
 // taskToEntry converts a Task to an IndexEntry
 func taskToEntry(t *task.Task) *IndexEntry {
 	return &IndexEntry{
-		ID:        t.ID,
-		ParentID:  t.ParentID,
-		Title:     t.Title,
-		Status:    t.Status,
-		Priority:  t.Priority,
-		Type:      t.Type,
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+		ID:          t.ID,
+		ParentID:    t.ParentID,
+		Title:       t.Title,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		Type:        t.Type,
+		Tags:        t.Tags,
+		ExternalID:  t.ExternalID,
+		Order:       t.Order,
+		Resolution:  t.Resolution,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		CompletedAt: t.CompletedAt,
+
+		ProjectRelations: projectRelations(t.Relations),
+	}
+}
+
+// projectRelations returns the relations pointing into other projects
+func projectRelations(relations []task.Relation) []task.Relation {
+	var result []task.Relation
+	for _, rel := range relations {
+		if rel.Project != "" {
+			result = append(result, rel)
+		}
 	}
+	return result
 }
 
 // entryToTask converts an IndexEntry back to a Task (without description)
 func entryToTask(e *IndexEntry) *task.Task {
 	return &task.Task{
-		ID:        e.ID,
-		ParentID:  e.ParentID,
-		Title:     e.Title,
-		Status:    e.Status,
-		Priority:  e.Priority,
-		Type:      e.Type,
-		CreatedAt: e.CreatedAt,
-		UpdatedAt: e.UpdatedAt,
+		ID:          e.ID,
+		ParentID:    e.ParentID,
+		Title:       e.Title,
+		Status:      e.Status,
+		Priority:    e.Priority,
+		Type:        e.Type,
+		Tags:        e.Tags,
+		ExternalID:  e.ExternalID,
+		Order:       e.Order,
+		Resolution:  e.Resolution,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+		CompletedAt: e.CompletedAt,
 		// Description intentionally empty
 	}
 }
 
-// SymmetricRelationType is a relation type where reverse edges are auto-generated
-const SymmetricRelationType = "relates_to"
-
-// BlockingRelationType is the relation type that affects task execution order
-const BlockingRelationType = "blocked_by"
-
 // Index is an in-memory cache of all tasks
 type Index struct {
 	entries           map[int]*IndexEntry
@@ -107,17 +134,65 @@ type Index struct {
 	dir               string
 	storage           *MarkdownStorage
 	dirty             bool
+	symmetric         map[string]bool // Relation types where reverse edges are auto-generated
+	blocking          map[string]bool // Relation types that affect task execution order
+	duplicate         map[string]bool // Relation types marking the source as a duplicate
+	projectBlocker    func(rel task.Relation) bool
+	children          map[int][]*IndexEntry // Direct subtasks by parent ID; nil until needed after a change
 }
 
 // NewIndex creates a new index for the given directory
 func NewIndex(dir string, storage *MarkdownStorage) *Index {
-	return &Index{
+	idx := &Index{
 		entries:           make(map[int]*IndexEntry),
 		relationsBySource: make(map[int][]task.RelationEdge),
 		relationsByTarget: make(map[int][]task.RelationEdge),
 		dir:               dir,
 		storage:           storage,
 	}
+	idx.SetRelationTypes(config.DefaultRelationTypes)
+	return idx
+}
+
+// SetRelationTypes configures which relation types are symmetric, blocking
+// or mark duplicates. Call before Load.
+func (idx *Index) SetRelationTypes(types []config.RelationType) {
+	idx.symmetric = make(map[string]bool)
+	idx.blocking = make(map[string]bool)
+	idx.duplicate = make(map[string]bool)
+	for _, rt := range types {
+		if rt.Duplicate {
+			idx.duplicate[rt.Name] = true
+		}
+		if rt.Symmetric {
+			idx.symmetric[rt.Name] = true
+		}
+		if rt.Blocking {
+			idx.blocking[rt.Name] = true
+		}
+	}
+}
+
+// defaultSymmetricTypes returns the default symmetric relation types, sorted
+func defaultSymmetricTypes() []string {
+	var types []string
+	for _, rt := range config.DefaultRelationTypes {
+		if rt.Symmetric {
+			types = append(types, rt.Name)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// symmetricTypes returns the symmetric relation types, sorted
+func (idx *Index) symmetricTypes() []string {
+	types := []string{}
+	for t := range idx.symmetric {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
 }
 
 // indexPath returns path to the index file
@@ -127,6 +202,7 @@ func (idx *Index) indexPath() string {
 
 func (idx *Index) reset() {
 	idx.entries = make(map[int]*IndexEntry)
+	idx.children = nil
 	idx.relationsBySource = make(map[int][]task.RelationEdge)
 	idx.relationsByTarget = make(map[int][]task.RelationEdge)
 	idx.dirty = false
@@ -142,6 +218,9 @@ func (idx *Index) rebuildFromTasks(tasks []*task.Task) error {
 	// Build relation edges from task frontmatter.
 	for _, t := range tasks {
 		for _, rel := range t.Relations {
+			if rel.Project != "" {
+				continue // Kept on the entry; the target is not in this index
+			}
 			edge := task.RelationEdge{
 				Type:   rel.Type,
 				Source: t.ID,
@@ -149,7 +228,7 @@ func (idx *Index) rebuildFromTasks(tasks []*task.Task) error {
 			}
 			idx.addEdge(edge)
 			// Symmetric types generate a reverse edge.
-			if rel.Type == SymmetricRelationType {
+			if idx.symmetric[rel.Type] {
 				reverse := task.RelationEdge{
 					Type:   rel.Type,
 					Source: rel.Task,
@@ -215,6 +294,7 @@ func (idx *Index) Save() error {
 		GitCommit: gitCommit,
 		Tasks:     entries,
 		Relations: relations,
+		Symmetric: idx.symmetricTypes(),
 	}
 
 	data, err := json.MarshalIndent(indexFile, "", "  ")
@@ -259,8 +339,19 @@ func (idx *Index) Load() error {
 		return idx.Rebuild()
 	}
 
+	// Reverse edges were generated for different symmetric relation types.
+	// Index files written before this was recorded used the defaults.
+	generated := indexFile.Symmetric
+	if generated == nil {
+		generated = defaultSymmetricTypes()
+	}
+	if strings.Join(generated, ",") != strings.Join(idx.symmetricTypes(), ",") {
+		return idx.Rebuild()
+	}
+
 	// Load entries into memory
 	idx.entries = make(map[int]*IndexEntry)
+	idx.children = nil
 	for _, e := range indexFile.Tasks {
 		idx.entries[e.ID] = e
 	}
@@ -337,6 +428,33 @@ func (idx *Index) isStaleOnDisk() (bool, error) {
 	return false, nil
 }
 
+// DirSignature summarises the task files and index in dir so that changes
+// by other processes can be detected without reloading everything
+func DirSignature(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var count int
+	var size int64
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), ".md") || entry.Name() == ".index.json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		count++
+		size += info.Size()
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d:%d", filepath.Clean(dir), count, size, latest.UnixNano())
+}
+
 // GetEntry returns an entry by ID (metadata only, no description)
 func (idx *Index) GetEntry(id int) (*IndexEntry, bool) {
 	idx.syncIfStale()
@@ -360,12 +478,14 @@ func (idx *Index) Get(id int) (*task.Task, bool) {
 // Set adds or updates a task in the index
 func (idx *Index) Set(t *task.Task) {
 	idx.entries[t.ID] = taskToEntry(t)
+	idx.children = nil
 	idx.dirty = true
 }
 
 // Delete removes a task from the index
 func (idx *Index) Delete(id int) {
 	delete(idx.entries, id)
+	idx.children = nil
 	idx.dirty = true
 }
 
@@ -412,22 +532,23 @@ func (idx *Index) Filter(status *task.Status, priority *task.Priority, taskType
 		}
 		result = append(result, entryToTask(e))
 	}
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].ID < result[j].ID
-	})
+	task.SortByRank(result)
 	return result
 }
 
 type nextTodoGroupKey struct {
-	priorityOrder    int
-	createdAt        time.Time
-	id               int
-	inProgressParent bool
+	priorityOrder int
+	rank          int
+	createdAt     time.Time
+	id            int
+	inProgress    bool
 }
 
+// nextTodoGroup collects the candidates below one task, each as its path
+// from the top-level ancestor down to the candidate itself
 type nextTodoGroup struct {
 	key   nextTodoGroupKey
-	tasks []*task.Task
+	paths [][]*IndexEntry
 }
 
 func (idx *Index) isActionableForNextTodo(e *IndexEntry) bool {
@@ -437,96 +558,100 @@ func (idx *Index) isActionableForNextTodo(e *IndexEntry) bool {
 	if idx.HasSubtasks(e.ID) {
 		return false
 	}
+	if _, dup := idx.DuplicateOf(e.ID); dup {
+		return false
+	}
 	return !idx.isBlocked(e.ID)
 }
 
-func (idx *Index) nextTodoGroupForEntry(e *IndexEntry) (int, nextTodoGroupKey) {
-	groupID := e.ID
-	key := nextTodoGroupKey{
-		priorityOrder:    e.Priority.Order(),
-		createdAt:        e.CreatedAt,
-		id:               e.ID,
-		inProgressParent: e.Status == task.StatusInProgress,
+func nextTodoKeyForEntry(e *IndexEntry) nextTodoGroupKey {
+	return nextTodoGroupKey{
+		priorityOrder: e.Priority.Order(),
+		rank:          entryToTask(e).Rank(),
+		createdAt:     e.CreatedAt,
+		id:            e.ID,
+		inProgress:    e.Status == task.StatusInProgress,
+	}
+}
+
+// before reports whether left comes first. byRank is set once any of the
+// siblings compared has been moved explicitly; their rank then overrides
+// priority and creation time, matching the order they are listed in.
+func (left nextTodoGroupKey) before(right nextTodoGroupKey, byRank bool) bool {
+	if left.inProgress != right.inProgress {
+		return left.inProgress
+	}
+	if byRank && left.rank != right.rank {
+		return left.rank < right.rank
+	}
+	if left.priorityOrder != right.priorityOrder {
+		return left.priorityOrder < right.priorityOrder
 	}
+	if !left.createdAt.Equal(right.createdAt) {
+		return left.createdAt.Before(right.createdAt)
+	}
+	return left.id < right.id
+}
 
-	if e.ParentID != nil {
-		if parent, ok := idx.GetEntry(*e.ParentID); ok {
-			groupID = parent.ID
-			key = nextTodoGroupKey{
-				priorityOrder:    parent.Priority.Order(),
-				createdAt:        parent.CreatedAt,
-				id:               parent.ID,
-				inProgressParent: parent.Status == task.StatusInProgress,
-			}
+// ancestorPath returns the entries from the top-level ancestor of e down to e
+func (idx *Index) ancestorPath(e *IndexEntry) []*IndexEntry {
+	path := []*IndexEntry{e}
+	seen := map[int]bool{e.ID: true}
+	for cur := e; cur.ParentID != nil && !seen[*cur.ParentID]; {
+		parent, ok := idx.entries[*cur.ParentID]
+		if !ok {
+			break
 		}
+		seen[parent.ID] = true
+		path = append([]*IndexEntry{parent}, path...)
+		cur = parent
 	}
-
-	return groupID, key
+	return path
 }
 
 // NextTodo returns the highest priority actionable todo task.
-// Parent tasks with subtasks are skipped. Subtasks inherit their parent's
-// priority, creation date, and ID for group selection, then compete within the
-// winning group by their own priority, creation date, and ID.
+// Parent tasks with subtasks are skipped. Candidates are grouped by their
+// top-level ancestor, and the best group is chosen by that ancestor's status,
+// priority, creation date and ID; once any of the ancestors compared has been
+// moved explicitly, their rank replaces priority and creation date. The
+// winning group is then split by the next level down in the same way until a
+// single task remains.
 func (idx *Index) NextTodo() *task.Task {
 	idx.syncIfStale()
-	groups := make(map[int]*nextTodoGroup)
-
+	var paths [][]*IndexEntry
 	for _, e := range idx.entries {
-		if !idx.isActionableForNextTodo(e) {
-			continue
+		if idx.isActionableForNextTodo(e) {
+			paths = append(paths, idx.ancestorPath(e))
 		}
-
-		candidate := entryToTask(e)
-		groupID, key := idx.nextTodoGroupForEntry(e)
-
-		group, ok := groups[groupID]
-		if !ok {
-			group = &nextTodoGroup{key: key}
-			groups[groupID] = group
-		}
-		group.tasks = append(group.tasks, candidate)
-	}
-
-	if len(groups) == 0 {
-		return nil
-	}
-
-	groupList := make([]*nextTodoGroup, 0, len(groups))
-	for _, group := range groups {
-		groupList = append(groupList, group)
 	}
 
-	sort.Slice(groupList, func(i, j int) bool {
-		left := groupList[i].key
-		right := groupList[j].key
-		if left.inProgressParent != right.inProgressParent {
-			return left.inProgressParent
+	for level := 0; len(paths) > 0; level++ {
+		if len(paths) == 1 {
+			return entryToTask(paths[0][len(paths[0])-1])
 		}
-		if left.priorityOrder != right.priorityOrder {
-			return left.priorityOrder < right.priorityOrder
-		}
-		if !left.createdAt.Equal(right.createdAt) {
-			return left.createdAt.Before(right.createdAt)
-		}
-		return left.id < right.id
-	})
 
-	winningGroup := groupList[0].tasks
-	sort.Slice(winningGroup, func(i, j int) bool {
-		if winningGroup[i].Status != winningGroup[j].Status {
-			return winningGroup[i].Status == task.StatusInProgress
-		}
-		if winningGroup[i].Priority.Order() != winningGroup[j].Priority.Order() {
-			return winningGroup[i].Priority.Order() < winningGroup[j].Priority.Order()
-		}
-		if !winningGroup[i].CreatedAt.Equal(winningGroup[j].CreatedAt) {
-			return winningGroup[i].CreatedAt.Before(winningGroup[j].CreatedAt)
+		groups := make(map[int]*nextTodoGroup)
+		byRank := false
+		for _, path := range paths {
+			head := path[min(level, len(path)-1)]
+			group, ok := groups[head.ID]
+			if !ok {
+				group = &nextTodoGroup{key: nextTodoKeyForEntry(head)}
+				groups[head.ID] = group
+				byRank = byRank || head.Order != 0
+			}
+			group.paths = append(group.paths, path)
 		}
-		return winningGroup[i].ID < winningGroup[j].ID
-	})
 
-	return winningGroup[0]
+		var winner *nextTodoGroup
+		for _, group := range groups {
+			if winner == nil || group.key.before(winner.key, byRank) {
+				winner = group
+			}
+		}
+		paths = winner.paths
+	}
+	return nil
 }
 
 // NextID returns the next available task ID
@@ -545,55 +670,91 @@ func (idx *Index) NextID() int {
 func (idx *Index) GetSubtasks(parentID int) []*task.Task {
 	idx.syncIfStale()
 	var result []*task.Task
-	for _, e := range idx.entries {
-		if e.ParentID != nil && *e.ParentID == parentID {
-			result = append(result, entryToTask(e))
-		}
+	for _, e := range idx.subtaskEntries(parentID) {
+		result = append(result, entryToTask(e))
 	}
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].ID < result[j].ID
-	})
+	task.SortByRank(result)
 	return result
 }
 
 // HasSubtasks returns true if the task has any subtasks
 func (idx *Index) HasSubtasks(taskID int) bool {
 	idx.syncIfStale()
-	for _, e := range idx.entries {
-		if e.ParentID != nil && *e.ParentID == taskID {
-			return true
-		}
-	}
-	return false
+	return len(idx.subtaskEntries(taskID)) > 0
 }
 
-// SubtaskCounts returns (total, done) counts for a parent task
+// SubtaskCounts returns (total, done) counts for all descendants of a parent task
 func (idx *Index) SubtaskCounts(parentID int) (total int, done int) {
 	idx.syncIfStale()
-	for _, e := range idx.entries {
-		if e.ParentID != nil && *e.ParentID == parentID {
-			total++
-			if e.Status == task.StatusDone {
-				done++
+	seen := map[int]bool{parentID: true}
+	queue := []int{parentID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range idx.subtaskEntries(id) {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				queue = append(queue, e.ID)
+				total++
+				if e.Status == task.StatusDone {
+					done++
+				}
 			}
 		}
 	}
 	return
 }
 
-// isBlocked checks if a task has any unresolved blocked_by relations
+// subtaskEntries returns the direct subtasks of a task. The parent to
+// children map is built once after the entries change, so counting the
+// subtasks of every listed task does not rescan the whole index each time.
+func (idx *Index) subtaskEntries(parentID int) []*IndexEntry {
+	if idx.children == nil {
+		idx.children = make(map[int][]*IndexEntry)
+		for _, e := range idx.entries {
+			if e.ParentID != nil {
+				idx.children[*e.ParentID] = append(idx.children[*e.ParentID], e)
+			}
+		}
+	}
+	return idx.children[parentID]
+}
+
+// isBlocked checks if a task has any unresolved blocking relations
 func (idx *Index) isBlocked(taskID int) bool {
 	idx.syncIfStale()
 	for _, e := range idx.relationsBySource[taskID] {
-		if e.Type == BlockingRelationType {
+		if idx.blocking[e.Type] {
 			if target, ok := idx.entries[e.Target]; ok && target.Status != task.StatusDone {
 				return true
 			}
 		}
 	}
+	if e, ok := idx.entries[taskID]; ok && idx.projectBlocker != nil {
+		for _, rel := range e.ProjectRelations {
+			if idx.blocking[rel.Type] && idx.projectBlocker(rel) {
+				return true
+			}
+		}
+	}
 	return false
 }
 
+// ProjectRelations returns the relations of a task to tasks in other projects
+func (idx *Index) ProjectRelations(taskID int) []task.Relation {
+	idx.syncIfStale()
+	if e, ok := idx.entries[taskID]; ok {
+		return e.ProjectRelations
+	}
+	return nil
+}
+
+// SetProjectBlocker sets the check NextTodo uses for blocking relations to
+// tasks in other projects. Without one they never block.
+func (idx *Index) SetProjectBlocker(blocking func(rel task.Relation) bool) {
+	idx.projectBlocker = blocking
+}
+
 // addEdge adds an edge to both lookup maps (internal helper, no persistence)
 func (idx *Index) addEdge(edge task.RelationEdge) {
 	idx.relationsBySource[edge.Source] = append(idx.relationsBySource[edge.Source], edge)
@@ -624,7 +785,7 @@ func (idx *Index) removeEdge(edge task.RelationEdge) {
 func (idx *Index) AddRelation(edge task.RelationEdge) {
 	idx.addEdge(edge)
 	// Symmetric types generate a reverse edge
-	if edge.Type == SymmetricRelationType {
+	if idx.symmetric[edge.Type] {
 		reverse := task.RelationEdge{
 			Type:   edge.Type,
 			Source: edge.Target,
@@ -639,7 +800,7 @@ func (idx *Index) AddRelation(edge task.RelationEdge) {
 func (idx *Index) RemoveRelation(edge task.RelationEdge) {
 	idx.removeEdge(edge)
 	// Symmetric types also remove the reverse edge
-	if edge.Type == SymmetricRelationType {
+	if idx.symmetric[edge.Type] {
 		reverse := task.RelationEdge{
 			Type:   edge.Type,
 			Source: edge.Target,
@@ -671,18 +832,41 @@ func (idx *Index) GetRelationsForTask(taskID int) []task.RelationEdge {
 	return result
 }
 
-// GetBlockers returns target IDs from blocked_by edges where source == taskID
+// GetBlockers returns target IDs from blocking edges where source == taskID
 func (idx *Index) GetBlockers(taskID int) []int {
 	idx.syncIfStale()
 	var blockers []int
 	for _, e := range idx.relationsBySource[taskID] {
-		if e.Type == BlockingRelationType {
+		if idx.blocking[e.Type] {
 			blockers = append(blockers, e.Target)
 		}
 	}
 	return blockers
 }
 
+// GetDependents returns source IDs from blocking edges where target == taskID
+func (idx *Index) GetDependents(taskID int) []int {
+	idx.syncIfStale()
+	var dependents []int
+	for _, e := range idx.relationsByTarget[taskID] {
+		if idx.blocking[e.Type] {
+			dependents = append(dependents, e.Source)
+		}
+	}
+	return dependents
+}
+
+// DuplicateOf returns the task that taskID is marked as a duplicate of
+func (idx *Index) DuplicateOf(taskID int) (int, bool) {
+	idx.syncIfStale()
+	for _, e := range idx.relationsBySource[taskID] {
+		if idx.duplicate[e.Type] {
+			return e.Target, true
+		}
+	}
+	return 0, false
+}
+
 // RemoveAllRelationsForTask removes all relations where task appears as source or target
 // Returns the removed edges so the service knows which other task files to update
 func (idx *Index) RemoveAllRelationsForTask(taskID int) []task.RelationEdge {
@@ -36,27 +36,56 @@ func (s *MarkdownStorage) taskPath(id int) string {
 
 // Save writes a task to a markdown file
 func (s *MarkdownStorage) Save(t *task.Task) error {
+	data, err := s.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	// Atomic write: write to temp, then rename
+	tmpPath := s.taskPath(t.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.taskPath(t.ID))
+}
+
+// Marshal renders a task as markdown with frontmatter, as written by Save
+func (s *MarkdownStorage) Marshal(t *task.Task) ([]byte, error) {
 	// Build frontmatter
 	frontmatter := struct {
-		ID        int             `yaml:"id"`
-		ParentID  *int            `yaml:"parent_id,omitempty"`
-		Title     string          `yaml:"title"`
-		Status    task.Status     `yaml:"status"`
-		Priority  task.Priority   `yaml:"priority"`
-		Type      string          `yaml:"type"`
-		Relations []task.Relation `yaml:"relations,omitempty"`
-		CreatedAt string          `yaml:"created_at"`
-		UpdatedAt string          `yaml:"updated_at"`
+		ID          int                 `yaml:"id"`
+		ParentID    *int                `yaml:"parent_id,omitempty"`
+		Title       string              `yaml:"title"`
+		Status      task.Status         `yaml:"status"`
+		Priority    task.Priority       `yaml:"priority"`
+		Type        string              `yaml:"type"`
+		Tags        []string            `yaml:"tags,omitempty"`
+		ExternalID  string              `yaml:"external_id,omitempty"`
+		Order       int                 `yaml:"order,omitempty"`
+		Relations   []task.Relation     `yaml:"relations,omitempty"`
+		Resolution  string              `yaml:"resolution,omitempty"`
+		History     []task.StatusChange `yaml:"history,omitempty"`
+		CreatedAt   string              `yaml:"created_at"`
+		UpdatedAt   string              `yaml:"updated_at"`
+		CompletedAt string              `yaml:"completed_at,omitempty"`
 	}{
-		ID:        t.ID,
-		ParentID:  t.ParentID,
-		Title:     t.Title,
-		Status:    t.Status,
-		Priority:  t.Priority,
-		Type:      t.Type,
-		Relations: t.Relations,
-		CreatedAt: t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:         t.ID,
+		ParentID:   t.ParentID,
+		Title:      t.Title,
+		Status:     t.Status,
+		Priority:   t.Priority,
+		Type:       t.Type,
+		Tags:       t.Tags,
+		ExternalID: t.ExternalID,
+		Order:      t.Order,
+		Relations:  t.Relations,
+		Resolution: t.Resolution,
+		History:    t.History,
+		CreatedAt:  t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:  t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if t.CompletedAt != nil {
+		frontmatter.CompletedAt = t.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
 
 	var buf bytes.Buffer
@@ -64,17 +93,11 @@ func (s *MarkdownStorage) Save(t *task.Task) error {
 	enc := yaml.NewEncoder(&buf)
 	enc.SetIndent(2)
 	if err := enc.Encode(frontmatter); err != nil {
-		return err
+		return nil, err
 	}
 	buf.WriteString("---\n\n")
 	buf.WriteString(t.Description)
-
-	// Atomic write: write to temp, then rename
-	tmpPath := s.taskPath(t.ID) + ".tmp"
-	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
-		return err
-	}
-	return os.Rename(tmpPath, s.taskPath(t.ID))
+	return buf.Bytes(), nil
 }
 
 // Load reads a task from a markdown file
@@ -126,6 +149,11 @@ func (s *MarkdownStorage) LoadAll() ([]*task.Task, error) {
 	return tasks, nil
 }
 
+// Parse reads a task from markdown as produced by Marshal
+func (s *MarkdownStorage) Parse(data []byte) (*task.Task, error) {
+	return s.parse(data)
+}
+
 // parse extracts task from markdown with frontmatter
 func (s *MarkdownStorage) parse(data []byte) (*task.Task, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(data))
@@ -148,15 +176,21 @@ func (s *MarkdownStorage) parse(data []byte) (*task.Task, error) {
 
 	// Parse frontmatter
 	var fm struct {
-		ID        int             `yaml:"id"`
-		ParentID  *int            `yaml:"parent_id"`
-		Title     string          `yaml:"title"`
-		Status    string          `yaml:"status"`
-		Priority  string          `yaml:"priority"`
-		Type      string          `yaml:"type"`
-		Relations []task.Relation `yaml:"relations"`
-		CreatedAt string          `yaml:"created_at"`
-		UpdatedAt string          `yaml:"updated_at"`
+		ID          int                 `yaml:"id"`
+		ParentID    *int                `yaml:"parent_id"`
+		Title       string              `yaml:"title"`
+		Status      string              `yaml:"status"`
+		Priority    string              `yaml:"priority"`
+		Type        string              `yaml:"type"`
+		Tags        []string            `yaml:"tags"`
+		ExternalID  string              `yaml:"external_id"`
+		Order       int                 `yaml:"order"`
+		Relations   []task.Relation     `yaml:"relations"`
+		Resolution  string              `yaml:"resolution"`
+		History     []task.StatusChange `yaml:"history"`
+		CreatedAt   string              `yaml:"created_at"`
+		UpdatedAt   string              `yaml:"updated_at"`
+		CompletedAt string              `yaml:"completed_at"`
 	}
 	if err := yaml.Unmarshal(frontmatterBuf.Bytes(), &fm); err != nil {
 		return nil, err
@@ -174,6 +208,10 @@ func (s *MarkdownStorage) parse(data []byte) (*task.Task, error) {
 	// Parse timestamps
 	createdAt, _ := parseTime(fm.CreatedAt)
 	updatedAt, _ := parseTime(fm.UpdatedAt)
+	var completedAt *time.Time
+	if at, err := parseTime(fm.CompletedAt); err == nil {
+		completedAt = &at
+	}
 
 	return &task.Task{
 		ID:          fm.ID,
@@ -183,9 +221,15 @@ func (s *MarkdownStorage) parse(data []byte) (*task.Task, error) {
 		Status:      task.Status(fm.Status),
 		Priority:    task.Priority(fm.Priority),
 		Type:        fm.Type,
+		Tags:        fm.Tags,
+		ExternalID:  fm.ExternalID,
+		Order:       fm.Order,
 		Relations:   fm.Relations,
+		Resolution:  fm.Resolution,
+		History:     fm.History,
 		CreatedAt:   createdAt,
 		UpdatedAt:   updatedAt,
+		CompletedAt: completedAt,
 	}, nil
 }
 
@@ -218,6 +262,15 @@ func (s *MarkdownStorage) Archive(id int) error {
 	return os.Rename(s.taskPath(id), s.archivePath(id))
 }
 
+// Unarchive moves a task file from the archive subdirectory back to the
+// tasks directory; it fails if an active task file with the same ID exists
+func (s *MarkdownStorage) Unarchive(id int) error {
+	if _, err := os.Stat(s.taskPath(id)); err == nil {
+		return fmt.Errorf("task file %s already exists", filepath.Base(s.taskPath(id)))
+	}
+	return os.Rename(s.archivePath(id), s.taskPath(id))
+}
+
 // LoadArchived reads an archived task from the archive directory
 func (s *MarkdownStorage) LoadArchived(id int) (*task.Task, error) {
 	data, err := os.ReadFile(s.archivePath(id))
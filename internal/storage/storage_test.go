@@ -304,6 +304,27 @@ func TestIndex_NextTodoBreaksTiesByLowerID(t *testing.T) {
 	}
 }
 
+func TestIndex_NextTodo_HonoursExplicitOrderAcrossPriorities(t *testing.T) {
+	dir := t.TempDir()
+	idx := NewIndex(dir, NewMarkdownStorage(dir))
+
+	now := time.Now().UTC()
+	idx.Set(&task.Task{ID: 3, Title: "High", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature", CreatedAt: now, UpdatedAt: now})
+	idx.Set(&task.Task{ID: 4, Title: "Medium", Status: task.StatusTodo, Priority: task.PriorityMedium, Type: "feature", CreatedAt: now, UpdatedAt: now})
+	idx.Set(&task.Task{ID: 5, Title: "Low", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "feature", CreatedAt: now, UpdatedAt: now})
+	if next := idx.NextTodo(); next == nil || next.ID != 3 {
+		t.Fatalf("NextTodo() = %v, want 3 by priority", next)
+	}
+
+	// As after "move 5 --before 3": the explicit order wins over priority
+	idx.Set(&task.Task{ID: 5, Title: "Low", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "feature", Order: 1500, CreatedAt: now, UpdatedAt: now})
+	for i := 0; i < 10; i++ {
+		if next := idx.NextTodo(); next == nil || next.ID != 5 {
+			t.Fatalf("NextTodo() = %v, want 5 moved before 3", next)
+		}
+	}
+}
+
 func TestIndex_NextTodo_UsesParentOrderingForTodoParentSubtasks(t *testing.T) {
 	dir := t.TempDir()
 	storage := NewMarkdownStorage(dir)
@@ -671,6 +692,36 @@ func TestMarkdownStorage_SaveLoad_WithoutParentID(t *testing.T) {
 	}
 }
 
+func TestMarkdownStorage_SaveLoad_CompletedAt(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+
+	completedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	tsk := &task.Task{
+		ID:          1,
+		Title:       "Done task",
+		Status:      task.StatusDone,
+		Priority:    task.PriorityHigh,
+		Type:        "feature",
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		CompletedAt: &completedAt,
+	}
+
+	if err := storage.Save(tsk); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := storage.Load(1)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.CompletedAt == nil || !loaded.CompletedAt.Equal(completedAt) {
+		t.Errorf("CompletedAt = %v, want %v", loaded.CompletedAt, completedAt)
+	}
+}
+
 func TestIndex_RebuildFromFiles(t *testing.T) {
 	dir := t.TempDir()
 	storage := NewMarkdownStorage(dir)
@@ -730,6 +781,64 @@ func TestIndex_GetSubtasks(t *testing.T) {
 	}
 }
 
+func TestIndex_GetSubtasks_HonoursOrder(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+	idx := NewIndex(dir, storage)
+
+	parentID := 1
+	storage.Save(&task.Task{ID: 1, Title: "Parent", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature"})
+	storage.Save(&task.Task{ID: 2, ParentID: &parentID, Title: "Sub 1", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature"})
+	storage.Save(&task.Task{ID: 3, ParentID: &parentID, Title: "Sub 2", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature"})
+	storage.Save(&task.Task{ID: 4, ParentID: &parentID, Title: "Sub 3", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature", Order: 2500})
+
+	idx.Load()
+
+	var ids []int
+	for _, sub := range idx.GetSubtasks(1) {
+		ids = append(ids, sub.ID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 4 || ids[2] != 3 {
+		t.Errorf("GetSubtasks(1) order = %v, want [2 4 3]", ids)
+	}
+
+	// Equal priority: the explicitly ordered subtask wins over creation order
+	if next := idx.NextTodo(); next == nil || next.ID != 2 {
+		t.Errorf("NextTodo() = %v, want task 2", next)
+	}
+	idx.Set(&task.Task{ID: 3, ParentID: &parentID, Title: "Sub 2", Status: task.StatusTodo, Priority: task.PriorityHigh, Type: "feature", Order: 500})
+	if next := idx.NextTodo(); next == nil || next.ID != 3 {
+		t.Errorf("NextTodo() = %v, want task 3", next)
+	}
+}
+
+func TestIndex_NestedSubtasks(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+	idx := NewIndex(dir, storage)
+
+	epicID, storyA, storyB := 1, 2, 3
+	storage.Save(&task.Task{ID: 1, Title: "Epic", Status: task.StatusInProgress, Priority: task.PriorityLow, Type: "feature"})
+	storage.Save(&task.Task{ID: 2, ParentID: &epicID, Title: "Story A", Status: task.StatusTodo, Priority: task.PriorityMedium, Type: "feature"})
+	storage.Save(&task.Task{ID: 3, ParentID: &epicID, Title: "Story B", Status: task.StatusInProgress, Priority: task.PriorityLow, Type: "feature"})
+	storage.Save(&task.Task{ID: 4, ParentID: &storyA, Title: "Step A1", Status: task.StatusTodo, Priority: task.PriorityCritical, Type: "feature"})
+	storage.Save(&task.Task{ID: 5, ParentID: &storyB, Title: "Step B1", Status: task.StatusDone, Priority: task.PriorityLow, Type: "feature"})
+	storage.Save(&task.Task{ID: 6, ParentID: &storyB, Title: "Step B2", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "feature"})
+	storage.Save(&task.Task{ID: 7, Title: "Standalone", Status: task.StatusTodo, Priority: task.PriorityCritical, Type: "feature"})
+
+	idx.Load()
+
+	if total, done := idx.SubtaskCounts(1); total != 5 || done != 1 {
+		t.Errorf("SubtaskCounts(1) = %d/%d, want 1/5", done, total)
+	}
+
+	// The in-progress epic wins over the critical standalone task; within it
+	// the in-progress story B wins over story A despite its critical step
+	if next := idx.NextTodo(); next == nil || next.ID != 6 {
+		t.Errorf("NextTodo() = %v, want task 6", next)
+	}
+}
+
 func TestIndex_HasSubtasks(t *testing.T) {
 	dir := t.TempDir()
 	storage := NewMarkdownStorage(dir)
@@ -777,6 +886,15 @@ func TestIndex_SubtaskCounts(t *testing.T) {
 	if done != 2 {
 		t.Errorf("done = %d, want 2", done)
 	}
+
+	// Counts follow changes made after the subtask map was built
+	sub2.Status = task.StatusDone
+	idx.Set(sub2)
+	idx.Set(&task.Task{ID: 5, ParentID: &sub2.ID, Title: "Nested", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "feature"})
+	idx.Delete(sub3.ID)
+	if total, done := idx.SubtaskCounts(1); total != 3 || done != 2 {
+		t.Errorf("SubtaskCounts(1) after changes = %d/%d, want 2/3", done, total)
+	}
 }
 
 func TestIndex_NextTodo_SkipsParentsWithSubtasks(t *testing.T) {
@@ -1470,6 +1588,52 @@ func TestMarkdownStorage_SaveLoad_WithoutRelations(t *testing.T) {
 	}
 }
 
+func TestMarkdownStorage_SaveLoad_WithTagsAndExternalID(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	tsk := &task.Task{
+		ID:         2,
+		Title:      "Imported task",
+		Status:     task.StatusTodo,
+		Priority:   task.PriorityLow,
+		Type:       "bug",
+		Tags:       []string{"auth", "backend"},
+		ExternalID: "jira:PROJ-2",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := storage.Save(tsk); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := storage.Load(2)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if strings.Join(loaded.Tags, ",") != "auth,backend" {
+		t.Errorf("Tags = %v, want [auth backend]", loaded.Tags)
+	}
+	if loaded.ExternalID != "jira:PROJ-2" {
+		t.Errorf("ExternalID = %q, want %q", loaded.ExternalID, "jira:PROJ-2")
+	}
+
+	// The index keeps both fields so lookups do not need to load files
+	idx := NewIndex(dir, storage)
+	if err := idx.Rebuild(); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	entry, ok := idx.GetEntry(2)
+	if !ok {
+		t.Fatal("GetEntry() returned false")
+	}
+	if entry.ExternalID != "jira:PROJ-2" || len(entry.Tags) != 2 {
+		t.Errorf("entry = %+v, want tags and external ID", entry)
+	}
+}
+
 func TestIndex_AddRemoveRelation(t *testing.T) {
 	dir := t.TempDir()
 	storage := NewMarkdownStorage(dir)
@@ -1556,6 +1720,64 @@ func TestIndex_GetBlockers(t *testing.T) {
 	}
 }
 
+func TestIndex_SetRelationTypes(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+	idx := NewIndex(dir, storage)
+	idx.SetRelationTypes([]config.RelationType{
+		{Name: "waits_for", Blocking: true},
+		{Name: "pairs_with", Symmetric: true},
+		{Name: "blocked_by"}, // Configured without blocking semantics
+	})
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		idx.Set(&task.Task{ID: i, Title: "Task", Status: task.StatusTodo, Priority: task.PriorityMedium, Type: "feature", CreatedAt: now, UpdatedAt: now})
+	}
+	idx.AddRelation(task.RelationEdge{Type: "waits_for", Source: 1, Target: 2})
+	idx.AddRelation(task.RelationEdge{Type: "blocked_by", Source: 2, Target: 3})
+	idx.AddRelation(task.RelationEdge{Type: "pairs_with", Source: 1, Target: 3})
+
+	if blockers := idx.GetBlockers(1); len(blockers) != 1 || blockers[0] != 2 {
+		t.Errorf("GetBlockers(1) = %v, want [2]", blockers)
+	}
+	if blockers := idx.GetBlockers(2); len(blockers) != 0 {
+		t.Errorf("GetBlockers(2) = %v, want none for non-blocking blocked_by", blockers)
+	}
+	if next := idx.NextTodo(); next == nil || next.ID != 2 {
+		t.Errorf("NextTodo() = %v, want task 2", next)
+	}
+
+	// Symmetric types generate the reverse edge
+	found := false
+	for _, e := range idx.GetRelationsForTask(3) {
+		if e.Type == "pairs_with" && e.Source == 3 && e.Target == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected reverse pairs_with edge from 3 to 1")
+	}
+}
+
+func TestIndex_NextTodo_SkipsDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+	idx := NewIndex(dir, storage)
+
+	now := time.Now()
+	idx.Set(&task.Task{ID: 1, Title: "Duplicate", Status: task.StatusTodo, Priority: task.PriorityCritical, Type: "bug", CreatedAt: now, UpdatedAt: now})
+	idx.Set(&task.Task{ID: 2, Title: "Canonical", Status: task.StatusTodo, Priority: task.PriorityLow, Type: "bug", CreatedAt: now, UpdatedAt: now})
+	idx.AddRelation(task.RelationEdge{Type: "duplicate_of", Source: 1, Target: 2})
+
+	if next := idx.NextTodo(); next == nil || next.ID != 2 {
+		t.Errorf("NextTodo() = %v, want canonical task 2", next)
+	}
+	if id, ok := idx.DuplicateOf(1); !ok || id != 2 {
+		t.Errorf("DuplicateOf(1) = %d, %v, want 2", id, ok)
+	}
+}
+
 func TestIndex_RemoveAllRelationsForTask(t *testing.T) {
 	dir := t.TempDir()
 	storage := NewMarkdownStorage(dir)
@@ -2061,3 +2283,208 @@ func TestMarkdownStorage_IsArchived(t *testing.T) {
 		t.Error("IsArchived(999) = true for non-existent task, want false")
 	}
 }
+
+func TestMarkdownStorage_Unarchive(t *testing.T) {
+	dir := t.TempDir()
+	s := NewMarkdownStorage(dir)
+
+	if err := s.Save(makeTestTask(1)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := s.Archive(1); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if err := s.Unarchive(1); err != nil {
+		t.Fatalf("Unarchive() error = %v", err)
+	}
+	if s.IsArchived(1) {
+		t.Error("IsArchived(1) = true after unarchiving, want false")
+	}
+	if _, err := s.Load(1); err != nil {
+		t.Errorf("Load() after unarchiving error = %v", err)
+	}
+	if err := s.Unarchive(1); err == nil {
+		t.Error("Unarchive() of a task that is not archived should return error")
+	}
+}
+
+func TestArchiveIndex(t *testing.T) {
+	dir := t.TempDir()
+	s := NewMarkdownStorage(dir)
+	parentID := 1
+	for id := 1; id <= 3; id++ {
+		tk := makeTestTask(id)
+		if id == 3 {
+			tk.Type = "bug"
+			tk.ParentID = &parentID
+		}
+		if err := s.Save(tk); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	a := NewArchiveIndex(s)
+	if err := a.Archive(1); err != nil {
+		t.Fatalf("Archive(1) error = %v", err)
+	}
+	if err := a.Archive(3); err != nil {
+		t.Fatalf("Archive(3) error = %v", err)
+	}
+	// As recorded by earlier versions, which removed relations to archived tasks
+	stripped := []task.RelationEdge{{Type: "blocked_by", Source: 2, Target: 1}}
+	a.entries[1].Stripped = stripped
+	if err := a.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "archive", ".index.json")); err != nil {
+		t.Fatalf("archive index not written: %v", err)
+	}
+	if got := a.NextID(); got != 4 {
+		t.Errorf("NextID() = %d, want 4", got)
+	}
+
+	// A fresh index reads the file, keeping the stripped relations
+	a = NewArchiveIndex(s)
+	bug := "bug"
+	got, err := a.FilterArchived(nil, nil, &bug, nil)
+	if err != nil {
+		t.Fatalf("FilterArchived() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("FilterArchived(type=bug) = %v, want [#3]", got)
+	}
+	topLevel := 0
+	if got, _ := a.FilterArchived(nil, nil, nil, &topLevel); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("FilterArchived(parent=0) = %v, want [#1]", got)
+	}
+
+	edges, err := a.Unarchive(1)
+	if err != nil {
+		t.Fatalf("Unarchive() error = %v", err)
+	}
+	if len(edges) != 1 || edges[0] != stripped[0] {
+		t.Errorf("Unarchive() = %v, want %v", edges, stripped)
+	}
+	if a.IsArchived(1) || !a.IsArchived(3) {
+		t.Error("IsArchived() wrong after Unarchive(1)")
+	}
+	if _, err := s.Load(1); err != nil {
+		t.Errorf("Load(1) after Unarchive() error = %v", err)
+	}
+}
+
+func TestArchiveIndex_RebuildsWhenStale(t *testing.T) {
+	dir := t.TempDir()
+	s := NewMarkdownStorage(dir)
+	for id := 1; id <= 2; id++ {
+		if err := s.Save(makeTestTask(id)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	a := NewArchiveIndex(s)
+	if err := a.Archive(1); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+
+	// Archived behind the index's back, e.g. by an older version
+	time.Sleep(10 * time.Millisecond)
+	if err := s.Archive(2); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	a = NewArchiveIndex(s)
+	got, err := a.FilterArchived(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("FilterArchived() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("FilterArchived() = %d tasks, want 2", len(got))
+	}
+}
+
+func TestArchiveIndex_SeesOtherProcesses(t *testing.T) {
+	dir := t.TempDir()
+	s := NewMarkdownStorage(dir)
+	for id := 1; id <= 2; id++ {
+		if err := s.Save(makeTestTask(id)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	a := NewArchiveIndex(s)
+	if got := a.NextID(); got != 1 {
+		t.Fatalf("NextID() = %d, want 1", got)
+	}
+
+	// Another process archives task 2 after a has loaded its entries
+	other := NewArchiveIndex(NewMarkdownStorage(dir))
+	if err := other.Archive(2); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if !a.IsArchived(2) {
+		t.Error("IsArchived(2) = false after another process archived it")
+	}
+	if got := a.NextID(); got != 3 {
+		t.Errorf("NextID() = %d, want 3", got)
+	}
+}
+
+func TestMarkdownStorage_SaveLoad_WithResolutionAndHistory(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMarkdownStorage(dir)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	tsk := &task.Task{
+		ID:         3,
+		Title:      "Dropped idea",
+		Status:     task.StatusDone,
+		Priority:   task.PriorityLow,
+		Type:       "feature",
+		Resolution: task.ResolutionCancelled,
+		History: []task.StatusChange{
+			{Action: "cancel", From: task.StatusTodo, To: task.StatusDone, Reason: "out of scope", At: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := storage.Save(tsk); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := storage.Load(3)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Resolution != task.ResolutionCancelled {
+		t.Errorf("Resolution = %q, want %q", loaded.Resolution, task.ResolutionCancelled)
+	}
+	if len(loaded.History) != 1 || loaded.History[0].Reason != "out of scope" || !loaded.History[0].At.Equal(now) {
+		t.Errorf("History = %+v, want the cancel entry", loaded.History)
+	}
+}
+
+func TestDirSignature(t *testing.T) {
+	dir := t.TempDir()
+	empty := DirSignature(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "001.md"), []byte("---\nid: 1\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withTask := DirSignature(dir)
+	if withTask == empty {
+		t.Error("signature should change when a task file is added")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if DirSignature(dir) != withTask {
+		t.Error("signature should ignore files other than tasks and the index")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "001.md"), []byte("---\nid: 1\nstatus: done\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if DirSignature(dir) == withTask {
+		t.Error("signature should change when a task file is modified")
+	}
+}
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// UnarchiveResult describes tasks brought back from the archive
+type UnarchiveResult struct {
+	Tasks    []*Task        `json:"tasks"`                        // The task and its subtasks, parents first
+	Restored []RelationEdge `json:"restored_relations,omitempty"` // Relations earlier versions removed from other tasks when archiving
+	Dropped  []RelationEdge `json:"dropped_relations,omitempty"`  // Relations with deleted tasks, or to restore on archived ones
+}
+
+// RelationRef is a relation of a task, marking targets that are archived
+type RelationRef struct {
+	Type     string `json:"type"`
+	Task     int    `json:"task"`
+	Project  string `json:"project,omitempty"`  // Workspace project of a target in another project
+	Archived bool   `json:"archived,omitempty"` // Archived targets count as done
+}
+
+// Ref returns the target as a reference: #42, or api#42 in another project
+func (r RelationRef) Ref() string {
+	return Relation{Task: r.Task, Project: r.Project}.Ref()
+}
+
+// RelationRefs returns the relations of t, marking those to archived tasks
+func (s *Service) RelationRefs(t *Task) []RelationRef {
+	var refs []RelationRef
+	for _, rel := range t.Relations {
+		ref := RelationRef{Type: rel.Type, Task: rel.Task, Project: rel.Project}
+		if rel.Project != "" {
+			if other, err := s.projectService(rel.Project); err == nil && other.archiveStorage != nil {
+				ref.Archived = other.archiveStorage.IsArchived(rel.Task)
+			}
+		} else if s.archiveStorage != nil {
+			ref.Archived = s.archiveStorage.IsArchived(rel.Task)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// GetArchived returns an archived task and its archived subtasks at all
+// levels, each parent before its own subtasks. Subtasks do not include
+// descriptions.
+func (s *Service) GetArchived(id int) (*Task, []*Task, error) {
+	if s.archiveStorage == nil || !s.archiveStorage.IsArchived(id) {
+		return nil, nil, fmt.Errorf("archived task not found: %d", id)
+	}
+	t, err := s.archiveStorage.LoadArchived(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	subtasks, err := s.archivedDescendants(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, subtasks, nil
+}
+
+// archivedDescendants returns the archived tasks below id, parents first
+func (s *Service) archivedDescendants(id int) ([]*Task, error) {
+	var result []*Task
+	queue := []int{id}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+		subtasks, err := s.archiveStorage.FilterArchived(nil, nil, nil, &parentID)
+		if err != nil {
+			return nil, err
+		}
+		SortByRank(subtasks)
+		for _, sub := range subtasks {
+			result = append(result, sub)
+			queue = append(queue, sub.ID)
+		}
+	}
+	return result, nil
+}
+
+// UnarchiveTask moves an archived task and its archived subtasks back to the
+// active tasks, together with their relations. Relations to tasks deleted
+// since are dropped, and a task whose parent was deleted meanwhile becomes a
+// top-level task. Relations earlier versions removed from other tasks when
+// archiving are restored.
+func (s *Service) UnarchiveTask(id int) (*UnarchiveResult, error) {
+	if s.archiveStorage == nil {
+		return nil, fmt.Errorf("archive storage not available")
+	}
+	if !s.archiveStorage.IsArchived(id) {
+		if _, ok := s.index.Get(id); ok {
+			return nil, fmt.Errorf("task %d is not archived", id)
+		}
+		return nil, fmt.Errorf("archived task not found: %d", id)
+	}
+	root, subtasks, err := s.GetArchived(id)
+	if err != nil {
+		return nil, err
+	}
+	if root.ParentID != nil && s.archiveStorage.IsArchived(*root.ParentID) {
+		return nil, fmt.Errorf("task %d is a subtask of archived task %d; unarchive that task instead", id, *root.ParentID)
+	}
+	ids := []int{id}
+	for _, sub := range subtasks {
+		ids = append(ids, sub.ID)
+	}
+	for _, tid := range ids {
+		if _, ok := s.index.Get(tid); ok {
+			return nil, fmt.Errorf("cannot unarchive task %d: ID %d is used by an active task", id, tid)
+		}
+	}
+
+	result := &UnarchiveResult{}
+	var stripped []RelationEdge
+	for _, tid := range ids {
+		edges, err := s.archiveStorage.Unarchive(tid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unarchive task %d: %w", tid, err)
+		}
+		stripped = append(stripped, edges...)
+		t, err := s.storage.Load(tid)
+		if err != nil {
+			return nil, err
+		}
+		s.index.Set(t)
+		result.Tasks = append(result.Tasks, t)
+	}
+
+	// The restored tasks' own relations, kept in their files
+	for _, t := range result.Tasks {
+		changed := false
+		if t.ParentID != nil {
+			if _, ok := s.index.Get(*t.ParentID); !ok {
+				t.ParentID = nil
+				changed = true
+			}
+		}
+		var kept []Relation
+		for _, rel := range t.Relations {
+			if rel.Project != "" {
+				kept = append(kept, rel) // Checked when resolved, like other projects' relations
+				continue
+			}
+			edge := RelationEdge{Type: rel.Type, Source: t.ID, Target: rel.Task}
+			if _, active := s.index.Get(rel.Task); !active && !s.archiveStorage.IsArchived(rel.Task) {
+				result.Dropped = append(result.Dropped, edge)
+				changed = true
+				continue
+			}
+			s.index.AddRelation(edge)
+			kept = append(kept, rel)
+		}
+		if changed {
+			t.Relations = kept
+			t.UpdatedAt = time.Now().UTC()
+			if err := s.storage.Save(t); err != nil {
+				return nil, err
+			}
+			s.index.Set(t)
+		}
+	}
+
+	// Relations earlier versions removed from other tasks when archiving
+	for _, edge := range stripped {
+		source, ok := s.index.Get(edge.Source)
+		if !ok {
+			result.Dropped = append(result.Dropped, edge)
+			continue
+		}
+		if !hasRelation(source, edge.Type, edge.Target) {
+			source.Relations = append(source.Relations, Relation{Type: edge.Type, Task: edge.Target})
+			source.UpdatedAt = time.Now().UTC()
+			if err := s.storage.Save(source); err != nil {
+				return nil, fmt.Errorf("failed to restore relations in task %d: %w", edge.Source, err)
+			}
+			s.index.Set(source)
+		}
+		s.addEdge(edge)
+		result.Restored = append(result.Restored, edge)
+	}
+
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	if parentID := result.Tasks[0].ParentID; parentID != nil {
+		if _, err := s.syncParentStatus(*parentID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// addEdge adds a relation to the index unless it is already there
+func (s *Service) addEdge(edge RelationEdge) {
+	if !slices.Contains(s.index.GetRelationsForTask(edge.Source), edge) {
+		s.index.AddRelation(edge)
+	}
+}
@@ -0,0 +1,232 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+func newArchiveTestService(t *testing.T) *Service {
+	t.Helper()
+	cfg := &config.Config{
+		TaskTypes:     []string{"feature", "bug"},
+		RelationTypes: config.DefaultRelationTypes,
+	}
+	ms := newMockStorage()
+	svc := NewService(ms, newMockArchiveStorage(ms), newMockIndex(), cfg.TaskTypes, cfg)
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return svc
+}
+
+func completeAndArchive(t *testing.T, svc *Service, id int) {
+	t.Helper()
+	if _, err := svc.StartTask(id); err != nil {
+		t.Fatalf("StartTask(%d) error = %v", id, err)
+	}
+	if _, err := svc.CompleteTask(id); err != nil {
+		t.Fatalf("CompleteTask(%d) error = %v", id, err)
+	}
+	if err := svc.ArchiveTask(id); err != nil {
+		t.Fatalf("ArchiveTask(%d) error = %v", id, err)
+	}
+}
+
+func TestService_UnarchiveTask_RestoresSubtasks(t *testing.T) {
+	svc := newArchiveTestService(t)
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityHigh, "feature", parent.ID)
+	other, _ := svc.Create("Other", "", PriorityHigh, "feature", nil)
+	if err := svc.AddRelation(other.ID, "blocked_by", sub.ID); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	completeAndArchive(t, svc, sub.ID)
+	if err := svc.ArchiveTask(parent.ID); err != nil {
+		t.Fatalf("ArchiveTask() error = %v", err)
+	}
+	if blocked, _ := svc.IsBlocked(other.ID); blocked {
+		t.Fatal("an archived blocker should not block")
+	}
+
+	if _, err := svc.UnarchiveTask(sub.ID); err == nil {
+		t.Error("UnarchiveTask(subtask of archived task) should fail")
+	}
+
+	result, err := svc.UnarchiveTask(parent.ID)
+	if err != nil {
+		t.Fatalf("UnarchiveTask() error = %v", err)
+	}
+	if len(result.Tasks) != 2 || result.Tasks[0].ID != parent.ID || result.Tasks[1].ID != sub.ID {
+		t.Fatalf("Tasks = %v, want parent then subtask", result.Tasks)
+	}
+	if len(result.Restored) != 0 || len(result.Dropped) != 0 {
+		t.Errorf("Restored = %v, Dropped = %v, want none", result.Restored, result.Dropped)
+	}
+
+	got, _ := svc.Get(other.ID)
+	if !hasRelation(got, "blocked_by", sub.ID) {
+		t.Errorf("relations of #%d = %v, want blocked_by #%d", other.ID, got.Relations, sub.ID)
+	}
+	if refs := svc.RelationRefs(got); len(refs) != 1 || refs[0].Archived {
+		t.Errorf("RelationRefs() = %v, want the unarchived task not marked", refs)
+	}
+	if _, subtasks, _ := svc.GetWithSubtasks(parent.ID); len(subtasks) != 1 {
+		t.Errorf("subtasks after unarchive = %d, want 1", len(subtasks))
+	}
+	if archived, _ := svc.ListArchived(nil, nil, nil, nil); len(archived) != 0 {
+		t.Errorf("archived after unarchive = %d, want 0", len(archived))
+	}
+	if _, err := svc.UnarchiveTask(parent.ID); err == nil {
+		t.Error("UnarchiveTask(active task) should fail")
+	}
+}
+
+func TestService_UnarchiveTask_DropsRelationsOfDeletedTasks(t *testing.T) {
+	svc := newArchiveTestService(t)
+	done, _ := svc.Create("Done", "", PriorityHigh, "feature", nil)
+	kept, _ := svc.Create("Kept", "", PriorityHigh, "feature", nil)
+	gone, _ := svc.Create("Gone", "", PriorityHigh, "feature", nil)
+	svc.AddRelation(done.ID, "blocked_by", kept.ID)
+	svc.AddRelation(done.ID, "blocked_by", gone.ID)
+	for _, blocker := range []int{kept.ID, gone.ID} {
+		svc.StartTask(blocker)
+		svc.CompleteTask(blocker)
+	}
+	completeAndArchive(t, svc, done.ID)
+	if err := svc.Delete(gone.ID, false); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	result, err := svc.UnarchiveTask(done.ID)
+	if err != nil {
+		t.Fatalf("UnarchiveTask() error = %v", err)
+	}
+	want := RelationEdge{Type: "blocked_by", Source: done.ID, Target: gone.ID}
+	if len(result.Dropped) != 1 || result.Dropped[0] != want {
+		t.Errorf("Dropped = %v, want [%v]", result.Dropped, want)
+	}
+	got, _ := svc.Get(done.ID)
+	if len(got.Relations) != 1 || !hasRelation(got, "blocked_by", kept.ID) {
+		t.Errorf("relations = %v, want blocked_by #%d", got.Relations, kept.ID)
+	}
+	if edges := svc.index.GetRelationsForTask(kept.ID); len(edges) == 0 {
+		t.Error("relation to the kept task should be back in the index")
+	}
+}
+
+func TestService_Delete_KeepsArchivedRelationsUntilUnarchive(t *testing.T) {
+	svc := newArchiveTestService(t)
+	blocker, _ := svc.Create("Blocker", "", PriorityHigh, "feature", nil)
+	done, _ := svc.Create("Done", "", PriorityHigh, "feature", nil)
+	svc.AddRelation(done.ID, "blocked_by", blocker.ID)
+	svc.StartTask(blocker.ID)
+	svc.CompleteTask(blocker.ID)
+	completeAndArchive(t, svc, done.ID)
+
+	if err := svc.Delete(blocker.ID, false); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	// The archived file is left as it was
+	archived, err := svc.archiveStorage.LoadArchived(done.ID)
+	if err != nil {
+		t.Fatalf("LoadArchived() error = %v", err)
+	}
+	if !hasRelation(archived, "blocked_by", blocker.ID) {
+		t.Errorf("archived relations = %v, want blocked_by #%d kept", archived.Relations, blocker.ID)
+	}
+
+	result, err := svc.UnarchiveTask(done.ID)
+	if err != nil {
+		t.Fatalf("UnarchiveTask() error = %v", err)
+	}
+	if len(result.Dropped) != 1 || result.Dropped[0].Target != blocker.ID {
+		t.Errorf("Dropped = %v, want the relation to the deleted task", result.Dropped)
+	}
+	if got, _ := svc.Get(done.ID); len(got.Relations) != 0 {
+		t.Errorf("relations after unarchive = %v, want none", got.Relations)
+	}
+}
+
+func TestService_UnarchiveTask_RestoresStrippedRelations(t *testing.T) {
+	cfg := &config.Config{
+		TaskTypes:     []string{"feature", "bug"},
+		RelationTypes: config.DefaultRelationTypes,
+	}
+	ms := newMockStorage()
+	as := newMockArchiveStorage(ms)
+	svc := NewService(ms, as, newMockIndex(), cfg.TaskTypes, cfg)
+	svc.Initialize()
+	done, _ := svc.Create("Done", "", PriorityHigh, "feature", nil)
+	other, _ := svc.Create("Other", "", PriorityHigh, "feature", nil)
+	completeAndArchive(t, svc, done.ID)
+
+	// Earlier versions removed relations to archived tasks and recorded them
+	edge := RelationEdge{Type: "blocked_by", Source: other.ID, Target: done.ID}
+	as.stripped[done.ID] = []RelationEdge{edge}
+
+	result, err := svc.UnarchiveTask(done.ID)
+	if err != nil {
+		t.Fatalf("UnarchiveTask() error = %v", err)
+	}
+	if len(result.Restored) != 1 || result.Restored[0] != edge {
+		t.Errorf("Restored = %v, want [%v]", result.Restored, edge)
+	}
+	if got, _ := svc.Get(other.ID); !hasRelation(got, "blocked_by", done.ID) {
+		t.Errorf("relations of #%d = %v, want blocked_by #%d", other.ID, got.Relations, done.ID)
+	}
+}
+
+func TestService_GetArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityHigh, "feature", parent.ID)
+	completeAndArchive(t, svc, sub.ID)
+	svc.ArchiveTask(parent.ID)
+
+	got, subtasks, err := svc.GetArchived(parent.ID)
+	if err != nil {
+		t.Fatalf("GetArchived() error = %v", err)
+	}
+	if got.ID != parent.ID || len(subtasks) != 1 || subtasks[0].ID != sub.ID {
+		t.Errorf("GetArchived() = #%d with %v, want #%d with subtask #%d", got.ID, subtasks, parent.ID, sub.ID)
+	}
+	if _, _, err := svc.GetArchived(999); err == nil {
+		t.Error("GetArchived(unknown) should fail")
+	}
+}
+
+func TestService_ListArchived_Filters(t *testing.T) {
+	svc := newArchiveTestService(t)
+	feature, _ := svc.Create("Feature", "", PriorityHigh, "feature", nil)
+	bug, _ := svc.Create("Bug", "", PriorityLow, "bug", nil)
+	completeAndArchive(t, svc, feature.ID)
+	completeAndArchive(t, svc, bug.ID)
+
+	taskType := "bug"
+	got, err := svc.ListArchived(nil, nil, &taskType, nil)
+	if err != nil {
+		t.Fatalf("ListArchived() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != bug.ID {
+		t.Errorf("ListArchived(type=bug) = %v, want [#%d]", got, bug.ID)
+	}
+	priority := PriorityHigh
+	if got, _ := svc.ListArchived(nil, &priority, nil, nil); len(got) != 1 || got[0].ID != feature.ID {
+		t.Errorf("ListArchived(priority=high) = %v, want [#%d]", got, feature.ID)
+	}
+}
+
+func TestService_Create_SkipsArchivedIDs(t *testing.T) {
+	svc := newArchiveTestService(t)
+	first, _ := svc.Create("First", "", PriorityHigh, "feature", nil)
+	completeAndArchive(t, svc, first.ID)
+
+	next, err := svc.Create("Next", "", PriorityHigh, "feature", nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if next.ID == first.ID {
+		t.Errorf("Create() reused archived ID %d", first.ID)
+	}
+}
@@ -0,0 +1,200 @@
+package task
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// AutoArchiveEntry is a task an auto-archive run archived, or would archive
+type AutoArchiveEntry struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Subtasks int    `json:"subtasks,omitempty"` // Archived with it
+	Reason   string `json:"reason"`
+}
+
+// AutoArchiveResult reports an auto-archive run
+type AutoArchiveResult struct {
+	DryRun   bool               `json:"dry_run,omitempty"`
+	Archived []AutoArchiveEntry `json:"archived"`
+	Errors   []string           `json:"errors,omitempty"`
+}
+
+// autoArchiveCandidate is a done task that may be archived, with its age
+type autoArchiveCandidate struct {
+	task  *Task
+	since time.Time // Basis timestamp
+	days  int       // Full days since then
+}
+
+// AutoArchive applies the auto_archive policy, whether or not it is enabled.
+// Done tasks older than the after_days of their rule (or the default) are
+// archived; while more than max_active tasks are active, the oldest other
+// done tasks follow, except those whose rule says never. Subtasks are only
+// considered once their parent is done and are archived with it. With
+// dryRun nothing is archived.
+func (s *Service) AutoArchive(dryRun bool) (*AutoArchiveResult, error) {
+	result := &AutoArchiveResult{DryRun: dryRun, Archived: []AutoArchiveEntry{}}
+	if s.config == nil {
+		return result, nil
+	}
+	if s.archiveStorage == nil {
+		return nil, fmt.Errorf("archive storage not available")
+	}
+	policy := s.config.AutoArchive
+	if policy.Basis != "" && policy.Basis != config.AutoArchiveBasisUpdatedAt && policy.Basis != config.AutoArchiveBasisCompletedAt {
+		return nil, fmt.Errorf("invalid auto_archive basis: %s (use %s or %s)", policy.Basis, config.AutoArchiveBasisUpdatedAt, config.AutoArchiveBasisCompletedAt)
+	}
+
+	now := time.Now().UTC()
+	var due, early []autoArchiveCandidate
+	dueReason := make(map[int]string)
+	for _, t := range s.autoArchiveCandidates() {
+		c := autoArchiveCandidate{task: t, since: autoArchiveBasis(t, policy.Basis)}
+		c.days = int(now.Sub(c.since).Hours() / 24)
+		afterDays, rule := policy.AfterDays, "default"
+		if r := policy.RuleFor(t.Type, t.Tags); r != nil {
+			if r.Never {
+				continue
+			}
+			afterDays, rule = r.AfterDays, r.String()
+		}
+		if c.days >= afterDays {
+			due = append(due, c)
+			dueReason[t.ID] = fmt.Sprintf("done for %d days (%s: after %d days)", c.days, rule, afterDays)
+		} else {
+			early = append(early, c)
+		}
+	}
+
+	// Parents first, so subtasks can be skipped once their parent is planned
+	planned := make(map[int]bool)
+	var plan []AutoArchiveEntry
+	add := func(c autoArchiveCandidate, reason string) int {
+		removed := 1
+		for _, sub := range s.Descendants(c.task.ID) {
+			if !s.autoArchiveCovered(sub, planned, false) {
+				removed++
+			}
+		}
+		planned[c.task.ID] = true
+		plan = append(plan, AutoArchiveEntry{ID: c.task.ID, Title: c.task.Title, Subtasks: len(s.Descendants(c.task.ID)), Reason: reason})
+		return removed
+	}
+	sort.SliceStable(due, func(i, j int) bool { return s.Depth(due[i].task.ID) < s.Depth(due[j].task.ID) })
+	active := len(s.index.All())
+	for _, c := range due {
+		if !s.autoArchiveCovered(c.task, planned, false) {
+			active -= add(c, dueReason[c.task.ID])
+		}
+	}
+
+	if policy.MaxActive > 0 {
+		sort.SliceStable(early, func(i, j int) bool { return early[i].since.Before(early[j].since) })
+		for _, c := range early {
+			if active <= policy.MaxActive {
+				break
+			}
+			if !s.autoArchiveCovered(c.task, planned, false) {
+				active -= add(c, fmt.Sprintf("done for %d days, more than %d active tasks", c.days, policy.MaxActive))
+			}
+		}
+	}
+
+	// A task planned for max_active may have taken planned subtasks along
+	for _, entry := range plan {
+		if t, ok := s.index.Get(entry.ID); ok && !s.autoArchiveCovered(t, planned, true) {
+			result.Archived = append(result.Archived, entry)
+		}
+	}
+	sort.Slice(result.Archived, func(i, j int) bool { return result.Archived[i].ID < result.Archived[j].ID })
+	if dryRun {
+		return result, nil
+	}
+
+	var archived []AutoArchiveEntry
+	for _, entry := range result.Archived {
+		if err := s.ArchiveTask(entry.ID); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("task %d: %v", entry.ID, err))
+			continue
+		}
+		archived = append(archived, entry)
+	}
+	if archived == nil {
+		archived = []AutoArchiveEntry{}
+	}
+	result.Archived = archived
+	return result, nil
+}
+
+// autoArchiveCandidates returns the done tasks auto-archiving considers:
+// top-level tasks and subtasks whose parent is done
+func (s *Service) autoArchiveCandidates() []*Task {
+	var candidates []*Task
+	for _, t := range s.index.Filter(&[]Status{StatusDone}[0], nil, nil, nil) {
+		if t.ParentID != nil {
+			parent, ok := s.index.Get(*t.ParentID)
+			if !ok || parent.Status != StatusDone {
+				continue
+			}
+		}
+		candidates = append(candidates, t)
+	}
+	return candidates
+}
+
+// autoArchiveCovered reports whether t or one of its ancestors is planned
+func (s *Service) autoArchiveCovered(t *Task, planned map[int]bool, ancestorsOnly bool) bool {
+	if !ancestorsOnly && planned[t.ID] {
+		return true
+	}
+	for _, id := range s.ancestorIDs(t) {
+		if planned[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// autoArchiveBasis returns the time a done task's age is measured from
+func autoArchiveBasis(t *Task, basis string) time.Time {
+	if basis == config.AutoArchiveBasisCompletedAt && t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	return t.UpdatedAt
+}
+
+// GetAutoArchiveCandidates returns the tasks an auto-archive run would archive
+func (s *Service) GetAutoArchiveCandidates() []*Task {
+	result, err := s.AutoArchive(true)
+	if err != nil {
+		return nil
+	}
+	var candidates []*Task
+	for _, entry := range result.Archived {
+		if t, ok := s.index.Get(entry.ID); ok {
+			candidates = append(candidates, t)
+		}
+	}
+	return candidates
+}
+
+// RunAutoArchive runs AutoArchive if auto_archive is enabled; the result is
+// nil otherwise. Tasks that fail to archive are logged and reported.
+func (s *Service) RunAutoArchive() (*AutoArchiveResult, error) {
+	if s.config == nil || !s.config.AutoArchive.Enabled {
+		return nil, nil
+	}
+	result, err := s.AutoArchive(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range result.Errors {
+		log.Printf("auto-archive: failed to archive %s", e)
+	}
+	return result, nil
+}
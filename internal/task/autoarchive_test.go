@@ -0,0 +1,157 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+func newAutoArchiveTestService(t *testing.T, policy config.AutoArchiveConfig) (*Service, *mockStorage, *mockArchiveStorage) {
+	t.Helper()
+	cfg := &config.Config{
+		TaskTypes:     []string{"feature", "bug", "epic"},
+		RelationTypes: config.DefaultRelationTypes,
+		AutoArchive:   policy,
+	}
+	ms := newMockStorage()
+	as := newMockArchiveStorage(ms)
+	svc := NewService(ms, as, newMockIndex(), cfg.TaskTypes, cfg)
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return svc, ms, as
+}
+
+// doneTask creates a done task last updated and completed the given days ago
+func doneTask(t *testing.T, svc *Service, ms *mockStorage, taskType string, updatedDays, completedDays int, tags ...string) *Task {
+	t.Helper()
+	created, err := svc.Create("Task", "", PriorityMedium, taskType, nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	svc.StartTask(created.ID)
+	if _, err := svc.CompleteTask(created.ID); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	stored := ms.tasks[created.ID]
+	now := time.Now().UTC()
+	completedAt := now.AddDate(0, 0, -completedDays)
+	stored.UpdatedAt = now.AddDate(0, 0, -updatedDays)
+	stored.CompletedAt = &completedAt
+	stored.Tags = tags
+	svc.index.Set(stored)
+	return stored
+}
+
+func archivedIDs(result *AutoArchiveResult) []int {
+	ids := make([]int, len(result.Archived))
+	for i, e := range result.Archived {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestService_AutoArchive_Rules(t *testing.T) {
+	svc, ms, as := newAutoArchiveTestService(t, config.AutoArchiveConfig{
+		AfterDays: 30,
+		Rules: []config.AutoArchiveRule{
+			{Tag: "keep", Never: true},
+			{Type: "bug", AfterDays: 7},
+			{Type: "epic", Never: true},
+		},
+	})
+	bug := doneTask(t, svc, ms, "bug", 10, 10)
+	doneTask(t, svc, ms, "bug", 3, 3)
+	doneTask(t, svc, ms, "epic", 100, 100)
+	doneTask(t, svc, ms, "bug", 10, 10, "keep")
+	doneTask(t, svc, ms, "feature", 10, 10)
+	feature := doneTask(t, svc, ms, "feature", 40, 40)
+
+	// Not enabled, but applied on request
+	result, err := svc.AutoArchive(false)
+	if err != nil {
+		t.Fatalf("AutoArchive() error = %v", err)
+	}
+	if got := archivedIDs(result); len(got) != 2 || got[0] != bug.ID || got[1] != feature.ID {
+		t.Fatalf("archived = %v, want [%d %d]", got, bug.ID, feature.ID)
+	}
+	if want := "done for 10 days (type=bug: after 7 days)"; result.Archived[0].Reason != want {
+		t.Errorf("Reason = %q, want %q", result.Archived[0].Reason, want)
+	}
+	if want := "done for 40 days (default: after 30 days)"; result.Archived[1].Reason != want {
+		t.Errorf("Reason = %q, want %q", result.Archived[1].Reason, want)
+	}
+	if !as.IsArchived(bug.ID) || !as.IsArchived(feature.ID) {
+		t.Error("planned tasks should be archived")
+	}
+}
+
+func TestService_AutoArchive_CompletedAtBasis(t *testing.T) {
+	policy := config.AutoArchiveConfig{AfterDays: 30, Basis: config.AutoArchiveBasisCompletedAt}
+	svc, ms, _ := newAutoArchiveTestService(t, policy)
+	// Completed long ago but edited recently, and the other way round
+	old := doneTask(t, svc, ms, "feature", 1, 40)
+	doneTask(t, svc, ms, "feature", 40, 1)
+
+	result, err := svc.AutoArchive(true)
+	if err != nil {
+		t.Fatalf("AutoArchive() error = %v", err)
+	}
+	if got := archivedIDs(result); len(got) != 1 || got[0] != old.ID {
+		t.Errorf("archived = %v, want [%d]", got, old.ID)
+	}
+
+	svc.config.AutoArchive.Basis = "created_at"
+	if _, err := svc.AutoArchive(true); err == nil {
+		t.Error("AutoArchive() with an invalid basis should fail")
+	}
+}
+
+func TestService_AutoArchive_MaxActive(t *testing.T) {
+	svc, ms, _ := newAutoArchiveTestService(t, config.AutoArchiveConfig{
+		AfterDays: 30,
+		MaxActive: 3,
+		Rules:     []config.AutoArchiveRule{{Type: "epic", Never: true}},
+	})
+	doneTask(t, svc, ms, "epic", 20, 20)
+	oldest := doneTask(t, svc, ms, "feature", 10, 10)
+	older := doneTask(t, svc, ms, "feature", 5, 5)
+	doneTask(t, svc, ms, "feature", 1, 1)
+	svc.Create("Open", "", PriorityMedium, "feature", nil)
+
+	result, err := svc.AutoArchive(true)
+	if err != nil {
+		t.Fatalf("AutoArchive() error = %v", err)
+	}
+	if got := archivedIDs(result); len(got) != 2 || got[0] != oldest.ID || got[1] != older.ID {
+		t.Fatalf("archived = %v, want [%d %d]", got, oldest.ID, older.ID)
+	}
+	if want := "done for 10 days, more than 3 active tasks"; result.Archived[0].Reason != want {
+		t.Errorf("Reason = %q, want %q", result.Archived[0].Reason, want)
+	}
+}
+
+func TestService_AutoArchive_DryRunAndSubtasks(t *testing.T) {
+	svc, ms, as := newAutoArchiveTestService(t, config.AutoArchiveConfig{AfterDays: 30})
+	parent, _ := svc.Create("Parent", "", PriorityMedium, "feature", nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", parent.ID)
+	svc.StartTask(sub.ID)
+	svc.CompleteTask(sub.ID)
+	for _, id := range []int{parent.ID, sub.ID} {
+		stored := ms.tasks[id]
+		stored.UpdatedAt = time.Now().UTC().AddDate(0, 0, -40)
+		svc.index.Set(stored)
+	}
+
+	result, err := svc.AutoArchive(true)
+	if err != nil {
+		t.Fatalf("AutoArchive() error = %v", err)
+	}
+	if !result.DryRun || len(result.Archived) != 1 || result.Archived[0].ID != parent.ID || result.Archived[0].Subtasks != 1 {
+		t.Fatalf("result = %+v, want parent #%d with 1 subtask", result, parent.ID)
+	}
+	if as.IsArchived(parent.ID) {
+		t.Error("a dry run should not archive")
+	}
+}
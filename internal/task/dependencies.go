@@ -0,0 +1,189 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DependencyNode is a task reached while walking blocked_by relations.
+// Depth is the number of blocked_by hops from the starting task.
+type DependencyNode struct {
+	TaskID int    `json:"task_id"`
+	Title  string `json:"title"`
+	Status Status `json:"status"`
+	Depth  int    `json:"depth"`
+}
+
+// DependencyGraph describes the transitive blocked_by relations of a task
+type DependencyGraph struct {
+	TaskID     int              `json:"task_id"`
+	Blockers   []DependencyNode `json:"blockers"`   // Tasks this task waits for, directly or indirectly
+	Dependents []DependencyNode `json:"dependents"` // Tasks waiting for this task, directly or indirectly
+}
+
+// CycleError is returned when a blocked_by relation would create a dependency cycle
+type CycleError struct {
+	Path []int // Task IDs along the cycle; the first and last entries are the same task
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, len(e.Path))
+	for i, id := range e.Path {
+		parts[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("would create a dependency cycle: %s (each task blocked_by the next)", strings.Join(parts, " -> "))
+}
+
+// checkBlockingCycle returns a CycleError if adding "source blocked_by target"
+// would close a cycle, i.e. if target already (transitively) waits for source.
+func (s *Service) checkBlockingCycle(source, target int) error {
+	path := findPath(target, source, s.index.GetBlockers)
+	if path == nil {
+		return nil
+	}
+	return &CycleError{Path: append([]int{source}, path...)}
+}
+
+// findPath returns the shortest path from start to goal following next, or nil
+func findPath(start, goal int, next func(int) []int) []int {
+	prev := map[int]int{start: start}
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == goal {
+			var path []int
+			for id := goal; ; id = prev[id] {
+				path = append([]int{id}, path...)
+				if id == start {
+					return path
+				}
+			}
+		}
+		for _, n := range next(current) {
+			if _, seen := prev[n]; !seen {
+				prev[n] = current
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil
+}
+
+// GetDependencyGraph returns the transitive blockers and dependents of a task
+func (s *Service) GetDependencyGraph(id int) (*DependencyGraph, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	return &DependencyGraph{
+		TaskID:     id,
+		Blockers:   s.walkDependencies(id, s.index.GetBlockers),
+		Dependents: s.walkDependencies(id, s.index.GetDependents),
+	}, nil
+}
+
+// walkDependencies collects all tasks reachable from id via next, breadth first
+func (s *Service) walkDependencies(id int, next func(int) []int) []DependencyNode {
+	depth := map[int]int{id: 0}
+	queue := []int{id}
+	nodes := []DependencyNode{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, n := range next(current) {
+			if _, seen := depth[n]; seen {
+				continue
+			}
+			depth[n] = depth[current] + 1
+			queue = append(queue, n)
+
+			node := DependencyNode{TaskID: n, Depth: depth[n]}
+			if t, err := s.Get(n); err == nil {
+				node.Title = t.Title
+				node.Status = t.Status
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].TaskID < nodes[j].TaskID
+	})
+	return nodes
+}
+
+// CriticalPath returns the longest chain of open (not done) tasks connected
+// by blocked_by relations, ordered from the task to do first to the task that
+// finishes the chain. Ties are broken by lower task IDs.
+func (s *Service) CriticalPath() []DependencyNode {
+	open := make(map[int]*Task)
+	var ids []int
+	for _, t := range s.index.All() {
+		if t.Status != StatusDone {
+			open[t.ID] = t
+			ids = append(ids, t.ID)
+		}
+	}
+	sort.Ints(ids)
+
+	// longest[id] is the length of the longest open blocker chain ending at id;
+	// next[id] is the blocker that chain continues with.
+	longest := make(map[int]int)
+	next := make(map[int]int)
+	onStack := make(map[int]bool)
+	var visit func(id int) int
+	visit = func(id int) int {
+		if l, ok := longest[id]; ok {
+			return l
+		}
+		if onStack[id] {
+			return 0 // Ignore edges closing a pre-existing cycle
+		}
+		onStack[id] = true
+		best, bestBlocker := 1, 0
+		blockers := s.index.GetBlockers(id)
+		sort.Ints(blockers)
+		for _, b := range blockers {
+			if _, ok := open[b]; !ok {
+				continue
+			}
+			if l := visit(b) + 1; l > best {
+				best, bestBlocker = l, b
+			}
+		}
+		onStack[id] = false
+		longest[id] = best
+		if bestBlocker != 0 {
+			next[id] = bestBlocker
+		}
+		return best
+	}
+
+	end, endLength := 0, 0
+	for _, id := range ids {
+		if l := visit(id); l > endLength {
+			end, endLength = id, l
+		}
+	}
+	if endLength < 2 {
+		return []DependencyNode{}
+	}
+
+	// Walk from the end of the chain to its first task, then reverse
+	var path []DependencyNode
+	for id := end; id != 0; id = next[id] {
+		t := open[id]
+		path = append(path, DependencyNode{TaskID: t.ID, Title: t.Title, Status: t.Status})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	for i := range path {
+		path[i].Depth = i
+	}
+	return path
+}
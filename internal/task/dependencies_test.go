@@ -0,0 +1,114 @@
+package task
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// newDependencyTestService creates tasks 1..n and applies "a blocked_by b" edges
+func newDependencyTestService(t *testing.T, n int, edges [][2]int) *Service {
+	t.Helper()
+	cfg := &config.Config{
+		TaskTypes:     []string{"feature", "bug"},
+		RelationTypes: config.DefaultRelationTypes,
+	}
+	svc := NewService(newMockStorage(), nil, newMockIndex(), cfg.TaskTypes, cfg)
+	svc.Initialize()
+	for i := 1; i <= n; i++ {
+		if _, err := svc.Create("Task", "", PriorityMedium, "feature", nil); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	for _, e := range edges {
+		if err := svc.AddRelation(e[0], RelationBlockedBy, e[1]); err != nil {
+			t.Fatalf("AddRelation(%d, %d) error = %v", e[0], e[1], err)
+		}
+	}
+	return svc
+}
+
+func nodeIDs(nodes []DependencyNode) []int {
+	ids := []int{}
+	for _, n := range nodes {
+		ids = append(ids, n.TaskID)
+	}
+	return ids
+}
+
+func TestService_AddRelation_RejectsCycle(t *testing.T) {
+	// 1 blocked_by 2 blocked_by 3
+	svc := newDependencyTestService(t, 3, [][2]int{{1, 2}, {2, 3}})
+
+	err := svc.AddRelation(3, RelationBlockedBy, 1)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("AddRelation() error = %v, want CycleError", err)
+	}
+	if want := []int{3, 1, 2, 3}; !reflect.DeepEqual(cycleErr.Path, want) {
+		t.Errorf("Path = %v, want %v", cycleErr.Path, want)
+	}
+	if !strings.Contains(err.Error(), "3 -> 1 -> 2 -> 3") {
+		t.Errorf("error = %q, want cycle path", err.Error())
+	}
+
+	// Relation must not have been stored
+	if blockers := svc.index.GetBlockers(3); len(blockers) != 0 {
+		t.Errorf("GetBlockers(3) = %v, want none", blockers)
+	}
+
+	// Non-blocking relations may still point backwards
+	if err := svc.AddRelation(3, "relates_to", 1); err != nil {
+		t.Errorf("AddRelation(relates_to) error = %v", err)
+	}
+}
+
+func TestService_GetDependencyGraph(t *testing.T) {
+	// 1 blocked_by 2, 2 blocked_by 3, 4 blocked_by 1
+	svc := newDependencyTestService(t, 4, [][2]int{{1, 2}, {2, 3}, {4, 1}})
+
+	graph, err := svc.GetDependencyGraph(1)
+	if err != nil {
+		t.Fatalf("GetDependencyGraph() error = %v", err)
+	}
+	if got := nodeIDs(graph.Blockers); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("Blockers = %v, want [2 3]", got)
+	}
+	if graph.Blockers[1].Depth != 2 {
+		t.Errorf("Blockers[1].Depth = %d, want 2", graph.Blockers[1].Depth)
+	}
+	if got := nodeIDs(graph.Dependents); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("Dependents = %v, want [4]", got)
+	}
+
+	if _, err := svc.GetDependencyGraph(99); err == nil {
+		t.Error("GetDependencyGraph() should fail for unknown task")
+	}
+}
+
+func TestService_CriticalPath(t *testing.T) {
+	// Chains: 4 -> 3 -> 2 -> 1 (4 blocked_by 3, ...) and 5 -> 1
+	svc := newDependencyTestService(t, 5, [][2]int{{4, 3}, {3, 2}, {2, 1}, {5, 1}})
+
+	if got := nodeIDs(svc.CriticalPath()); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("CriticalPath() = %v, want [1 2 3 4]", got)
+	}
+
+	// Done tasks drop out of the path
+	svc.StartTask(1)
+	if _, err := svc.CompleteTask(1); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	if got := nodeIDs(svc.CriticalPath()); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("CriticalPath() = %v, want [2 3 4]", got)
+	}
+
+	// No chains at all
+	empty := newDependencyTestService(t, 2, nil)
+	if got := empty.CriticalPath(); len(got) != 0 {
+		t.Errorf("CriticalPath() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,306 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DuplicateOptions controls what MarkDuplicate does besides adding the relation
+type DuplicateOptions struct {
+	Close bool // Set the duplicate to done
+	Merge bool // Append the duplicate's description and tags to the canonical task
+}
+
+// DuplicateResult describes the outcome of MarkDuplicate
+type DuplicateResult struct {
+	Duplicate *Task          `json:"duplicate"`
+	Canonical *Task          `json:"canonical"`
+	Repointed []RelationEdge `json:"repointed,omitempty"` // Relations moved from the duplicate to the canonical task
+	Dropped   []RelationEdge `json:"dropped,omitempty"`   // Relations removed because the canonical task already has them
+	Closed    bool           `json:"closed"`
+	Merged    bool           `json:"merged"`
+}
+
+// DefaultDuplicateOptions returns the duplicate options configured in mcp-tasks.yaml
+func (s *Service) DefaultDuplicateOptions() DuplicateOptions {
+	if s.config == nil {
+		return DuplicateOptions{}
+	}
+	return DuplicateOptions{Close: s.config.Duplicates.AutoClose, Merge: s.config.Duplicates.Merge}
+}
+
+// DuplicateOf returns the task that id is marked as a duplicate of
+func (s *Service) DuplicateOf(id int) (int, bool) {
+	return s.index.DuplicateOf(id)
+}
+
+// WithoutDuplicates returns tasks minus those marked as duplicates
+func (s *Service) WithoutDuplicates(tasks []*Task) []*Task {
+	result := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		if _, dup := s.index.DuplicateOf(t.ID); !dup {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// duplicateRelationType returns the configured relation type marking duplicates
+func (s *Service) duplicateRelationType() (string, error) {
+	if s.config == nil {
+		return "duplicate_of", nil
+	}
+	name := s.config.DuplicateRelationType()
+	if name == "" {
+		return "", fmt.Errorf("no relation type is configured as duplicate")
+	}
+	return name, nil
+}
+
+// MarkDuplicate marks task id as a duplicate of canonical. Relations other
+// tasks have to the duplicate are re-pointed to the canonical task, so
+// nothing keeps waiting on a task that will never be worked on.
+func (s *Service) MarkDuplicate(id, canonical int, opts DuplicateOptions) (*DuplicateResult, error) {
+	relType, err := s.duplicateRelationType()
+	if err != nil {
+		return nil, err
+	}
+	if other, dup := s.index.DuplicateOf(canonical); dup {
+		return nil, fmt.Errorf("task %d is itself a duplicate of task %d; use that task as canonical", canonical, other)
+	}
+	if err := s.AddRelation(id, relType, canonical); err != nil {
+		return nil, err
+	}
+
+	// Relations other tasks have to the duplicate
+	result := &DuplicateResult{}
+	plan := s.newRelationPlan()
+	plan.repoint(id, canonical, result)
+	if err := plan.apply(time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	dup, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	canon, err := s.Get(canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if opts.Merge {
+		mergeContent(canon, dup)
+		canon.UpdatedAt = now
+		if err := s.storage.Save(canon); err != nil {
+			return nil, err
+		}
+		s.index.Set(canon)
+		result.Merged = true
+	}
+
+	if opts.Close && dup.Status != StatusDone {
+		if err := s.recordTransition(dup, StatusDone, "", "duplicate", fmt.Sprintf("duplicate of #%d", canonical)); err != nil {
+			return nil, err
+		}
+		if dup.ParentID != nil {
+			if _, err := s.syncParentStatus(*dup.ParentID); err != nil {
+				return nil, err
+			}
+		}
+		result.Closed = true
+	}
+
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	result.Duplicate = dup
+	result.Canonical = canon
+	return result, nil
+}
+
+// relationPlan collects relation changes on copies of the affected tasks, so
+// they are all validated against the planned state before anything is written
+type relationPlan struct {
+	s        *Service
+	tasks    map[int]*Task      // Working copies of active tasks
+	original map[int][]Relation // Relations of each copy before the plan
+	changed  map[int]bool       // Copies to write
+	deleted  map[int]bool       // Tasks removed with the plan
+}
+
+func (s *Service) newRelationPlan() *relationPlan {
+	return &relationPlan{
+		s:        s,
+		tasks:    make(map[int]*Task),
+		original: make(map[int][]Relation),
+		changed:  make(map[int]bool),
+		deleted:  make(map[int]bool),
+	}
+}
+
+// task returns the working copy of an active task
+func (p *relationPlan) task(id int) (*Task, error) {
+	if t, ok := p.tasks[id]; ok {
+		return t, nil
+	}
+	loaded, err := p.s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	t := *loaded
+	t.Relations = slices.Clone(loaded.Relations)
+	t.Tags = slices.Clone(loaded.Tags)
+	t.History = slices.Clone(loaded.History)
+	p.tasks[id] = &t
+	p.original[id] = loaded.Relations
+	return &t, nil
+}
+
+// lookup returns a task as planned, falling back to the archive like Get
+func (p *relationPlan) lookup(id int) (*Task, error) {
+	if t, ok := p.tasks[id]; ok {
+		return t, nil
+	}
+	return p.s.Get(id)
+}
+
+// blockers returns the tasks id waits for once the plan is applied
+func (p *relationPlan) blockers(id int) []int {
+	t, ok := p.tasks[id]
+	if !ok {
+		return p.s.index.GetBlockers(id)
+	}
+	var ids []int
+	for _, rel := range t.Relations {
+		if rel.Project == "" && !p.deleted[rel.Task] && p.s.isBlockingType(rel.Type) {
+			ids = append(ids, rel.Task)
+		}
+	}
+	return ids
+}
+
+// checkAdd validates a new relation of a canonical type between two tasks of
+// the project against the planned state
+func (p *relationPlan) checkAdd(source int, relationType string, target int) error {
+	if source == target {
+		return fmt.Errorf("cannot create relation: source and target are the same task (%d)", source)
+	}
+	if p.deleted[target] {
+		return fmt.Errorf("target task not found: %d", target)
+	}
+	rt, _, _, err := p.s.resolveRelation(source, relationType, target)
+	if err != nil {
+		return err
+	}
+	src, err := p.task(source)
+	if err != nil {
+		return err
+	}
+	tgt, err := p.lookup(target)
+	if err != nil {
+		return fmt.Errorf("target task not found: %d", target)
+	}
+	if !isAllowedTaskType(rt.SourceTypes, src.Type) || !isAllowedTaskType(rt.TargetTypes, tgt.Type) {
+		return fmt.Errorf("cannot create relation: %s is not allowed from %s task %d to %s task %d", relationType, src.Type, source, tgt.Type, target)
+	}
+	if hasRelation(src, relationType, target) || rt.Symmetric && hasRelation(tgt, relationType, source) {
+		return fmt.Errorf("relation already exists: %s from %d to %d", relationType, source, target)
+	}
+	if rt.Blocking {
+		if path := findPath(target, source, p.blockers); path != nil {
+			return &CycleError{Path: append([]int{source}, path...)}
+		}
+	}
+	return nil
+}
+
+// repoint moves relations other tasks have to from so they point at to,
+// unless the moved relation would be redundant or invalid
+func (p *relationPlan) repoint(from, to int, result *DuplicateResult) {
+	for _, e := range p.s.index.GetRelationsForTask(from) {
+		if e.Target != from || e.Source == from || p.deleted[e.Source] {
+			continue
+		}
+		src, err := p.task(e.Source)
+		if err != nil || !hasRelation(src, e.Type, from) {
+			continue // Reverse edge of a relation stored on from itself
+		}
+
+		keep := p.checkAdd(e.Source, e.Type, to) == nil
+		var relations []Relation
+		for _, rel := range src.Relations {
+			if rel.Type == e.Type && rel.Task == from && rel.Project == "" {
+				if keep {
+					relations = append(relations, Relation{Type: e.Type, Task: to})
+				}
+				continue
+			}
+			relations = append(relations, rel)
+		}
+		src.Relations = relations
+		p.changed[src.ID] = true
+		if keep {
+			result.Repointed = append(result.Repointed, RelationEdge{Type: e.Type, Source: e.Source, Target: to})
+		} else {
+			result.Dropped = append(result.Dropped, e)
+		}
+	}
+}
+
+// apply writes the changed tasks, updates the relation edges in the index,
+// deletes the tasks removed with the plan and saves the index once
+func (p *relationPlan) apply(now time.Time) error {
+	var changed, deleted []int
+	for id := range p.changed {
+		if !p.deleted[id] {
+			changed = append(changed, id)
+		}
+	}
+	for id := range p.deleted {
+		deleted = append(deleted, id)
+	}
+	slices.Sort(changed)
+	slices.Sort(deleted)
+
+	for _, id := range changed {
+		t := p.tasks[id]
+		t.UpdatedAt = now
+		if err := p.s.storage.Save(t); err != nil {
+			// Keep the index consistent with the files written so far
+			_ = p.s.index.Save()
+			return fmt.Errorf("failed to save task %d: %w", id, err)
+		}
+		p.s.index.Set(t)
+		for _, rel := range p.original[id] {
+			if rel.Project == "" && !slices.Contains(t.Relations, rel) {
+				p.s.index.RemoveRelation(RelationEdge{Type: rel.Type, Source: id, Target: rel.Task})
+			}
+		}
+		for _, rel := range t.Relations {
+			if rel.Project == "" && !slices.Contains(p.original[id], rel) {
+				p.s.index.AddRelation(RelationEdge{Type: rel.Type, Source: id, Target: rel.Task})
+			}
+		}
+	}
+	for _, id := range deleted {
+		if err := p.s.storage.Delete(id); err != nil {
+			_ = p.s.index.Save()
+			return fmt.Errorf("failed to delete task %d: %w", id, err)
+		}
+		p.s.index.RemoveAllRelationsForTask(id)
+		p.s.index.Delete(id)
+	}
+	return p.s.index.Save()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestService_MarkDuplicate(t *testing.T) {
+	// 3 blocked_by 1, 4 relates_to 1, 2 blocked_by 1
+	svc := newDependencyTestService(t, 4, [][2]int{{3, 1}, {2, 1}})
+	if err := svc.AddRelation(4, "relates_to", 1); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	desc := "Steps to reproduce"
+	if _, err := svc.Update(1, nil, &desc, nil, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	result, err := svc.MarkDuplicate(1, 2, DuplicateOptions{Close: true, Merge: true})
+	if err != nil {
+		t.Fatalf("MarkDuplicate() error = %v", err)
+	}
+
+	// 3 now waits for the canonical task; 2's own relation to 1 is dropped
+	if blockers := svc.index.GetBlockers(3); len(blockers) != 1 || blockers[0] != 2 {
+		t.Errorf("GetBlockers(3) = %v, want [2]", blockers)
+	}
+	if blockers := svc.index.GetBlockers(2); len(blockers) != 0 {
+		t.Errorf("GetBlockers(2) = %v, want none", blockers)
+	}
+	if len(result.Repointed) != 2 || len(result.Dropped) != 1 {
+		t.Errorf("Repointed = %v, Dropped = %v, want 2 and 1", result.Repointed, result.Dropped)
+	}
+
+	canonical, _ := svc.Get(2)
+	if !strings.Contains(canonical.Description, "Merged from #1") || !strings.Contains(canonical.Description, desc) {
+		t.Errorf("canonical.Description = %q, want merged description", canonical.Description)
+	}
+	dup, _ := svc.Get(1)
+	if dup.Status != StatusDone || !result.Closed {
+		t.Errorf("duplicate status = %s, want done", dup.Status)
+	}
+	if id, ok := svc.DuplicateOf(1); !ok || id != 2 {
+		t.Errorf("DuplicateOf(1) = %d, %v, want 2", id, ok)
+	}
+
+	listed := svc.WithoutDuplicates(svc.List(nil, nil, nil, nil))
+	for _, task := range listed {
+		if task.ID == 1 {
+			t.Error("WithoutDuplicates() should hide task 1")
+		}
+	}
+
+	// A duplicate cannot become canonical
+	if _, err := svc.MarkDuplicate(3, 1, DuplicateOptions{}); err == nil {
+		t.Error("MarkDuplicate() should reject a duplicate as canonical")
+	}
+}
+
+func TestService_MarkDuplicate_CloseUpdatesParent(t *testing.T) {
+	svc := newArchiveTestService(t)
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityHigh, "feature", parent.ID)
+	canonical, _ := svc.Create("Canonical", "", PriorityHigh, "feature", nil)
+
+	if _, err := svc.MarkDuplicate(sub.ID, canonical.ID, DuplicateOptions{Close: true}); err != nil {
+		t.Fatalf("MarkDuplicate() error = %v", err)
+	}
+
+	dup, _ := svc.Get(sub.ID)
+	if len(dup.History) != 1 || dup.History[0].Action != "duplicate" || dup.History[0].To != StatusDone {
+		t.Errorf("History = %+v, want a duplicate transition to done", dup.History)
+	}
+	if got, _ := svc.Get(parent.ID); got.Status != StatusDone {
+		t.Errorf("parent status = %s, want done after its only subtask was closed", got.Status)
+	}
+}
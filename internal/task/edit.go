@@ -0,0 +1,115 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ApplyEdit saves a task edited as a whole, e.g. in a text editor. Title,
+// description, status, priority, type, tags, external ID and relations are
+// taken from edited and validated like Update and AddRelation; the ID and
+// parent cannot change, and timestamps, order and history are kept. Nothing
+// is written unless every change is valid. Returns the saved task and
+// whether anything changed.
+func (s *Service) ApplyEdit(id int, edited *Task) (*Task, bool, error) {
+	original, ok := s.index.Get(id)
+	if !ok {
+		return nil, false, fmt.Errorf("task not found: %d", id)
+	}
+	if edited.ID != id {
+		return nil, false, fmt.Errorf("the task ID cannot be changed (was %d, got %d)", id, edited.ID)
+	}
+	if !sameParent(original.ParentID, edited.ParentID) {
+		return nil, false, fmt.Errorf("the parent cannot be changed here; use reparent instead")
+	}
+
+	t := *original
+	if err := s.applyChanges(&t, &edited.Title, &edited.Description, nil, &edited.Priority, &edited.Type); err != nil {
+		return nil, false, err
+	}
+	if edited.Status != original.Status {
+		if !IsValidStatus(string(edited.Status)) {
+			return nil, false, fmt.Errorf("invalid status: %s", edited.Status)
+		}
+		if err := s.checkStatusChange(original, edited.Status, nil); err != nil {
+			return nil, false, err
+		}
+		t.setStatus(edited.Status, time.Now().UTC())
+		t.Resolution = ""
+	}
+	t.Tags = edited.Tags
+	t.ExternalID = edited.ExternalID
+
+	// Relations are changed through AddRelation/RemoveRelation, so check the
+	// new ones before writing anything
+	var added, removed []Relation
+	for _, rel := range edited.Relations {
+		if !slices.Contains(original.Relations, rel) && !slices.Contains(added, rel) {
+			if err := s.checkEditedRelation(id, rel); err != nil {
+				return nil, false, err
+			}
+			added = append(added, rel)
+		}
+	}
+	for _, rel := range original.Relations {
+		if !slices.Contains(edited.Relations, rel) {
+			removed = append(removed, rel)
+		}
+	}
+
+	changed := len(added) > 0 || len(removed) > 0 || t.Title != original.Title ||
+		t.Description != original.Description || t.Status != original.Status ||
+		t.Priority != original.Priority || t.Type != original.Type ||
+		t.ExternalID != original.ExternalID || !slices.Equal(t.Tags, original.Tags)
+	if !changed {
+		return original, false, nil
+	}
+
+	t.UpdatedAt = time.Now().UTC()
+	if err := s.storage.Save(&t); err != nil {
+		return nil, false, err
+	}
+	s.index.Set(&t)
+	for _, rel := range removed {
+		if err := s.RemoveProjectRelation(id, rel.Type, rel.Project, rel.Task); err != nil {
+			return nil, false, err
+		}
+	}
+	for _, rel := range added {
+		if err := s.AddProjectRelation(id, rel.Type, rel.Project, rel.Task); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := s.index.Save(); err != nil {
+		return nil, false, err
+	}
+
+	if t.Status != original.Status && t.ParentID != nil {
+		if _, err := s.syncParentStatus(*t.ParentID); err != nil {
+			return nil, false, err
+		}
+	}
+	saved, err := s.Get(id)
+	if err != nil {
+		return nil, false, err
+	}
+	return saved, true, nil
+}
+
+// checkEditedRelation validates a relation added in an edited task
+func (s *Service) checkEditedRelation(id int, rel Relation) error {
+	if s.isLocalProject(rel.Project) {
+		_, _, _, err := s.checkRelation(id, rel.Type, rel.Task)
+		return err
+	}
+	rt, _, _, err := s.resolveRelation(id, rel.Type, rel.Task)
+	if err != nil {
+		return err
+	}
+	if rt.Name != rel.Type {
+		return fmt.Errorf("cannot create relation: use %s on %s instead of %s", rt.Name, rel.Ref(), rel.Type)
+	}
+	_, err = s.checkProjectRelation(id, rt, rel.Project, rel.Task)
+	return err
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestService_ApplyEdit(t *testing.T) {
+	// 2 blocked_by 1
+	svc := newDependencyTestService(t, 3, [][2]int{{2, 1}})
+
+	loaded, _ := svc.Get(2)
+	edited := *loaded
+	edited.Title = "Renamed"
+	edited.Description = "New plan"
+	edited.Priority = PriorityHigh
+	edited.Tags = []string{"ui"}
+	edited.Relations = []Relation{{Type: "relates_to", Task: 3}}
+
+	saved, changed, err := svc.ApplyEdit(2, &edited)
+	if err != nil {
+		t.Fatalf("ApplyEdit() error = %v", err)
+	}
+	if !changed {
+		t.Error("ApplyEdit() changed = false, want true")
+	}
+	if saved.Title != "Renamed" || saved.Description != "New plan" || saved.Priority != PriorityHigh || len(saved.Tags) != 1 {
+		t.Errorf("saved = %+v, want edited fields", saved)
+	}
+	if blocked, _ := svc.IsBlocked(2); blocked {
+		t.Error("removed blocked_by relation should no longer block")
+	}
+	if len(saved.Relations) != 1 || saved.Relations[0] != (Relation{Type: "relates_to", Task: 3}) {
+		t.Errorf("Relations = %v, want [relates_to 3]", saved.Relations)
+	}
+	if !saved.CreatedAt.Equal(loaded.CreatedAt) {
+		t.Error("CreatedAt should be kept")
+	}
+
+	// Saving the task unchanged is a no-op
+	again := *saved
+	if _, changed, err := svc.ApplyEdit(2, &again); err != nil || changed {
+		t.Errorf("ApplyEdit() unchanged = %v, %v, want false, nil", changed, err)
+	}
+}
+
+func TestService_ApplyEdit_Rejects(t *testing.T) {
+	svc := newDependencyTestService(t, 2, [][2]int{{2, 1}})
+	parent := 1
+
+	tests := []struct {
+		name    string
+		edit    func(e *Task)
+		wantErr string
+	}{
+		{"id", func(e *Task) { e.ID = 5 }, "ID cannot be changed"},
+		{"parent", func(e *Task) { e.ParentID = &parent }, "use reparent"},
+		{"title", func(e *Task) { e.Title = "" }, "title cannot be empty"},
+		{"status", func(e *Task) { e.Status = "blocked" }, "invalid status"},
+		{"priority", func(e *Task) { e.Priority = "urgent" }, "invalid priority"},
+		{"type", func(e *Task) { e.Type = "chore" }, "invalid task type"},
+		{"blocked start", func(e *Task) { e.Status = StatusInProgress }, "blocked by"},
+		{"relation type", func(e *Task) { e.Relations = append(e.Relations, Relation{Type: "owns", Task: 1}) }, "invalid relation type"},
+		{"relation target", func(e *Task) { e.Relations = append(e.Relations, Relation{Type: "relates_to", Task: 99}) }, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, _ := svc.Get(2)
+			edited := *loaded
+			edited.Title = "Changed"
+			tt.edit(&edited)
+			if _, _, err := svc.ApplyEdit(2, &edited); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ApplyEdit() error = %v, want %q", err, tt.wantErr)
+			}
+			if got, _ := svc.Get(2); got.Title == "Changed" {
+				t.Error("rejected edit should not be saved")
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter selects tasks by their metadata. Nil fields match every task.
+// ParentID follows the Index.Filter semantics: 0 = top-level only,
+// >0 = subtasks of that parent.
+type Filter struct {
+	Status   *Status
+	Priority *Priority
+	Type     *string
+	ParentID *int
+}
+
+// ParseFilter parses a filter expression of comma-separated key=value pairs,
+// e.g. "status=todo,priority=low,type=bug,parent=3".
+func ParseFilter(expr string) (*Filter, error) {
+	f := &Filter{}
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("filter expression is empty")
+	}
+	for _, part := range strings.Split(expr, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid filter term %q (expected key=value)", part)
+		}
+		switch key {
+		case "status":
+			if !IsValidStatus(value) {
+				return nil, fmt.Errorf("invalid status: %s", value)
+			}
+			s := Status(value)
+			f.Status = &s
+		case "priority":
+			if !IsValidPriority(value) {
+				return nil, fmt.Errorf("invalid priority: %s", value)
+			}
+			p := Priority(value)
+			f.Priority = &p
+		case "type":
+			v := value
+			f.Type = &v
+		case "parent", "parent_id":
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 0 {
+				return nil, fmt.Errorf("invalid parent ID: %s", value)
+			}
+			f.ParentID = &id
+		default:
+			return nil, fmt.Errorf("unknown filter key: %s", key)
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,39 @@
+package task
+
+import "testing"
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter("status=todo, priority=high,type=bug,parent=3")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if f.Status == nil || *f.Status != StatusTodo {
+		t.Errorf("Status = %v, want todo", f.Status)
+	}
+	if f.Priority == nil || *f.Priority != PriorityHigh {
+		t.Errorf("Priority = %v, want high", f.Priority)
+	}
+	if f.Type == nil || *f.Type != "bug" {
+		t.Errorf("Type = %v, want bug", f.Type)
+	}
+	if f.ParentID == nil || *f.ParentID != 3 {
+		t.Errorf("ParentID = %v, want 3", f.ParentID)
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"status",
+		"status=",
+		"status=blocked",
+		"priority=urgent",
+		"parent=abc",
+		"owner=me",
+	}
+	for _, expr := range tests {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) should fail", expr)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EdgeParent is the graph edge type linking a subtask (source) to its parent (target)
+const EdgeParent = "parent"
+
+// GraphEdge is a directed edge between two tasks in a TaskGraph.
+// Undirected is set for relations that exist in both directions (e.g. relates_to).
+// TargetProject is set for relations to a task in another workspace project.
+type GraphEdge struct {
+	Source        int    `json:"source"`
+	Target        int    `json:"target"`
+	TargetProject string `json:"target_project,omitempty"`
+	Type          string `json:"type"`
+	Undirected    bool   `json:"undirected,omitempty"`
+}
+
+// TaskGraph is a set of tasks and the parent and relation edges between them.
+// Projects holds the tasks in other workspace projects the tasks relate to.
+type TaskGraph struct {
+	Tasks    []*Task        `json:"tasks"`
+	Projects []*ProjectTask `json:"projects,omitempty"`
+	Edges    []GraphEdge    `json:"edges"`
+}
+
+// GraphFilter restricts the tasks and edges included in a TaskGraph
+type GraphFilter struct {
+	RootID    *int     // Only the root, its subtasks and tasks directly related to them
+	Statuses  []Status // Only tasks with one of these statuses (empty = all)
+	EdgeTypes []string // Only these edge types, EdgeParent or relation types (empty = all)
+}
+
+// TaskGraph returns the tasks matching the filter together with the parent
+// links and relations between them
+func (s *Service) TaskGraph(f GraphFilter) (*TaskGraph, error) {
+	for _, st := range f.Statuses {
+		if !IsValidStatus(string(st)) {
+			return nil, fmt.Errorf("invalid status: %s", st)
+		}
+	}
+	wantEdge := make(map[string]bool)
+	for _, et := range f.EdgeTypes {
+		if et != EdgeParent {
+			// Edges are stored under the canonical name of inverse types
+			rt, _, _, err := s.resolveRelation(0, et, 0)
+			if err != nil {
+				return nil, fmt.Errorf("invalid edge type: %s", et)
+			}
+			et = rt.Name
+		}
+		wantEdge[et] = true
+	}
+	edgeAllowed := func(t string) bool {
+		return len(wantEdge) == 0 || wantEdge[t]
+	}
+
+	byID := make(map[int]*Task)
+	for _, t := range s.index.All() {
+		byID[t.ID] = t
+	}
+
+	// Select tasks
+	included := make(map[int]bool)
+	if f.RootID != nil {
+		if _, ok := byID[*f.RootID]; !ok {
+			return nil, fmt.Errorf("task not found: %d", *f.RootID)
+		}
+		var subtree []int
+		var walk func(id int)
+		walk = func(id int) {
+			if included[id] {
+				return
+			}
+			included[id] = true
+			subtree = append(subtree, id)
+			for _, sub := range s.index.GetSubtasks(id) {
+				walk(sub.ID)
+			}
+		}
+		walk(*f.RootID)
+		for _, id := range subtree {
+			for _, e := range s.index.GetRelationsForTask(id) {
+				if edgeAllowed(e.Type) {
+					included[e.Source] = true
+					included[e.Target] = true
+				}
+			}
+		}
+	} else {
+		for id := range byID {
+			included[id] = true
+		}
+	}
+	if len(f.Statuses) > 0 {
+		for id := range included {
+			t, ok := byID[id]
+			if !ok || !containsStatus(f.Statuses, t.Status) {
+				delete(included, id)
+			}
+		}
+	}
+
+	g := &TaskGraph{Tasks: []*Task{}, Edges: []GraphEdge{}}
+	for id := range included {
+		if t, ok := byID[id]; ok {
+			g.Tasks = append(g.Tasks, t)
+		}
+	}
+	sort.Slice(g.Tasks, func(i, j int) bool { return g.Tasks[i].ID < g.Tasks[j].ID })
+
+	// Collect edges between included tasks; a relation present in both
+	// directions is emitted once as an undirected edge
+	emitted := make(map[GraphEdge]int)
+	for _, t := range g.Tasks {
+		if t.ParentID != nil && included[*t.ParentID] && edgeAllowed(EdgeParent) {
+			g.Edges = append(g.Edges, GraphEdge{Source: t.ID, Target: *t.ParentID, Type: EdgeParent})
+		}
+		for _, e := range s.index.GetRelationsForTask(t.ID) {
+			if e.Source != t.ID || !included[e.Target] || !edgeAllowed(e.Type) {
+				continue
+			}
+			reverse := GraphEdge{Source: e.Target, Target: e.Source, Type: e.Type}
+			if i, ok := emitted[reverse]; ok {
+				g.Edges[i].Undirected = true
+				continue
+			}
+			edge := GraphEdge{Source: e.Source, Target: e.Target, Type: e.Type}
+			emitted[edge] = len(g.Edges)
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	// Relations into other projects, with their targets as extra nodes
+	external := make(map[string]bool)
+	for _, t := range g.Tasks {
+		for _, rel := range s.index.ProjectRelations(t.ID) {
+			if !edgeAllowed(rel.Type) {
+				continue
+			}
+			target, err := s.projectTask(rel.Project, rel.Task)
+			if err != nil || (len(f.Statuses) > 0 && !containsStatus(f.Statuses, target.Status)) {
+				continue
+			}
+			if ref := FormatRef(rel.Project, rel.Task); !external[ref] {
+				external[ref] = true
+				node := *target
+				node.Description = ""
+				g.Projects = append(g.Projects, &ProjectTask{Project: rel.Project, Ref: ref, Task: &node})
+			}
+			g.Edges = append(g.Edges, GraphEdge{Source: t.ID, Target: rel.Task, TargetProject: rel.Project, Type: rel.Type})
+		}
+	}
+	return g, nil
+}
+
+func containsStatus(statuses []Status, status Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
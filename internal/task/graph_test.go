@@ -0,0 +1,78 @@
+package task
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func graphEdgeStrings(g *TaskGraph) []string {
+	var edges []string
+	for _, e := range g.Edges {
+		s := e.Type
+		if e.Undirected {
+			s += "~"
+		}
+		edges = append(edges, fmt.Sprintf("%d %s %d", e.Source, s, e.Target))
+	}
+	return edges
+}
+
+func TestService_TaskGraph(t *testing.T) {
+	// Tasks 1-4, subtasks 5 and 6 of 1; 6 blocked_by 5; 1 relates_to 4
+	svc := newDependencyTestService(t, 4, [][2]int{})
+	parent := 1
+	if _, err := svc.Create("Sub A", "", PriorityMedium, "feature", &parent); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := svc.Create("Sub B", "", PriorityMedium, "feature", &parent); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := svc.AddRelation(6, RelationBlockedBy, 5); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	if err := svc.AddRelation(1, "relates_to", 4); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	done := StatusDone
+	if _, err := svc.Update(5, nil, nil, &done, nil, nil, true); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	g, err := svc.TaskGraph(GraphFilter{})
+	if err != nil {
+		t.Fatalf("TaskGraph() error = %v", err)
+	}
+	if len(g.Tasks) != 6 {
+		t.Errorf("len(Tasks) = %d, want 6", len(g.Tasks))
+	}
+	want := []string{"1 relates_to~ 4", "5 parent 1", "6 parent 1", "6 blocked_by 5"}
+	if got := graphEdgeStrings(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges = %v, want %v", got, want)
+	}
+
+	// Root keeps the subtree plus directly related tasks
+	root := 1
+	g, _ = svc.TaskGraph(GraphFilter{RootID: &root})
+	var ids []int
+	for _, task := range g.Tasks {
+		ids = append(ids, task.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 4, 5, 6}) {
+		t.Errorf("root task IDs = %v, want [1 4 5 6]", ids)
+	}
+
+	// Status and edge type filters
+	g, _ = svc.TaskGraph(GraphFilter{Statuses: []Status{StatusTodo}, EdgeTypes: []string{EdgeParent}})
+	if got := graphEdgeStrings(g); !reflect.DeepEqual(got, []string{"6 parent 1"}) {
+		t.Errorf("filtered Edges = %v, want [6 parent 1]", got)
+	}
+
+	if _, err := svc.TaskGraph(GraphFilter{EdgeTypes: []string{"depends"}}); err == nil {
+		t.Error("TaskGraph() should reject unknown edge types")
+	}
+	missing := 99
+	if _, err := svc.TaskGraph(GraphFilter{RootID: &missing}); err == nil {
+		t.Error("TaskGraph() should fail for unknown root")
+	}
+}
@@ -0,0 +1,272 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// MaxDepth returns the configured maximum nesting depth; 0 means unlimited
+func (s *Service) MaxDepth() int {
+	if s.config == nil {
+		return config.DefaultMaxDepth
+	}
+	return s.config.Hierarchy.MaxDepth
+}
+
+// ancestorIDs returns the IDs above a task, from its parent up to the top level
+func (s *Service) ancestorIDs(t *Task) []int {
+	var ids []int
+	seen := map[int]bool{t.ID: true}
+	for parentID := t.ParentID; parentID != nil && !seen[*parentID]; {
+		seen[*parentID] = true
+		ids = append(ids, *parentID)
+		parent, ok := s.index.Get(*parentID)
+		if !ok {
+			break
+		}
+		parentID = parent.ParentID
+	}
+	return ids
+}
+
+// Depth returns the level of a task in the hierarchy; top-level tasks are at depth 1
+func (s *Service) Depth(id int) int {
+	t, ok := s.index.Get(id)
+	if !ok {
+		return 0
+	}
+	return len(s.ancestorIDs(t)) + 1
+}
+
+// Descendants returns all tasks below id, each parent before its subtasks.
+// Tasks do not include descriptions.
+func (s *Service) Descendants(id int) []*Task {
+	var result []*Task
+	seen := map[int]bool{id: true}
+	var walk func(parentID int)
+	walk = func(parentID int) {
+		for _, sub := range s.index.GetSubtasks(parentID) {
+			if seen[sub.ID] {
+				continue
+			}
+			seen[sub.ID] = true
+			result = append(result, sub)
+			walk(sub.ID)
+		}
+	}
+	walk(id)
+	return result
+}
+
+// subtreeHeight returns the number of levels in the subtree rooted at id (1 for a leaf)
+func (s *Service) subtreeHeight(id int) int {
+	height := 1
+	depths := map[int]int{id: 1}
+	for _, t := range s.Descendants(id) {
+		d := depths[*t.ParentID] + 1
+		depths[t.ID] = d
+		height = max(height, d)
+	}
+	return height
+}
+
+// checkDepth verifies that a subtree of the given height fits below parentID
+func (s *Service) checkDepth(parentID, height int) error {
+	limit := s.MaxDepth()
+	if limit > 0 && s.Depth(parentID)+height > limit {
+		return fmt.Errorf("task hierarchy too deep: maximum depth is %d", limit)
+	}
+	return nil
+}
+
+// ReparentResult describes the outcome of SetParent
+type ReparentResult struct {
+	Task           *Task   `json:"task"`
+	OldParentID    *int    `json:"old_parent_id,omitempty"`
+	UpdatedParents []*Task `json:"updated_parents,omitempty"` // Parents whose status changed as a result of the move
+}
+
+// SetParent moves a task, together with its subtasks, under a new parent.
+// A nil parentID promotes the task to top level. The status of the old and
+// new parent is re-evaluated afterwards.
+func (s *Service) SetParent(id int, parentID *int) (*ReparentResult, error) {
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if sameParent(t.ParentID, parentID) {
+		return nil, fmt.Errorf("task %d already has this parent", id)
+	}
+
+	if parentID != nil {
+		if *parentID == id {
+			return nil, fmt.Errorf("task %d cannot be its own parent", id)
+		}
+		parent, err := s.activeTask(*parentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent: %w", err)
+		}
+		for _, ancestor := range s.ancestorIDs(parent) {
+			if ancestor == id {
+				return nil, fmt.Errorf("cannot move task %d under its own subtask %d", id, *parentID)
+			}
+		}
+		if err := s.checkDepth(*parentID, s.subtreeHeight(id)); err != nil {
+			return nil, err
+		}
+	}
+
+	oldParentID := t.ParentID
+	t.ParentID = parentID
+	t.Order = 0 // Rank by ID among the new siblings
+	t.UpdatedAt = time.Now().UTC()
+	if err := s.storage.Save(t); err != nil {
+		return nil, err
+	}
+	s.index.Set(t)
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+
+	result := &ReparentResult{Task: t, OldParentID: oldParentID}
+	for _, pid := range []*int{oldParentID, parentID} {
+		if pid == nil {
+			continue
+		}
+		updated, err := s.syncParentStatus(*pid)
+		if err != nil {
+			return nil, err
+		}
+		result.UpdatedParents = append(result.UpdatedParents, updated...)
+	}
+	return result, nil
+}
+
+// syncParentStatus brings a parent's status in line with its subtasks the
+// way StartTask and CompleteTask do: started once any subtask has started,
+// done once all are done, and back in progress if a done parent gains open
+// work. Changes propagate upwards; returns the parents whose status changed.
+func (s *Service) syncParentStatus(parentID int) ([]*Task, error) {
+	parent, ok := s.index.Get(parentID)
+	if !ok {
+		return nil, nil
+	}
+	subtasks := s.index.GetSubtasks(parentID)
+	if len(subtasks) == 0 {
+		return nil, nil
+	}
+
+	allDone, anyStarted := true, false
+	for _, sub := range subtasks {
+		if sub.Status != StatusDone {
+			allDone = false
+		}
+		if sub.Status != StatusTodo {
+			anyStarted = true
+		}
+	}
+
+	status := parent.Status
+	switch {
+	case allDone:
+		status = StatusDone
+	case parent.Status == StatusDone:
+		status = StatusInProgress
+	case parent.Status == StatusTodo && anyStarted:
+		status = StatusInProgress
+	}
+	if status == parent.Status {
+		return nil, nil
+	}
+	updated, err := s.update(parentID, nil, nil, &status, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update parent task %d: %w", parentID, err)
+	}
+	result := []*Task{updated}
+	if updated.ParentID != nil {
+		above, err := s.syncParentStatus(*updated.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, above...)
+	}
+	return result, nil
+}
+
+// HierarchyFilter selects the tasks shown by Hierarchy
+type HierarchyFilter struct {
+	Status            *Status
+	Priority          *Priority
+	Type              *string
+	ParentID          int // Show the subtasks of this task; 0 = top-level tasks
+	MaxDepth          int // Levels shown; deeper subtasks are collapsed (0 = all)
+	IncludeDuplicates bool
+}
+
+// HierarchyNode is a task with its subtasks. Context nodes do not match the
+// filter themselves and are shown because a descendant does.
+type HierarchyNode struct {
+	*Task
+	Blocked   bool             `json:"blocked"`
+	Context   bool             `json:"context,omitempty"`
+	Collapsed int              `json:"collapsed_subtasks,omitempty"` // Subtasks hidden by MaxDepth
+	Subtasks  []*HierarchyNode `json:"subtasks,omitempty"`
+}
+
+// Hierarchy returns the tasks below f.ParentID as a tree in rank order.
+// Tasks not matching the status, priority and type filters are left out
+// unless one of their descendants matches. Tasks do not include descriptions.
+func (s *Service) Hierarchy(f HierarchyFilter) ([]*HierarchyNode, error) {
+	if f.Status != nil && !IsValidStatus(string(*f.Status)) {
+		return nil, fmt.Errorf("invalid status: %s", *f.Status)
+	}
+	if f.Priority != nil && !IsValidPriority(string(*f.Priority)) {
+		return nil, fmt.Errorf("invalid priority: %s", *f.Priority)
+	}
+	if f.MaxDepth < 0 {
+		return nil, fmt.Errorf("depth must not be negative")
+	}
+	var parentID *int
+	if f.ParentID != 0 {
+		if _, ok := s.index.Get(f.ParentID); !ok {
+			return nil, fmt.Errorf("task not found: %d", f.ParentID)
+		}
+		parentID = &f.ParentID
+	}
+	matches := func(t *Task) bool {
+		return (f.Status == nil || t.Status == *f.Status) &&
+			(f.Priority == nil || t.Priority == *f.Priority) &&
+			(f.Type == nil || t.Type == *f.Type)
+	}
+
+	seen := make(map[int]bool)
+	var build func(parentID *int, depth int) []*HierarchyNode
+	build = func(parentID *int, depth int) []*HierarchyNode {
+		siblings := s.siblings(parentID)
+		if !f.IncludeDuplicates {
+			siblings = s.WithoutDuplicates(siblings)
+		}
+		var nodes []*HierarchyNode
+		for _, t := range siblings {
+			if seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			node := &HierarchyNode{Task: t, Context: !matches(t)}
+			node.Subtasks = build(&t.ID, depth+1)
+			if node.Context && len(node.Subtasks) == 0 {
+				continue
+			}
+			node.Blocked, _ = s.IsBlocked(t.ID)
+			if f.MaxDepth > 0 && depth >= f.MaxDepth {
+				node.Collapsed = len(node.Subtasks)
+				node.Subtasks = nil
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(parentID, 1), nil
+}
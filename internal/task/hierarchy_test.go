@@ -0,0 +1,231 @@
+package task
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestService_SetParent(t *testing.T) {
+	// 1 and 2 are parents, 3 is a subtask of 1 and 4 of 2
+	svc := newDependencyTestService(t, 2, nil)
+	for _, parent := range []int{1, 2} {
+		if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", parent); err != nil {
+			t.Fatalf("CreateSubtask() error = %v", err)
+		}
+	}
+	if err := svc.AddRelation(3, "relates_to", 4); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	if _, err := svc.StartTask(3); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	if _, err := svc.StartTask(4); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	if _, err := svc.CompleteTask(4); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+
+	// Moving the open subtask 3 under done parent 2 reopens it; 1 loses its
+	// only subtask and keeps its status
+	result, err := svc.SetParent(3, intPtr(2))
+	if err != nil {
+		t.Fatalf("SetParent() error = %v", err)
+	}
+	if result.Task.ParentID == nil || *result.Task.ParentID != 2 {
+		t.Errorf("ParentID = %v, want 2", result.Task.ParentID)
+	}
+	if len(result.Task.Relations) != 1 {
+		t.Errorf("Relations = %v, want relation kept", result.Task.Relations)
+	}
+	if len(result.UpdatedParents) != 1 || result.UpdatedParents[0].ID != 2 || result.UpdatedParents[0].Status != StatusInProgress {
+		t.Errorf("UpdatedParents = %v, want parent 2 back in progress", result.UpdatedParents)
+	}
+	if svc.index.HasSubtasks(1) {
+		t.Error("task 1 should have no subtasks left")
+	}
+
+	// Promoting 3 leaves only done subtask 4 under 2, which auto-completes it
+	result, err = svc.SetParent(3, nil)
+	if err != nil {
+		t.Fatalf("SetParent(nil) error = %v", err)
+	}
+	if result.Task.ParentID != nil {
+		t.Errorf("ParentID = %v, want top level", *result.Task.ParentID)
+	}
+	if p, _ := svc.Get(2); p.Status != StatusDone {
+		t.Errorf("parent 2 status = %s, want done", p.Status)
+	}
+}
+
+func TestService_SetParent_Errors(t *testing.T) {
+	svc := newDependencyTestService(t, 2, nil)
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       int
+		parentID *int
+	}{
+		{"own parent", 2, intPtr(2)},
+		{"under own subtask", 1, intPtr(3)},
+		{"unchanged", 3, intPtr(1)},
+		{"missing parent", 2, intPtr(99)},
+	}
+	for _, tt := range tests {
+		if _, err := svc.SetParent(tt.id, tt.parentID); err == nil {
+			t.Errorf("%s: SetParent() expected error", tt.name)
+		}
+	}
+
+	// Task 1 with its subtask would end up three levels deep
+	svc.config.Hierarchy.MaxDepth = 2
+	if _, err := svc.SetParent(1, intPtr(2)); err == nil || !strings.Contains(err.Error(), "maximum depth is 2") {
+		t.Errorf("SetParent() error = %v, want maximum depth error", err)
+	}
+}
+
+func TestService_SetParent_RejectsArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	svc.Create("Archived", "", PriorityMedium, "feature", nil)
+	svc.Create("Todo", "", PriorityMedium, "feature", nil)
+	completeAndArchive(t, svc, 1)
+
+	if _, err := svc.SetParent(1, intPtr(2)); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("SetParent() of an archived task error = %v, want archived error", err)
+	}
+	if _, err := svc.SetParent(2, intPtr(1)); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("SetParent() under an archived task error = %v, want archived error", err)
+	}
+	if _, ok := svc.index.Get(1); ok {
+		t.Error("archived task should not be written back to the active tasks")
+	}
+	if todo, _ := svc.Get(2); todo.Status != StatusTodo {
+		t.Errorf("Status = %s, the would-be parent should stay todo", todo.Status)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestService_NestedHierarchy_StartCompleteArchive(t *testing.T) {
+	svc, _, as := newServiceWithArchive()
+
+	epic, _ := svc.Create("Epic", "", PriorityHigh, "feature", nil)
+	story, _ := svc.CreateSubtask("Story", "", PriorityMedium, "feature", epic.ID)
+	step1, _ := svc.CreateSubtask("Step 1", "", PriorityMedium, "feature", story.ID)
+	step2, _ := svc.CreateSubtask("Step 2", "", PriorityMedium, "feature", story.ID)
+
+	if total, done := svc.GetSubtaskCounts(epic.ID); total != 3 || done != 0 {
+		t.Errorf("GetSubtaskCounts(epic) = %d/%d, want 0/3", done, total)
+	}
+
+	// Starting a step starts the story and the epic
+	if _, err := svc.StartTask(step1.ID); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	for _, id := range []int{epic.ID, story.ID} {
+		if got, _ := svc.Get(id); got.Status != StatusInProgress {
+			t.Errorf("task %d status = %s, want in_progress", id, got.Status)
+		}
+	}
+
+	// The epic cannot be completed while a step is open
+	if _, err := svc.CompleteTask(epic.ID); err == nil {
+		t.Error("CompleteTask(epic) should fail with incomplete nested subtasks")
+	}
+
+	if _, err := svc.CompleteTask(step1.ID); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	if _, err := svc.StartTask(step2.ID); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	if _, err := svc.CompleteTask(step2.ID); err != nil {
+		t.Fatalf("CompleteTask() error = %v", err)
+	}
+	for _, id := range []int{epic.ID, story.ID} {
+		if got, _ := svc.Get(id); got.Status != StatusDone {
+			t.Errorf("task %d status = %s, want done", id, got.Status)
+		}
+	}
+
+	_, subtasks, _ := svc.GetWithSubtasks(epic.ID)
+	if len(subtasks) != 3 || subtasks[0].ID != story.ID {
+		t.Errorf("GetWithSubtasks() subtasks = %d, want story first of 3", len(subtasks))
+	}
+
+	if err := svc.ArchiveTask(epic.ID); err != nil {
+		t.Fatalf("ArchiveTask() error = %v", err)
+	}
+	for _, id := range []int{epic.ID, story.ID, step1.ID, step2.ID} {
+		if !as.IsArchived(id) {
+			t.Errorf("task %d should be archived", id)
+		}
+	}
+}
+
+// outline renders a hierarchy as "id[flags](subtasks)" for comparisons
+func outline(nodes []*HierarchyNode) string {
+	var parts []string
+	for _, n := range nodes {
+		s := strconv.Itoa(n.ID)
+		if n.Context {
+			s += "c"
+		}
+		if n.Blocked {
+			s += "b"
+		}
+		if n.Collapsed > 0 {
+			s += "+" + strconv.Itoa(n.Collapsed)
+		}
+		if len(n.Subtasks) > 0 {
+			s += "(" + outline(n.Subtasks) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestService_Hierarchy(t *testing.T) {
+	// 3 is a subtask of 1, 4 (a bug blocked by 2) of 3, 5 of 2
+	svc := newDependencyTestService(t, 2, nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1)
+	bug, _ := svc.CreateSubtask("Bug", "", PriorityHigh, "bug", sub.ID)
+	svc.CreateSubtask("Other", "", PriorityMedium, "feature", 2)
+	if err := svc.AddRelation(bug.ID, RelationBlockedBy, 2); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+
+	bugType := "bug"
+	tests := []struct {
+		name string
+		f    HierarchyFilter
+		want string
+	}{
+		{"all", HierarchyFilter{}, "1(3(4b)) 2(5)"},
+		{"subtree", HierarchyFilter{ParentID: 1}, "3(4b)"},
+		{"depth", HierarchyFilter{MaxDepth: 1}, "1+1 2+1"},
+		{"filter keeps ancestors", HierarchyFilter{Type: &bugType}, "1c(3c(4b))"},
+		{"filter and depth", HierarchyFilter{Type: &bugType, MaxDepth: 2}, "1c(3c+1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := svc.Hierarchy(tt.f)
+			if err != nil {
+				t.Fatalf("Hierarchy() error = %v", err)
+			}
+			if got := outline(nodes); got != tt.want {
+				t.Errorf("Hierarchy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := svc.Hierarchy(HierarchyFilter{ParentID: 99}); err == nil {
+		t.Error("expected error for unknown parent")
+	}
+}
@@ -0,0 +1,243 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImportRecord is a task from an external tracker, already mapped onto task
+// fields. ExternalID identifies the record across re-imports; ParentExternalID
+// links it to another record (or previously imported task) as a subtask.
+type ImportRecord struct {
+	ExternalID       string
+	ParentExternalID string
+	Title            string
+	Description      string
+	Status           Status
+	Priority         Priority
+	Type             string
+	Tags             []string
+}
+
+// ImportResult summarises an import run
+type ImportResult struct {
+	Created  []int    `json:"created"`
+	Updated  []int    `json:"updated"`
+	Skipped  []int    `json:"skipped,omitempty"` // Archived tasks matching a record, left unchanged
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Import creates or updates tasks from external records. Tasks are matched by
+// ExternalID, so importing the same export twice updates the existing tasks
+// instead of duplicating them. Records matching an archived task are skipped
+// with a warning. All records are validated before any file is written, and
+// the index is saved once.
+func (s *Service) Import(records []ImportRecord) (*ImportResult, error) {
+	result := &ImportResult{}
+	if len(records) == 0 {
+		return result, nil
+	}
+
+	// Existing tasks by external ID
+	existingByExt := make(map[string]*Task)
+	for _, t := range s.index.All() {
+		if t.ExternalID != "" {
+			existingByExt[t.ExternalID] = t
+		}
+	}
+	archivedByExt := make(map[string]int)
+	if s.archiveStorage != nil {
+		archived, err := s.archiveStorage.FilterArchived(nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range archived {
+			if t.ExternalID != "" {
+				archivedByExt[t.ExternalID] = t.ID
+			}
+		}
+	}
+
+	// Validate and assign IDs
+	idByExt := make(map[string]int)
+	isNew := make(map[int]bool)
+	seen := make(map[string]bool)
+	var imported []ImportRecord
+	nextID := s.nextID()
+	for i, rec := range records {
+		if rec.ExternalID == "" {
+			return nil, fmt.Errorf("record %d: external ID is required", i+1)
+		}
+		if seen[rec.ExternalID] {
+			return nil, fmt.Errorf("duplicate external ID in import: %s", rec.ExternalID)
+		}
+		seen[rec.ExternalID] = true
+		if rec.Title == "" {
+			return nil, fmt.Errorf("record %s: title is required", rec.ExternalID)
+		}
+		if !IsValidStatus(string(rec.Status)) {
+			return nil, fmt.Errorf("record %s: invalid status: %s", rec.ExternalID, rec.Status)
+		}
+		if !IsValidPriority(string(rec.Priority)) {
+			return nil, fmt.Errorf("record %s: invalid priority: %s", rec.ExternalID, rec.Priority)
+		}
+		if !s.isValidType(rec.Type) {
+			return nil, fmt.Errorf("record %s: invalid task type: %s", rec.ExternalID, rec.Type)
+		}
+		if id, ok := archivedByExt[rec.ExternalID]; ok {
+			result.Skipped = append(result.Skipped, id)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: task %d is archived, skipped (unarchive it to update it)", rec.ExternalID, id))
+			continue
+		}
+		if existing, ok := existingByExt[rec.ExternalID]; ok {
+			idByExt[rec.ExternalID] = existing.ID
+		} else {
+			idByExt[rec.ExternalID] = nextID
+			isNew[nextID] = true
+			nextID++
+		}
+		imported = append(imported, rec)
+	}
+
+	// Subtasks of archived records stay active, at top level
+	for i, rec := range imported {
+		if _, ok := archivedByExt[rec.ParentExternalID]; ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: parent %s is archived, imported as top-level task", rec.ExternalID, rec.ParentExternalID))
+			imported[i].ParentExternalID = ""
+		}
+	}
+
+	parents := s.resolveImportParents(imported, idByExt, existingByExt, result)
+
+	// Build the tasks to write
+	now := time.Now().UTC()
+	var tasks []*Task
+	for _, rec := range imported {
+		id := idByExt[rec.ExternalID]
+		var t *Task
+		if isNew[id] {
+			t = &Task{ID: id, ExternalID: rec.ExternalID, CreatedAt: now}
+		} else {
+			loaded, ok := s.index.Get(id)
+			if !ok {
+				return nil, fmt.Errorf("task not found: %d", id)
+			}
+			t = loaded
+		}
+		t.ParentID = parents[id]
+		t.Title = rec.Title
+		t.Description = rec.Description
+		t.setStatus(rec.Status, now)
+		t.Priority = rec.Priority
+		t.Type = rec.Type
+		t.Tags = rec.Tags
+		t.UpdatedAt = now
+		tasks = append(tasks, t)
+	}
+
+	// Ensure directory exists for write operation
+	if err := s.storage.EnsureDir(); err != nil {
+		return nil, err
+	}
+
+	for _, t := range tasks {
+		if err := s.storage.Save(t); err != nil {
+			// Keep the index consistent with the files written so far
+			_ = s.index.Save()
+			return nil, fmt.Errorf("failed to save task %d: %w", t.ID, err)
+		}
+		s.index.Set(t)
+		if isNew[t.ID] {
+			result.Created = append(result.Created, t.ID)
+		} else {
+			result.Updated = append(result.Updated, t.ID)
+		}
+	}
+
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveImportParents determines the parent ID of every imported task.
+// Links that would nest tasks deeper than the configured maximum depth, form
+// a cycle, or point to unknown records are dropped with a warning.
+func (s *Service) resolveImportParents(records []ImportRecord, idByExt map[string]int, existingByExt map[string]*Task, result *ImportResult) map[int]*int {
+	// Requested parent per imported task ID
+	requested := make(map[int]int)
+	imported := make(map[int]bool)
+	for _, rec := range records {
+		id := idByExt[rec.ExternalID]
+		imported[id] = true
+		if rec.ParentExternalID == "" {
+			continue
+		}
+		parentID, ok := idByExt[rec.ParentExternalID]
+		if !ok {
+			existing, found := existingByExt[rec.ParentExternalID]
+			if !found {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: parent %s not found, imported as top-level task", rec.ExternalID, rec.ParentExternalID))
+				continue
+			}
+			parentID = existing.ID
+		}
+		if parentID == id {
+			continue
+		}
+		requested[id] = parentID
+	}
+
+	limit := s.MaxDepth()
+	parents := make(map[int]*int)
+	depths := make(map[int]int)
+	var resolve func(id int, visiting map[int]bool) int
+	resolve = func(id int, visiting map[int]bool) int {
+		if d, ok := depths[id]; ok {
+			return d
+		}
+		if !imported[id] {
+			// Tasks outside the import keep their current place
+			return s.Depth(id)
+		}
+		visiting[id] = true
+		defer delete(visiting, id)
+
+		depth := 1
+		if parentID, ok := requested[id]; ok {
+			switch {
+			case visiting[parentID]:
+				result.Warnings = append(result.Warnings, fmt.Sprintf("task %d: parent %d would create a cycle, imported as top-level task", id, parentID))
+			case limit > 0 && resolve(parentID, visiting) >= limit:
+				result.Warnings = append(result.Warnings, fmt.Sprintf("task %d: parent %d is at maximum depth %d, imported as top-level task", id, parentID, limit))
+			default:
+				p := parentID
+				parents[id] = &p
+				depth = resolve(parentID, visiting) + 1
+			}
+		}
+		depths[id] = depth
+		return depth
+	}
+
+	for _, rec := range records {
+		resolve(idByExt[rec.ExternalID], make(map[int]bool))
+	}
+
+	// Existing subtasks move along with their parent and must still fit
+	if limit > 0 {
+		for _, rec := range records {
+			id := idByExt[rec.ExternalID]
+			parentID, ok := parents[id]
+			if !ok || !s.index.HasSubtasks(id) {
+				continue
+			}
+			if depths[id]+s.subtreeHeight(id)-1 > limit {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("task %d: has subtasks that would exceed maximum depth %d, not moved under parent %d", id, limit, *parentID))
+				delete(parents, id)
+			}
+		}
+	}
+
+	return parents
+}
@@ -0,0 +1,144 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestService_Import_CreatesAndUpdates(t *testing.T) {
+	idx := newMockIndex()
+	svc := NewService(newMockStorage(), nil, idx, []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	records := []ImportRecord{
+		{ExternalID: "jira:P-1", Title: "Epic", Status: StatusTodo, Priority: PriorityHigh, Type: "feature"},
+		{ExternalID: "jira:P-2", ParentExternalID: "jira:P-1", Title: "Story", Status: StatusTodo, Priority: PriorityLow, Type: "bug", Tags: []string{"auth"}},
+	}
+	result, err := svc.Import(records)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Created) != 2 || len(result.Updated) != 0 {
+		t.Fatalf("result = %+v, want 2 created", result)
+	}
+
+	story, _ := svc.Get(result.Created[1])
+	if story.ParentID == nil || *story.ParentID != result.Created[0] {
+		t.Errorf("story.ParentID = %v, want %d", story.ParentID, result.Created[0])
+	}
+	if story.ExternalID != "jira:P-2" || len(story.Tags) != 1 {
+		t.Errorf("story = %+v, want external ID and tags", story)
+	}
+
+	// Re-import updates instead of duplicating
+	records[1].Title = "Story (renamed)"
+	records[1].Status = StatusDone
+	savesBefore := idx.saves
+	result, err = svc.Import(records)
+	if err != nil {
+		t.Fatalf("Import() second run error = %v", err)
+	}
+	if len(result.Created) != 0 || len(result.Updated) != 2 {
+		t.Fatalf("result = %+v, want 2 updated", result)
+	}
+	if idx.saves-savesBefore != 1 {
+		t.Errorf("index saved %d times, want 1", idx.saves-savesBefore)
+	}
+	if n := len(svc.List(nil, nil, nil, nil)); n != 2 {
+		t.Errorf("task count after re-import = %d, want 2", n)
+	}
+	story, _ = svc.Get(story.ID)
+	if story.Title != "Story (renamed)" || story.Status != StatusDone {
+		t.Errorf("story = %q/%s, want updated title and status", story.Title, story.Status)
+	}
+}
+
+func TestService_Import_FlattensDeepHierarchy(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	records := []ImportRecord{
+		{ExternalID: "x:1", Title: "Epic", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "x:2", ParentExternalID: "x:1", Title: "Story", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "x:3", ParentExternalID: "x:2", Title: "Sub-task", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "x:4", ParentExternalID: "x:99", Title: "Orphan", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "x:5", ParentExternalID: "x:3", Title: "Step", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+	}
+	result, err := svc.Import(records)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want 2", result.Warnings)
+	}
+
+	subtask, _ := svc.Get(result.Created[2])
+	if subtask.ParentID == nil || *subtask.ParentID != result.Created[1] {
+		t.Errorf("sub-task ParentID = %v, want %d", subtask.ParentID, result.Created[1])
+	}
+	step, _ := svc.Get(result.Created[4])
+	if step.ParentID != nil {
+		t.Errorf("step ParentID = %d, want nil (beyond maximum depth)", *step.ParentID)
+	}
+	orphan, _ := svc.Get(result.Created[3])
+	if orphan.ParentID != nil {
+		t.Errorf("orphan ParentID = %d, want nil", *orphan.ParentID)
+	}
+}
+
+func TestService_Import_Validation(t *testing.T) {
+	storage := newMockStorage()
+	svc := NewService(storage, nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	records := []ImportRecord{
+		{ExternalID: "x:1", Title: "Valid", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "x:2", Title: "Invalid", Status: StatusTodo, Priority: PriorityMedium, Type: "chore"},
+	}
+	_, err := svc.Import(records)
+	if err == nil || !strings.Contains(err.Error(), "invalid task type") {
+		t.Fatalf("Import() error = %v, want invalid task type", err)
+	}
+	if len(storage.tasks) != 0 {
+		t.Errorf("%d task(s) written despite validation error", len(storage.tasks))
+	}
+}
+
+func TestService_Import_SkipsArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	records := []ImportRecord{
+		{ExternalID: "gh:1", Title: "Shipped", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+		{ExternalID: "gh:2", Title: "Open", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"},
+	}
+	result, err := svc.Import(records)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	shipped := result.Created[0]
+	completeAndArchive(t, svc, shipped)
+
+	// Re-importing the same export neither duplicates nor revives the archived task
+	records = append(records, ImportRecord{ExternalID: "gh:3", ParentExternalID: "gh:1", Title: "Follow-up", Status: StatusTodo, Priority: PriorityMedium, Type: "feature"})
+	result, err = svc.Import(records)
+	if err != nil {
+		t.Fatalf("Import() second run error = %v", err)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != shipped {
+		t.Errorf("Skipped = %v, want [%d]", result.Skipped, shipped)
+	}
+	if len(result.Created) != 1 || len(result.Updated) != 1 {
+		t.Errorf("result = %+v, want the follow-up created and gh:2 updated", result)
+	}
+	if len(result.Warnings) != 2 || !strings.Contains(result.Warnings[0], "archived") || !strings.Contains(result.Warnings[1], "parent gh:1 is archived") {
+		t.Errorf("Warnings = %v, want the skipped record and the orphaned follow-up", result.Warnings)
+	}
+	if _, ok := svc.index.Get(shipped); ok {
+		t.Error("archived task should not be back in the active index")
+	}
+	if followUp, _ := svc.Get(result.Created[0]); followUp.ParentID != nil {
+		t.Errorf("follow-up ParentID = %v, want top level", followUp.ParentID)
+	}
+	if n := len(svc.List(nil, nil, nil, nil)); n != 2 {
+		t.Errorf("active task count = %d, want 2", n)
+	}
+}
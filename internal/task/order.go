@@ -0,0 +1,127 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SortByRank sorts tasks by their rank among siblings, falling back to ID
+func SortByRank(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Rank() != tasks[j].Rank() {
+			return tasks[i].Rank() < tasks[j].Rank()
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// siblings returns the tasks sharing parentID (nil = top level), in rank order
+func (s *Service) siblings(parentID *int) []*Task {
+	var result []*Task
+	if parentID != nil {
+		result = s.index.GetSubtasks(*parentID)
+	} else {
+		for _, t := range s.index.All() {
+			if t.ParentID == nil {
+				result = append(result, t)
+			}
+		}
+	}
+	SortByRank(result)
+	return result
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// MoveTask places a task directly before or after a sibling.
+// Exactly one of before and after must be non-zero.
+func (s *Service) MoveTask(id, before, after int) (*Task, error) {
+	if (before == 0) == (after == 0) {
+		return nil, fmt.Errorf("exactly one of before or after is required")
+	}
+	anchorID := before
+	if anchorID == 0 {
+		anchorID = after
+	}
+	if anchorID == id {
+		return nil, fmt.Errorf("cannot move task %d relative to itself", id)
+	}
+
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := s.activeTask(anchorID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameParent(t.ParentID, anchor.ParentID) {
+		return nil, fmt.Errorf("task %d and task %d do not share the same parent", id, anchorID)
+	}
+
+	var others []*Task
+	for _, sib := range s.siblings(t.ParentID) {
+		if sib.ID != id {
+			others = append(others, sib)
+		}
+	}
+	pos := 0
+	for i, sib := range others {
+		if sib.ID == anchorID {
+			pos = i
+			break
+		}
+	}
+	if after != 0 {
+		pos++
+	}
+
+	prev := 0
+	if pos > 0 {
+		prev = others[pos-1].Rank()
+	}
+	rank := prev + OrderStep
+	if pos < len(others) {
+		rank = prev + (others[pos].Rank()-prev)/2
+	}
+
+	changed := make(map[int]int)
+	if rank > prev && (pos == len(others) || rank < others[pos].Rank()) {
+		changed[id] = rank
+	} else {
+		// No room between the neighbours: renumber all siblings
+		ordered := append(append(append([]*Task{}, others[:pos]...), t), others[pos:]...)
+		for i, sib := range ordered {
+			if sib.Order != (i+1)*OrderStep {
+				changed[sib.ID] = (i + 1) * OrderStep
+			}
+		}
+	}
+
+	for sibID, order := range changed {
+		sib := t
+		if sibID != id {
+			if sib, err = s.activeTask(sibID); err != nil {
+				return nil, err
+			}
+		}
+		sib.Order = order
+		if sibID == id {
+			sib.UpdatedAt = time.Now().UTC()
+		}
+		if err := s.storage.Save(sib); err != nil {
+			return nil, fmt.Errorf("failed to save task %d: %w", sibID, err)
+		}
+		s.index.Set(sib)
+	}
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
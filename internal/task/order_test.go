@@ -0,0 +1,93 @@
+package task
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func siblingIDs(svc *Service, parentID *int) []int {
+	ids := []int{}
+	for _, t := range svc.siblings(parentID) {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestService_MoveTask(t *testing.T) {
+	svc := newDependencyTestService(t, 3, nil)
+
+	if _, err := svc.MoveTask(3, 1, 0); err != nil {
+		t.Fatalf("MoveTask(3, before 1) error = %v", err)
+	}
+	if got := siblingIDs(svc, nil); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("order = %v, want [3 1 2]", got)
+	}
+
+	if _, err := svc.MoveTask(1, 0, 2); err != nil {
+		t.Fatalf("MoveTask(1, after 2) error = %v", err)
+	}
+	if got := siblingIDs(svc, nil); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("order = %v, want [3 2 1]", got)
+	}
+}
+
+func TestService_MoveTask_RenumbersWithoutGap(t *testing.T) {
+	svc := newDependencyTestService(t, 3, nil)
+	for id, order := range map[int]int{1: 1, 2: 2, 3: 3} {
+		task, _ := svc.Get(id)
+		task.Order = order
+		svc.index.Set(task)
+	}
+
+	if _, err := svc.MoveTask(3, 2, 0); err != nil {
+		t.Fatalf("MoveTask() error = %v", err)
+	}
+	if got := siblingIDs(svc, nil); !slices.Equal(got, []int{1, 3, 2}) {
+		t.Errorf("order = %v, want [1 3 2]", got)
+	}
+	moved, _ := svc.Get(3)
+	if moved.Order != 2*OrderStep {
+		t.Errorf("Order = %d, want %d after renumbering", moved.Order, 2*OrderStep)
+	}
+}
+
+func TestService_MoveTask_Errors(t *testing.T) {
+	svc := newDependencyTestService(t, 2, nil)
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		id, before, after int
+	}{
+		{"neither", 1, 0, 0},
+		{"both", 1, 2, 2},
+		{"self", 1, 1, 0},
+		{"not a sibling", 3, 2, 0},
+		{"missing anchor", 1, 99, 0},
+	}
+	for _, tt := range tests {
+		if _, err := svc.MoveTask(tt.id, tt.before, tt.after); err == nil {
+			t.Errorf("%s: MoveTask() expected error", tt.name)
+		}
+	}
+}
+
+func TestService_MoveTask_RejectsArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	svc.Create("Archived", "", PriorityMedium, "feature", nil)
+	svc.Create("Active", "", PriorityMedium, "feature", nil)
+	completeAndArchive(t, svc, 1)
+
+	if _, err := svc.MoveTask(1, 0, 2); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("MoveTask() of an archived task error = %v, want archived error", err)
+	}
+	if _, err := svc.MoveTask(2, 1, 0); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("MoveTask() before an archived task error = %v, want archived error", err)
+	}
+	if _, ok := svc.index.Get(1); ok {
+		t.Error("archived task should not be written back to the active tasks")
+	}
+}
@@ -0,0 +1,336 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+)
+
+// ProjectTask is a task together with the workspace project it belongs to
+type ProjectTask struct {
+	Project string `json:"project"`
+	Ref     string `json:"ref"` // Project-qualified reference, e.g. api#42
+	*Task
+}
+
+// NextAcrossProjects picks the task to work on next from the next task of
+// each project: in-progress work first, then priority, creation time,
+// project name. Returns nil if no project has a task.
+func NextAcrossProjects(services map[string]*Service) *ProjectTask {
+	var best *ProjectTask
+	for name, svc := range services {
+		t := svc.GetNextTask()
+		if t == nil {
+			continue
+		}
+		candidate := &ProjectTask{Project: name, Ref: FormatRef(name, t.ID), Task: t}
+		if best == nil || nextBefore(candidate, best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func nextBefore(a, b *ProjectTask) bool {
+	if (a.Status == StatusInProgress) != (b.Status == StatusInProgress) {
+		return a.Status == StatusInProgress
+	}
+	if a.Priority.Order() != b.Priority.Order() {
+		return a.Priority.Order() < b.Priority.Order()
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.Project < b.Project
+}
+
+// FormatRef returns the project-qualified reference of a task
+func FormatRef(project string, id int) string {
+	return fmt.Sprintf("%s#%d", project, id)
+}
+
+// ProjectResolver opens a workspace project by name
+type ProjectResolver func(name string) (*Service, error)
+
+// NewProjectResolver returns a resolver that opens each project once with
+// open and attaches the workspace to it, so relations of its tasks into
+// other projects resolve as well
+func NewProjectResolver(open func(name string) (*Service, error)) ProjectResolver {
+	var mu sync.Mutex
+	services := make(map[string]*Service)
+	var resolve ProjectResolver
+	resolve = func(name string) (*Service, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if svc, ok := services[name]; ok {
+			return svc, nil
+		}
+		svc, err := open(name)
+		if err != nil {
+			return nil, err
+		}
+		svc.SetWorkspace(name, resolve)
+		services[name] = svc
+		return svc, nil
+	}
+	return resolve
+}
+
+// SetWorkspace names the service's project in its workspace and sets how
+// other projects are opened for relations into them
+func (s *Service) SetWorkspace(project string, projects ProjectResolver) {
+	s.project = project
+	s.projects = projects
+	s.index.SetProjectBlocker(s.projectBlocking)
+}
+
+// isLocalProject reports whether a relation's project is the service's own
+func (s *Service) isLocalProject(project string) bool {
+	return project == "" || project == s.project
+}
+
+// projectService returns the service of a workspace project
+func (s *Service) projectService(project string) (*Service, error) {
+	if s.isLocalProject(project) {
+		return s, nil
+	}
+	if s.projects == nil {
+		return nil, fmt.Errorf("project %s is not available outside a workspace", project)
+	}
+	return s.projects(project)
+}
+
+// projectTask returns a task of a workspace project, falling back to its
+// archive like Get
+func (s *Service) projectTask(project string, id int) (*Task, error) {
+	other, err := s.projectService(project)
+	if err != nil {
+		return nil, err
+	}
+	return other.Get(id)
+}
+
+// isBlockingType reports whether a relation type affects execution order
+func (s *Service) isBlockingType(name string) bool {
+	rt, _, _, err := s.resolveRelation(0, name, 0)
+	return err == nil && rt.Blocking
+}
+
+// projectBlocking reports whether the target of a blocking relation into
+// another project is unresolved. Like local blockers, missing tasks count as
+// resolved and archived ones as done; a project that cannot be opened keeps
+// blocking.
+func (s *Service) projectBlocking(rel Relation) bool {
+	other, err := s.projectService(rel.Project)
+	if err != nil {
+		return true
+	}
+	t, err := other.Get(rel.Task)
+	return err == nil && t.Status != StatusDone
+}
+
+// projectBlockers returns the unresolved blockers of a task in other projects
+func (s *Service) projectBlockers(taskID int) []BlockingInfo {
+	var blockers []BlockingInfo
+	for _, rel := range s.index.ProjectRelations(taskID) {
+		if !s.isBlockingType(rel.Type) {
+			continue
+		}
+		other, err := s.projectService(rel.Project)
+		if err != nil {
+			blockers = append(blockers, BlockingInfo{TaskID: rel.Task, Project: rel.Project, Title: "(project not available)"})
+			continue
+		}
+		t, err := other.Get(rel.Task)
+		if err != nil || t.Status == StatusDone {
+			continue
+		}
+		blockers = append(blockers, BlockingInfo{TaskID: t.ID, Project: rel.Project, Status: t.Status, Title: t.Title})
+	}
+	return blockers
+}
+
+// AddProjectRelation adds a relation from a task to a task in another
+// workspace project. The relation is stored on the source task only. An
+// empty project or the service's own adds a local relation.
+func (s *Service) AddProjectRelation(source int, relationType, project string, target int) error {
+	if s.isLocalProject(project) {
+		return s.AddRelation(source, relationType, target)
+	}
+	rt, _, _, err := s.resolveRelation(source, relationType, target)
+	if err != nil {
+		return err
+	}
+	ref := FormatRef(project, target)
+	if rt.Name != relationType {
+		// Inverse names are stored on the target, pointing back here
+		if s.project == "" {
+			return fmt.Errorf("cannot create relation: %s is stored on %s, but this project is not part of the workspace", relationType, ref)
+		}
+		other, err := s.projectService(project)
+		if err != nil {
+			return err
+		}
+		return other.AddProjectRelation(target, rt.Name, s.project, source)
+	}
+	srcTask, err := s.checkProjectRelation(source, rt, project, target)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.EnsureDir(); err != nil {
+		return err
+	}
+	srcTask.Relations = append(srcTask.Relations, Relation{Type: relationType, Task: target, Project: project})
+	srcTask.UpdatedAt = time.Now().UTC()
+	if err := s.storage.Save(srcTask); err != nil {
+		return err
+	}
+	s.index.Set(srcTask)
+	return s.index.Save()
+}
+
+// checkProjectRelation validates a new relation of canonical type rt from a
+// task to a task in another project and returns the source task
+func (s *Service) checkProjectRelation(source int, rt config.RelationType, project string, target int) (*Task, error) {
+	ref := FormatRef(project, target)
+	if rt.Duplicate {
+		return nil, fmt.Errorf("cannot create relation: %s is not supported between projects", rt.Name)
+	}
+	other, err := s.projectService(project)
+	if err != nil {
+		return nil, err
+	}
+
+	srcTask, err := s.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("source task not found: %d", source)
+	}
+	if err := s.checkNotArchived(source); err != nil {
+		return nil, err
+	}
+	tgtTask, err := other.Get(target)
+	if err != nil {
+		return nil, fmt.Errorf("target task not found: %s", ref)
+	}
+
+	if !isAllowedTaskType(rt.SourceTypes, srcTask.Type) {
+		return nil, fmt.Errorf("cannot create relation: %s is not allowed from %s task %d (allowed source types: %s)", rt.Name, srcTask.Type, source, strings.Join(rt.SourceTypes, ", "))
+	}
+	if !isAllowedTaskType(rt.TargetTypes, tgtTask.Type) {
+		return nil, fmt.Errorf("cannot create relation: %s is not allowed to %s task %s (allowed target types: %s)", rt.Name, tgtTask.Type, ref, strings.Join(rt.TargetTypes, ", "))
+	}
+	if hasProjectRelation(srcTask, rt.Name, project, target) {
+		return nil, fmt.Errorf("relation already exists: %s from %d to %s", rt.Name, source, ref)
+	}
+	if rt.Blocking {
+		if err := s.checkProjectBlockingCycle(source, project, target); err != nil {
+			return nil, fmt.Errorf("cannot create relation: %w", err)
+		}
+	}
+	return srcTask, nil
+}
+
+// RemoveProjectRelation removes a relation from a task to a task in another
+// workspace project
+func (s *Service) RemoveProjectRelation(source int, relationType, project string, target int) error {
+	if s.isLocalProject(project) {
+		return s.RemoveRelation(source, relationType, target)
+	}
+	rt, _, _, err := s.resolveRelation(source, relationType, target)
+	if err != nil {
+		return err
+	}
+	if rt.Name != relationType {
+		if s.project == "" {
+			return fmt.Errorf("relation not found: %s from %d to %s", relationType, source, FormatRef(project, target))
+		}
+		other, err := s.projectService(project)
+		if err != nil {
+			return err
+		}
+		return other.RemoveProjectRelation(target, rt.Name, s.project, source)
+	}
+
+	srcTask, err := s.Get(source)
+	if err != nil {
+		return fmt.Errorf("source task not found: %d", source)
+	}
+	if !hasProjectRelation(srcTask, relationType, project, target) {
+		return fmt.Errorf("relation not found: %s from %d to %s", relationType, source, FormatRef(project, target))
+	}
+	if err := s.checkNotArchived(source); err != nil {
+		return err
+	}
+
+	var relations []Relation
+	for _, rel := range srcTask.Relations {
+		if rel.Type != relationType || rel.Project != project || rel.Task != target {
+			relations = append(relations, rel)
+		}
+	}
+	srcTask.Relations = relations
+	srcTask.UpdatedAt = time.Now().UTC()
+	if err := s.storage.Save(srcTask); err != nil {
+		return err
+	}
+	s.index.Set(srcTask)
+	return s.index.Save()
+}
+
+// hasProjectRelation reports whether t has a relation of the given type to
+// a task in another project
+func hasProjectRelation(t *Task, relationType, project string, target int) bool {
+	for _, rel := range t.Relations {
+		if rel.Type == relationType && rel.Project == project && rel.Task == target {
+			return true
+		}
+	}
+	return false
+}
+
+// projectRef identifies a task across workspace projects
+type projectRef struct {
+	project string
+	id      int
+}
+
+// checkProjectBlockingCycle rejects a blocking relation from source to a
+// task in another project that already waits for source, following blocking
+// relations through any project. Needs the service's own project name to
+// recognise source; without it only local cycles are detected.
+func (s *Service) checkProjectBlockingCycle(source int, project string, target int) error {
+	if s.project == "" {
+		return nil
+	}
+	start := projectRef{s.project, source}
+	seen := map[projectRef]bool{}
+	queue := []projectRef{{project, target}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == start {
+			return fmt.Errorf("would create a dependency cycle: %s already waits for %s", FormatRef(project, target), FormatRef(s.project, source))
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		svc, err := s.projectService(current.project)
+		if err != nil {
+			continue
+		}
+		for _, id := range svc.index.GetBlockers(current.id) {
+			queue = append(queue, projectRef{current.project, id})
+		}
+		for _, rel := range svc.index.ProjectRelations(current.id) {
+			if svc.isBlockingType(rel.Type) {
+				queue = append(queue, projectRef{rel.Project, rel.Task})
+			}
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
@@ -54,15 +55,22 @@ type Index interface {
 	RemoveRelation(edge RelationEdge)
 	GetRelationsForTask(taskID int) []RelationEdge
 	GetBlockers(taskID int) []int
+	GetDependents(taskID int) []int
+	DuplicateOf(taskID int) (int, bool)
 	RemoveAllRelationsForTask(taskID int) []RelationEdge
+	// Relations to tasks in other workspace projects (not part of the edges above)
+	ProjectRelations(taskID int) []Relation
+	SetProjectBlocker(blocking func(rel Relation) bool) // Reports whether a blocking relation to another project is unresolved
 }
 
-// ArchiveStorage extends Storage with archive-specific operations
+// ArchiveStorage keeps archived tasks out of the active index
 type ArchiveStorage interface {
 	Archive(id int) error
+	Unarchive(id int) ([]RelationEdge, error) // Returns relations earlier versions removed from other tasks when archiving
 	LoadArchived(id int) (*Task, error)
-	LoadAllArchived() ([]*Task, error)
+	FilterArchived(status *Status, priority *Priority, taskType *string, parentID *int) ([]*Task, error) // Without descriptions
 	IsArchived(id int) bool
+	NextID() int
 }
 
 // Service provides task management operations
@@ -72,6 +80,8 @@ type Service struct {
 	index          Index
 	validTypes     []string
 	config         *config.Config
+	project        string          // Name in the workspace; empty if unknown
+	projects       ProjectResolver // Opens other workspace projects; nil outside a workspace
 }
 
 // NewService creates a new task service
@@ -107,7 +117,7 @@ func (s *Service) Initialize() error {
 	}
 	// Run auto-archive on startup if enabled
 	if s.config != nil && s.config.AutoArchive.Enabled {
-		if err := s.RunAutoArchive(); err != nil {
+		if _, err := s.RunAutoArchive(); err != nil {
 			// Log but don't fail startup
 			log.Printf("auto-archive on startup failed: %v", err)
 		}
@@ -129,12 +139,11 @@ func (s *Service) Create(title, description string, priority Priority, taskType
 
 	// Validate parent if provided
 	if parentID != nil {
-		parent, ok := s.index.Get(*parentID)
-		if !ok {
+		if _, ok := s.index.Get(*parentID); !ok {
 			return nil, fmt.Errorf("parent task not found: %d", *parentID)
 		}
-		if parent.ParentID != nil {
-			return nil, fmt.Errorf("cannot create subtask under a subtask (single level only)")
+		if err := s.checkDepth(*parentID, 1); err != nil {
+			return nil, err
 		}
 	}
 
@@ -145,7 +154,7 @@ func (s *Service) Create(title, description string, priority Priority, taskType
 
 	now := time.Now().UTC()
 	t := &Task{
-		ID:          s.index.NextID(),
+		ID:          s.nextID(),
 		ParentID:    parentID,
 		Title:       title,
 		Description: description,
@@ -168,6 +177,15 @@ func (s *Service) Create(title, description string, priority Priority, taskType
 	return t, nil
 }
 
+// nextID returns the ID for a new task, skipping the IDs of archived tasks
+func (s *Service) nextID() int {
+	id := s.index.NextID()
+	if s.archiveStorage != nil {
+		id = max(id, s.archiveStorage.NextID())
+	}
+	return id
+}
+
 // CreateSubtask creates a subtask under a parent
 func (s *Service) CreateSubtask(title, description string, priority Priority, taskType string, parentID int) (*Task, error) {
 	return s.Create(title, description, priority, taskType, &parentID)
@@ -189,31 +207,106 @@ func (s *Service) Get(id int) (*Task, error) {
 	return nil, fmt.Errorf("task not found: %d", id)
 }
 
-// GetWithSubtasks returns a task and its subtasks in one call
+// activeTask returns a task from the active index with full description.
+// Archived tasks are rejected, since saving them would bring them back into
+// the active tasks while the archived copy stays.
+func (s *Service) activeTask(id int) (*Task, error) {
+	if t, ok := s.index.Get(id); ok {
+		return t, nil
+	}
+	if s.archiveStorage != nil && s.archiveStorage.IsArchived(id) {
+		return nil, fmt.Errorf("task %d is archived; unarchive it first", id)
+	}
+	return nil, fmt.Errorf("task not found: %d", id)
+}
+
+// GetWithSubtasks returns a task and its subtasks at all levels in one call,
+// each parent before its own subtasks
 func (s *Service) GetWithSubtasks(id int) (*Task, []*Task, error) {
 	t, err := s.Get(id)
 	if err != nil {
 		return nil, nil, err
 	}
-	subtasks := s.index.GetSubtasks(id)
-	return t, subtasks, nil
+	return t, s.Descendants(id), nil
 }
 
-// GetSubtaskCounts returns the count of subtasks for a task
+// GetSubtaskCounts returns the count of subtasks at all levels below a task
 func (s *Service) GetSubtaskCounts(taskID int) (total, done int) {
 	return s.index.SubtaskCounts(taskID)
 }
 
-// Update modifies a task
-func (s *Service) Update(id int, title, description *string, status *Status, priority *Priority, taskType *string) (*Task, error) {
-	t, err := s.Get(id)
+// Update modifies a task. Status changes follow the rules of StartTask,
+// CompleteTask and ReopenTask and update ancestors accordingly; force skips
+// both and is recorded in the task's history.
+func (s *Service) Update(id int, title, description *string, status *Status, priority *Priority, taskType *string, force bool) (*Task, error) {
+	if status == nil {
+		return s.update(id, title, description, nil, priority, taskType)
+	}
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	from := t.Status
+	changing := *status != from && IsValidStatus(string(*status))
+	if changing && !force {
+		if err := s.checkStatusChange(t, *status, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.applyChanges(t, title, description, status, priority, taskType); err != nil {
+		return nil, err
+	}
+	t.UpdatedAt = time.Now().UTC()
+	if changing && force {
+		t.History = append(t.History, StatusChange{Action: ActionForce, From: from, To: t.Status, At: t.UpdatedAt})
+	}
+	if err := s.storage.Save(t); err != nil {
+		return nil, err
+	}
+	s.index.Set(t)
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+
+	if changing && !force && t.ParentID != nil {
+		if _, err := s.syncParentStatus(*t.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// update modifies a task without status validation; used for cascades
+func (s *Service) update(id int, title, description *string, status *Status, priority *Priority, taskType *string) (*Task, error) {
+	t, err := s.activeTask(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.applyChanges(t, title, description, status, priority, taskType); err != nil {
+		return nil, err
+	}
+
+	t.UpdatedAt = time.Now().UTC()
+
+	if err := s.storage.Save(t); err != nil {
+		return nil, err
+	}
+
+	s.index.Set(t)
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// applyChanges validates and applies field changes to a task in memory
+func (s *Service) applyChanges(t *Task, title, description *string, status *Status, priority *Priority, taskType *string) error {
 	if title != nil {
 		if *title == "" {
-			return nil, fmt.Errorf("title cannot be empty")
+			return fmt.Errorf("title cannot be empty")
 		}
 		t.Title = *title
 	}
@@ -222,38 +315,118 @@ func (s *Service) Update(id int, title, description *string, status *Status, pri
 	}
 	if status != nil {
 		if !IsValidStatus(string(*status)) {
-			return nil, fmt.Errorf("invalid status: %s", *status)
+			return fmt.Errorf("invalid status: %s", *status)
 		}
-		t.Status = *status
+		if *status != t.Status {
+			t.Resolution = ""
+		}
+		t.setStatus(*status, time.Now().UTC())
 	}
 	if priority != nil {
 		if !IsValidPriority(string(*priority)) {
-			return nil, fmt.Errorf("invalid priority: %s", *priority)
+			return fmt.Errorf("invalid priority: %s", *priority)
 		}
 		t.Priority = *priority
 	}
 	if taskType != nil {
 		if !s.isValidType(*taskType) {
-			return nil, fmt.Errorf("invalid task type: %s", *taskType)
+			return fmt.Errorf("invalid task type: %s", *taskType)
 		}
 		t.Type = *taskType
 	}
+	return nil
+}
 
-	t.UpdatedAt = time.Now().UTC()
+// BatchUpdate applies the same field changes to several tasks. All tasks are
+// loaded and validated before any file is written, and the index is saved once.
+// Status changes are validated and recorded as in Update, taking the other
+// tasks of the batch into account.
+func (s *Service) BatchUpdate(ids []int, description *string, status *Status, priority *Priority, taskType *string, force bool) ([]*Task, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no tasks selected for update")
+	}
+	if description == nil && status == nil && priority == nil && taskType == nil {
+		return nil, fmt.Errorf("no changes given")
+	}
 
-	if err := s.storage.Save(t); err != nil {
-		return nil, err
+	var pending map[int]Status
+	if status != nil {
+		pending = make(map[int]Status)
+		for _, id := range ids {
+			pending[id] = *status
+		}
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[int]bool)
+	var tasks []*Task
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		loaded, err := s.activeTask(id)
+		if err != nil {
+			return nil, err
+		}
+		// Work on a copy so a failed validation leaves nothing modified
+		t := *loaded
+		from := t.Status
+		if status != nil && !force && IsValidStatus(string(*status)) {
+			if err := s.checkStatusChange(&t, *status, pending); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.applyChanges(&t, nil, description, status, priority, taskType); err != nil {
+			return nil, fmt.Errorf("task %d: %w", id, err)
+		}
+		if force && t.Status != from {
+			t.History = append(slices.Clone(t.History), StatusChange{Action: ActionForce, From: from, To: t.Status, At: now})
+		}
+		tasks = append(tasks, &t)
+	}
+
+	for _, t := range tasks {
+		t.UpdatedAt = now
+		if err := s.storage.Save(t); err != nil {
+			// Keep the index consistent with the files written so far
+			_ = s.index.Save()
+			return nil, fmt.Errorf("failed to save task %d: %w", t.ID, err)
+		}
+		s.index.Set(t)
 	}
 
-	s.index.Set(t)
 	if err := s.index.Save(); err != nil {
 		return nil, err
 	}
 
-	return t, nil
+	if status != nil && !force {
+		synced := make(map[int]bool)
+		for _, t := range tasks {
+			if t.ParentID == nil || seen[*t.ParentID] || synced[*t.ParentID] {
+				continue
+			}
+			synced[*t.ParentID] = true
+			if _, err := s.syncParentStatus(*t.ParentID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tasks, nil
 }
 
-// Delete removes a task
+// FindTasks returns the IDs of all active tasks matching the filter
+func (s *Service) FindTasks(f *Filter) []int {
+	var ids []int
+	for _, t := range s.index.Filter(f.Status, f.Priority, f.Type, f.ParentID) {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+// Delete removes a task and the relations active tasks have to it. Archived
+// tasks are not rewritten: their relations to the deleted task stay in the
+// archive until UnarchiveTask drops them.
 func (s *Service) Delete(id int, deleteSubtasks bool) error {
 	t, err := s.Get(id)
 	if err != nil {
@@ -268,8 +441,10 @@ func (s *Service) Delete(id int, deleteSubtasks bool) error {
 		}
 
 		// Delete all subtasks first
-		subtasks := s.index.GetSubtasks(id)
-		for _, sub := range subtasks {
+		for _, sub := range s.Descendants(id) {
+			if err := s.updateAffectedRelationTasks(sub.ID, s.index.RemoveAllRelationsForTask(sub.ID)); err != nil {
+				return err
+			}
 			if err := s.storage.Delete(sub.ID); err != nil {
 				return fmt.Errorf("failed to delete subtask %d: %w", sub.ID, err)
 			}
@@ -277,35 +452,10 @@ func (s *Service) Delete(id int, deleteSubtasks bool) error {
 		}
 	}
 
-	// Remove all relations referencing this task
-	removedEdges := s.index.RemoveAllRelationsForTask(id)
-
-	// Update other tasks' frontmatter to remove relations pointing to this task
-	affectedTasks := make(map[int]bool)
-	for _, edge := range removedEdges {
-		// Only update frontmatter for edges where the other task is the source
-		// (relations are stored in the source task's frontmatter)
-		if edge.Source != id {
-			affectedTasks[edge.Source] = true
-		}
-	}
-	for affectedID := range affectedTasks {
-		affected, err := s.Get(affectedID)
-		if err != nil {
-			continue
-		}
-		var newRelations []Relation
-		for _, rel := range affected.Relations {
-			if rel.Task != id {
-				newRelations = append(newRelations, rel)
-			}
-		}
-		affected.Relations = newRelations
-		affected.UpdatedAt = time.Now().UTC()
-		if err := s.storage.Save(affected); err != nil {
-			return fmt.Errorf("failed to update relations in task %d: %w", affectedID, err)
-		}
-		s.index.Set(affected)
+	// Remove all relations referencing this task, also from other tasks'
+	// frontmatter; archiving keeps them, only deleting cleans them up
+	if err := s.updateAffectedRelationTasks(id, s.index.RemoveAllRelationsForTask(id)); err != nil {
+		return err
 	}
 
 	if err := s.storage.Delete(t.ID); err != nil {
@@ -322,6 +472,17 @@ func (s *Service) List(status *Status, priority *Priority, taskType *string, par
 	return s.index.Filter(status, priority, taskType, parentID)
 }
 
+// AllTasks returns all active tasks with descriptions loaded from disk, sorted by ID
+func (s *Service) AllTasks() []*Task {
+	var tasks []*Task
+	for _, entry := range s.index.All() {
+		if t, ok := s.index.Get(entry.ID); ok {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
 // GetNextTask returns the highest priority todo task
 // Note: Task returned does not include description (use Get to load full task data)
 func (s *Service) GetNextTask() *Task {
@@ -330,7 +491,7 @@ func (s *Service) GetNextTask() *Task {
 
 // StartTask moves a task from todo to in_progress
 func (s *Service) StartTask(id int) (*Task, error) {
-	t, err := s.Get(id)
+	t, err := s.activeTask(id)
 	if err != nil {
 		return nil, err
 	}
@@ -341,34 +502,30 @@ func (s *Service) StartTask(id int) (*Task, error) {
 
 	// Check if task is blocked
 	if blocked, blockers := s.IsBlocked(id); blocked {
-		var parts []string
-		for _, b := range blockers {
-			parts = append(parts, fmt.Sprintf("%d (%s)", b.TaskID, b.Status))
-		}
-		return nil, fmt.Errorf("task %d is blocked by tasks: %s", id, strings.Join(parts, ", "))
+		return nil, blockedError(id, blockers)
 	}
 
-	// Auto-start parent if this is a subtask
-	if t.ParentID != nil {
-		parent, err := s.Get(*t.ParentID)
+	// Auto-start all ancestors that are still todo
+	for _, ancestorID := range s.ancestorIDs(t) {
+		ancestor, err := s.activeTask(ancestorID)
 		if err != nil {
-			return nil, fmt.Errorf("parent task not found: %d", *t.ParentID)
+			return nil, fmt.Errorf("parent task not found: %d", ancestorID)
 		}
-		if parent.Status == StatusTodo {
+		if ancestor.Status == StatusTodo {
 			status := StatusInProgress
-			if _, err := s.Update(*t.ParentID, nil, nil, &status, nil, nil); err != nil {
+			if _, err := s.update(ancestorID, nil, nil, &status, nil, nil); err != nil {
 				return nil, fmt.Errorf("failed to start parent task: %w", err)
 			}
 		}
 	}
 
 	status := StatusInProgress
-	return s.Update(id, nil, nil, &status, nil, nil)
+	return s.update(id, nil, nil, &status, nil, nil)
 }
 
 // CompleteTask moves a task from in_progress to done
 func (s *Service) CompleteTask(id int) (*Task, error) {
-	t, err := s.Get(id)
+	t, err := s.activeTask(id)
 	if err != nil {
 		return nil, err
 	}
@@ -377,10 +534,9 @@ func (s *Service) CompleteTask(id int) (*Task, error) {
 		return nil, fmt.Errorf("task %d is not in progress (current: %s)", id, t.Status)
 	}
 
-	// Check if this task has incomplete subtasks
-	subtasks := s.index.GetSubtasks(id)
+	// Check if this task has incomplete subtasks at any level
 	incompleteCount := 0
-	for _, sub := range subtasks {
+	for _, sub := range s.Descendants(id) {
 		if sub.Status != StatusDone {
 			incompleteCount++
 		}
@@ -391,25 +547,25 @@ func (s *Service) CompleteTask(id int) (*Task, error) {
 
 	// Complete this task
 	status := StatusDone
-	completed, err := s.Update(id, nil, nil, &status, nil, nil)
+	completed, err := s.update(id, nil, nil, &status, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// If this is a subtask, check if all siblings are done -> auto-complete parent
-	if t.ParentID != nil {
-		siblings := s.index.GetSubtasks(*t.ParentID)
+	// Walk up the hierarchy: each ancestor whose subtasks are all done is auto-completed
+	for _, ancestorID := range s.ancestorIDs(t) {
 		allDone := true
-		for _, sib := range siblings {
+		for _, sib := range s.index.GetSubtasks(ancestorID) {
 			if sib.Status != StatusDone {
 				allDone = false
 				break
 			}
 		}
-		if allDone {
-			if _, err := s.Update(*t.ParentID, nil, nil, &status, nil, nil); err != nil {
-				return nil, fmt.Errorf("failed to auto-complete parent: %w", err)
-			}
+		if !allDone {
+			break
+		}
+		if _, err := s.update(ancestorID, nil, nil, &status, nil, nil); err != nil {
+			return nil, fmt.Errorf("failed to auto-complete parent: %w", err)
 		}
 	}
 
@@ -418,40 +574,113 @@ func (s *Service) CompleteTask(id int) (*Task, error) {
 
 // BlockingInfo describes a task that is blocking another
 type BlockingInfo struct {
-	TaskID int    `json:"task_id"`
-	Status Status `json:"status"`
-	Title  string `json:"title"`
+	TaskID  int    `json:"task_id"`
+	Project string `json:"project,omitempty"` // Workspace project of a blocker in another project
+	Status  Status `json:"status"`
+	Title   string `json:"title"`
 }
 
-// AddRelation adds a relation between two tasks
-func (s *Service) AddRelation(source int, relationType string, target int) error {
+// Ref returns the blocker as a reference: #42, or api#42 in another project
+func (b BlockingInfo) Ref() string {
+	return Relation{Task: b.TaskID, Project: b.Project}.Ref()
+}
+
+// resolveRelation looks up a relation type by name. Inverse names are mapped
+// onto their canonical type with source and target swapped, so "3 blocks 5"
+// is stored as "5 blocked_by 3".
+func (s *Service) resolveRelation(source int, name string, target int) (config.RelationType, int, int, error) {
+	cfg := s.config
+	if cfg == nil {
+		cfg = &config.Config{RelationTypes: config.DefaultRelationTypes}
+	}
+	rt, inverse, ok := cfg.LookupRelationType(name)
+	if !ok {
+		if s.config != nil {
+			return rt, 0, 0, fmt.Errorf("invalid relation type: %s", name)
+		}
+		rt = config.RelationType{Name: name}
+	}
+	if inverse {
+		source, target = target, source
+	}
+	return rt, source, target, nil
+}
+
+// isAllowedTaskType reports whether taskType is in allowed (empty = any)
+func isAllowedTaskType(allowed []string, taskType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, t := range allowed {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRelation validates a new relation and returns its resolved type and
+// the tasks it would be stored on and point to
+func (s *Service) checkRelation(source int, relationType string, target int) (config.RelationType, *Task, *Task, error) {
 	// Validate no self-reference
 	if source == target {
-		return fmt.Errorf("cannot create relation: source and target are the same task (%d)", source)
+		return config.RelationType{}, nil, nil, fmt.Errorf("cannot create relation: source and target are the same task (%d)", source)
 	}
 
 	// Validate relation type
-	if s.config != nil && !s.config.IsValidRelationType(relationType) {
-		return fmt.Errorf("invalid relation type: %s", relationType)
+	rt, source, target, err := s.resolveRelation(source, relationType, target)
+	if err != nil {
+		return rt, nil, nil, err
 	}
+	relationType = rt.Name
 
 	// Validate source task exists
 	srcTask, err := s.Get(source)
 	if err != nil {
-		return fmt.Errorf("source task not found: %d", source)
+		return rt, nil, nil, fmt.Errorf("source task not found: %d", source)
+	}
+	if err := s.checkNotArchived(source); err != nil {
+		return rt, nil, nil, err
 	}
 
 	// Validate target task exists
-	if _, err := s.Get(target); err != nil {
-		return fmt.Errorf("target task not found: %d", target)
+	tgtTask, err := s.Get(target)
+	if err != nil {
+		return rt, nil, nil, fmt.Errorf("target task not found: %d", target)
+	}
+
+	// Validate task types the relation type is restricted to
+	if !isAllowedTaskType(rt.SourceTypes, srcTask.Type) {
+		return rt, nil, nil, fmt.Errorf("cannot create relation: %s is not allowed from %s task %d (allowed source types: %s)", relationType, srcTask.Type, source, strings.Join(rt.SourceTypes, ", "))
+	}
+	if !isAllowedTaskType(rt.TargetTypes, tgtTask.Type) {
+		return rt, nil, nil, fmt.Errorf("cannot create relation: %s is not allowed to %s task %d (allowed target types: %s)", relationType, tgtTask.Type, target, strings.Join(rt.TargetTypes, ", "))
 	}
 
 	// Check for duplicate
-	for _, rel := range srcTask.Relations {
-		if rel.Type == relationType && rel.Task == target {
-			return fmt.Errorf("relation already exists: %s from %d to %d", relationType, source, target)
+	if hasRelation(srcTask, relationType, target) {
+		return rt, nil, nil, fmt.Errorf("relation already exists: %s from %d to %d", relationType, source, target)
+	}
+	if rt.Symmetric && hasRelation(tgtTask, relationType, source) {
+		return rt, nil, nil, fmt.Errorf("relation already exists: %s from %d to %d", relationType, target, source)
+	}
+
+	// Reject blocking relations that would make tasks wait for each other forever
+	if rt.Blocking {
+		if err := s.checkBlockingCycle(source, target); err != nil {
+			return rt, nil, nil, fmt.Errorf("cannot create relation: %w", err)
 		}
 	}
+	return rt, srcTask, tgtTask, nil
+}
+
+// AddRelation adds a relation between two tasks
+func (s *Service) AddRelation(source int, relationType string, target int) error {
+	rt, srcTask, tgtTask, err := s.checkRelation(source, relationType, target)
+	if err != nil {
+		return err
+	}
+	relationType, source, target = rt.Name, srcTask.ID, tgtTask.ID
 
 	// Ensure directory exists for write operation
 	if err := s.storage.EnsureDir(); err != nil {
@@ -476,16 +705,29 @@ func (s *Service) AddRelation(source int, relationType string, target int) error
 
 // RemoveRelation removes a relation between two tasks
 func (s *Service) RemoveRelation(source int, relationType string, target int) error {
+	rt, source, target, err := s.resolveRelation(source, relationType, target)
+	if err != nil {
+		return err
+	}
+	relationType = rt.Name
+
 	srcTask, err := s.Get(source)
 	if err != nil {
 		return fmt.Errorf("source task not found: %d", source)
 	}
 
+	// A symmetric relation is stored on whichever task it was added from
+	if rt.Symmetric && !hasRelation(srcTask, relationType, target) {
+		if tgtTask, err := s.Get(target); err == nil && hasRelation(tgtTask, relationType, source) {
+			srcTask, source, target = tgtTask, target, source
+		}
+	}
+
 	// Find and remove the relation from frontmatter
 	found := false
 	var newRelations []Relation
 	for _, rel := range srcTask.Relations {
-		if rel.Type == relationType && rel.Task == target {
+		if rel.Type == relationType && rel.Task == target && rel.Project == "" {
 			found = true
 			continue
 		}
@@ -495,6 +737,9 @@ func (s *Service) RemoveRelation(source int, relationType string, target int) er
 	if !found {
 		return fmt.Errorf("relation not found: %s from %d to %d", relationType, source, target)
 	}
+	if err := s.checkNotArchived(source); err != nil {
+		return err
+	}
 
 	srcTask.Relations = newRelations
 	srcTask.UpdatedAt = time.Now().UTC()
@@ -511,13 +756,28 @@ func (s *Service) RemoveRelation(source int, relationType string, target int) er
 	return s.index.Save()
 }
 
-// IsBlocked checks if a task has unresolved blocked_by relations
-func (s *Service) IsBlocked(taskID int) (bool, []BlockingInfo) {
-	blockerIDs := s.index.GetBlockers(taskID)
-	if len(blockerIDs) == 0 {
-		return false, nil
+// checkNotArchived rejects changing the relations stored on an archived task
+func (s *Service) checkNotArchived(id int) error {
+	if s.archiveStorage != nil && s.archiveStorage.IsArchived(id) {
+		return fmt.Errorf("task %d is archived; unarchive it to change its relations", id)
 	}
+	return nil
+}
 
+// hasRelation reports whether t has a relation of the given type to target
+// in its own project
+func hasRelation(t *Task, relationType string, target int) bool {
+	for _, rel := range t.Relations {
+		if rel.Type == relationType && rel.Task == target && rel.Project == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBlocked checks if a task has unresolved blocking relations
+func (s *Service) IsBlocked(taskID int) (bool, []BlockingInfo) {
+	blockerIDs := s.index.GetBlockers(taskID)
 	var blockers []BlockingInfo
 	for _, id := range blockerIDs {
 		t, ok := s.index.Get(id)
@@ -532,6 +792,7 @@ func (s *Service) IsBlocked(taskID int) (bool, []BlockingInfo) {
 			})
 		}
 	}
+	blockers = append(blockers, s.projectBlockers(taskID)...)
 
 	return len(blockers) > 0, blockers
 }
@@ -550,18 +811,18 @@ func (s *Service) ArchiveTask(id int) error {
 	}
 
 	// If the task has subtasks, verify all are done and archive them first
+	leaving := map[int]bool{id: true}
 	if s.index.HasSubtasks(id) {
-		subtasks := s.index.GetSubtasks(id)
+		subtasks := s.Descendants(id)
 		for _, sub := range subtasks {
 			if sub.Status != StatusDone {
 				return fmt.Errorf("cannot archive task %d: subtask %d is not done (current: %s)", id, sub.ID, sub.Status)
 			}
+			leaving[sub.ID] = true
 		}
 		// Archive all subtasks first
 		for _, sub := range subtasks {
-			// Clean relations for each subtask
-			removedEdges := s.index.RemoveAllRelationsForTask(sub.ID)
-			if err := s.updateAffectedRelationTasks(sub.ID, removedEdges); err != nil {
+			if err := s.removeOwnEdges(sub.ID, leaving); err != nil {
 				return err
 			}
 			if err := s.archiveStorage.Archive(sub.ID); err != nil {
@@ -571,9 +832,7 @@ func (s *Service) ArchiveTask(id int) error {
 		}
 	}
 
-	// Clean relations for the main task
-	removedEdges := s.index.RemoveAllRelationsForTask(id)
-	if err := s.updateAffectedRelationTasks(id, removedEdges); err != nil {
+	if err := s.removeOwnEdges(id, leaving); err != nil {
 		return err
 	}
 
@@ -586,6 +845,50 @@ func (s *Service) ArchiveTask(id int) error {
 	return s.index.Save()
 }
 
+// removeOwnEdges removes the index edges of a task's own relations before it
+// leaves the active tasks together with the tasks in leaving. Relations other
+// tasks have to it are kept: an archived task stays a valid relation target
+// and counts as done. Symmetric relations to tasks staying active move to
+// those tasks, so both ends keep the link and it can still be removed.
+func (s *Service) removeOwnEdges(id int, leaving map[int]bool) error {
+	t, err := s.storage.Load(id)
+	if err != nil {
+		return err
+	}
+	var kept []Relation
+	for _, rel := range t.Relations {
+		if rel.Project != "" {
+			kept = append(kept, rel)
+			continue
+		}
+		if other, ok := s.index.Get(rel.Task); ok && !leaving[rel.Task] && s.isSymmetric(rel.Type) {
+			if !hasRelation(other, rel.Type, id) {
+				other.Relations = append(other.Relations, Relation{Type: rel.Type, Task: id})
+				other.UpdatedAt = time.Now().UTC()
+				if err := s.storage.Save(other); err != nil {
+					return fmt.Errorf("failed to move relation to task %d: %w", other.ID, err)
+				}
+				s.index.Set(other)
+			}
+			continue // The index edges in both directions stay
+		}
+		s.index.RemoveRelation(RelationEdge{Type: rel.Type, Source: id, Target: rel.Task})
+		kept = append(kept, rel)
+	}
+	if len(kept) == len(t.Relations) {
+		return nil
+	}
+	t.Relations = kept
+	t.UpdatedAt = time.Now().UTC()
+	return s.storage.Save(t)
+}
+
+// isSymmetric reports whether a relation type holds in both directions
+func (s *Service) isSymmetric(relationType string) bool {
+	rt, _, _, err := s.resolveRelation(0, relationType, 0)
+	return err == nil && rt.Symmetric
+}
+
 // updateAffectedRelationTasks updates frontmatter of tasks whose relations pointed to taskID
 func (s *Service) updateAffectedRelationTasks(taskID int, removedEdges []RelationEdge) error {
 	affectedTasks := make(map[int]bool)
@@ -601,7 +904,7 @@ func (s *Service) updateAffectedRelationTasks(taskID int, removedEdges []Relatio
 		}
 		var newRelations []Relation
 		for _, rel := range affected.Relations {
-			if rel.Task != taskID {
+			if rel.Task != taskID || rel.Project != "" {
 				newRelations = append(newRelations, rel)
 			}
 		}
@@ -615,55 +918,29 @@ func (s *Service) updateAffectedRelationTasks(taskID int, removedEdges []Relatio
 	return nil
 }
 
-// GetAutoArchiveCandidates returns done tasks that are eligible for auto-archiving
-func (s *Service) GetAutoArchiveCandidates() []*Task {
-	if s.config == nil {
-		return nil
-	}
-	threshold := time.Now().UTC().AddDate(0, 0, -s.config.AutoArchive.AfterDays)
-
-	doneTasks := s.index.Filter(&[]Status{StatusDone}[0], nil, nil, nil)
-	var candidates []*Task
-	for _, t := range doneTasks {
-		if t.UpdatedAt.After(threshold) {
-			continue
-		}
-		// Only return top-level tasks or subtasks whose parent is also done
-		if t.ParentID != nil {
-			parent, ok := s.index.Get(*t.ParentID)
-			if !ok || parent.Status != StatusDone {
-				continue
-			}
-		}
-		candidates = append(candidates, t)
+// ListArchived returns archived tasks matching the filters, sorted by ID.
+// parentID: nil = all tasks, 0 = top-level only, >0 = subtasks of that parent.
+// Tasks returned do not include descriptions.
+func (s *Service) ListArchived(status *Status, priority *Priority, taskType *string, parentID *int) ([]*Task, error) {
+	if s.archiveStorage == nil {
+		return nil, fmt.Errorf("archive storage not available")
 	}
-	return candidates
+	return s.archiveStorage.FilterArchived(status, priority, taskType, parentID)
 }
 
-// RunAutoArchive archives all eligible candidates; skips individual failures
-func (s *Service) RunAutoArchive() error {
-	if s.config == nil || !s.config.AutoArchive.Enabled {
-		return nil
+// AllArchived returns all archived tasks with descriptions loaded from disk, sorted by ID
+func (s *Service) AllArchived() ([]*Task, error) {
+	entries, err := s.ListArchived(nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
 	}
-	candidates := s.GetAutoArchiveCandidates()
-	for _, t := range candidates {
-		// Skip tasks already archived (may have been archived as subtasks)
-		if _, ok := s.index.Get(t.ID); !ok {
-			continue
-		}
-		if err := s.ArchiveTask(t.ID); err != nil {
-			log.Printf("auto-archive: failed to archive task %d: %v", t.ID, err)
+	var tasks []*Task
+	for _, entry := range entries {
+		if t, err := s.archiveStorage.LoadArchived(entry.ID); err == nil {
+			tasks = append(tasks, t)
 		}
 	}
-	return nil
-}
-
-// ListArchived returns all archived tasks (linear scan of archive directory)
-func (s *Service) ListArchived() ([]*Task, error) {
-	if s.archiveStorage == nil {
-		return nil, fmt.Errorf("archive storage not available")
-	}
-	return s.archiveStorage.LoadAllArchived()
+	return tasks, nil
 }
 
 // isValidType checks if task type is valid
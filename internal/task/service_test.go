@@ -2,6 +2,8 @@ package task
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,10 +15,11 @@ import (
 type mockArchiveStorage struct {
 	active   *mockStorage
 	archived map[int]*Task
+	stripped map[int][]RelationEdge // Relations removed by earlier versions, returned by Unarchive
 }
 
 func newMockArchiveStorage(active *mockStorage) *mockArchiveStorage {
-	return &mockArchiveStorage{active: active, archived: make(map[int]*Task)}
+	return &mockArchiveStorage{active: active, archived: make(map[int]*Task), stripped: make(map[int][]RelationEdge)}
 }
 
 func (m *mockArchiveStorage) Archive(id int) error {
@@ -29,6 +32,21 @@ func (m *mockArchiveStorage) Archive(id int) error {
 	return nil
 }
 
+func (m *mockArchiveStorage) Unarchive(id int) ([]RelationEdge, error) {
+	t, ok := m.archived[id]
+	if !ok {
+		return nil, fmt.Errorf("archived task not found: %d", id)
+	}
+	if _, exists := m.active.tasks[id]; exists {
+		return nil, fmt.Errorf("active task %d already exists", id)
+	}
+	m.active.tasks[id] = t
+	stripped := m.stripped[id]
+	delete(m.archived, id)
+	delete(m.stripped, id)
+	return stripped, nil
+}
+
 func (m *mockArchiveStorage) LoadArchived(id int) (*Task, error) {
 	t, ok := m.archived[id]
 	if !ok {
@@ -37,11 +55,29 @@ func (m *mockArchiveStorage) LoadArchived(id int) (*Task, error) {
 	return t, nil
 }
 
-func (m *mockArchiveStorage) LoadAllArchived() ([]*Task, error) {
-	result := make([]*Task, 0, len(m.archived))
+func (m *mockArchiveStorage) FilterArchived(status *Status, priority *Priority, taskType *string, parentID *int) ([]*Task, error) {
+	var result []*Task
 	for _, t := range m.archived {
+		if status != nil && t.Status != *status {
+			continue
+		}
+		if priority != nil && t.Priority != *priority {
+			continue
+		}
+		if taskType != nil && t.Type != *taskType {
+			continue
+		}
+		if parentID != nil {
+			if *parentID == 0 && t.ParentID != nil {
+				continue
+			}
+			if *parentID != 0 && (t.ParentID == nil || *t.ParentID != *parentID) {
+				continue
+			}
+		}
 		result = append(result, t)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 	return result, nil
 }
 
@@ -50,6 +86,14 @@ func (m *mockArchiveStorage) IsArchived(id int) bool {
 	return ok
 }
 
+func (m *mockArchiveStorage) NextID() int {
+	maxID := 0
+	for id := range m.archived {
+		maxID = max(maxID, id)
+	}
+	return maxID + 1
+}
+
 // mockStorage implements Storage interface for testing
 type mockStorage struct {
 	tasks map[int]*Task
@@ -83,6 +127,7 @@ type mockIndex struct {
 	nextID            int
 	relationsBySource map[int][]RelationEdge
 	relationsByTarget map[int][]RelationEdge
+	saves             int
 }
 
 func newMockIndex() *mockIndex {
@@ -95,7 +140,10 @@ func newMockIndex() *mockIndex {
 }
 
 func (m *mockIndex) Load() error { return nil }
-func (m *mockIndex) Save() error { return nil }
+func (m *mockIndex) Save() error {
+	m.saves++
+	return nil
+}
 
 func (m *mockIndex) Get(id int) (*Task, bool) {
 	t, ok := m.tasks[id]
@@ -189,7 +237,9 @@ func (m *mockIndex) HasSubtasks(taskID int) bool {
 func (m *mockIndex) SubtaskCounts(parentID int) (total int, done int) {
 	for _, t := range m.tasks {
 		if t.ParentID != nil && *t.ParentID == parentID {
-			total++
+			subTotal, subDone := m.SubtaskCounts(t.ID)
+			total += 1 + subTotal
+			done += subDone
 			if t.Status == StatusDone {
 				done++
 			}
@@ -261,6 +311,25 @@ func (m *mockIndex) GetBlockers(taskID int) []int {
 	return blockers
 }
 
+func (m *mockIndex) GetDependents(taskID int) []int {
+	var dependents []int
+	for _, e := range m.relationsByTarget[taskID] {
+		if e.Type == "blocked_by" {
+			dependents = append(dependents, e.Source)
+		}
+	}
+	return dependents
+}
+
+func (m *mockIndex) DuplicateOf(taskID int) (int, bool) {
+	for _, e := range m.relationsBySource[taskID] {
+		if e.Type == "duplicate_of" {
+			return e.Target, true
+		}
+	}
+	return 0, false
+}
+
 func (m *mockIndex) RemoveAllRelationsForTask(taskID int) []RelationEdge {
 	var removed []RelationEdge
 	for _, e := range m.relationsBySource[taskID] {
@@ -290,6 +359,20 @@ func (m *mockIndex) RemoveAllRelationsForTask(taskID int) []RelationEdge {
 	return removed
 }
 
+func (m *mockIndex) ProjectRelations(taskID int) []Relation {
+	var result []Relation
+	if t, ok := m.tasks[taskID]; ok {
+		for _, rel := range t.Relations {
+			if rel.Project != "" {
+				result = append(result, rel)
+			}
+		}
+	}
+	return result
+}
+
+func (m *mockIndex) SetProjectBlocker(blocking func(rel Relation) bool) {}
+
 func TestService_Create(t *testing.T) {
 	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
 	if err := svc.Initialize(); err != nil {
@@ -390,7 +473,7 @@ func TestService_Update(t *testing.T) {
 
 	newTitle := "Updated"
 	newStatus := StatusInProgress
-	updated, err := svc.Update(task.ID, &newTitle, nil, &newStatus, nil, nil)
+	updated, err := svc.Update(task.ID, &newTitle, nil, &newStatus, nil, nil, false)
 	if err != nil {
 		t.Fatalf("Update() error = %v", err)
 	}
@@ -557,10 +640,19 @@ func TestService_CreateSubtask_NestedSubtask(t *testing.T) {
 	parent, _ := svc.Create("Parent", "Desc", PriorityHigh, "feature", nil)
 	subtask, _ := svc.CreateSubtask("Subtask", "Desc", PriorityMedium, "feature", parent.ID)
 
-	// Try to create subtask under subtask (should fail - single level only)
-	_, err := svc.CreateSubtask("Nested", "Desc", PriorityLow, "feature", subtask.ID)
-	if err == nil {
-		t.Error("CreateSubtask() under subtask should fail")
+	// Nesting up to the default maximum depth is allowed
+	nested, err := svc.CreateSubtask("Nested", "Desc", PriorityLow, "feature", subtask.ID)
+	if err != nil {
+		t.Fatalf("CreateSubtask() under subtask error = %v", err)
+	}
+	if depth := svc.Depth(nested.ID); depth != 3 {
+		t.Errorf("Depth() = %d, want 3", depth)
+	}
+
+	// One level more exceeds it
+	_, err = svc.CreateSubtask("Too deep", "Desc", PriorityLow, "feature", nested.ID)
+	if err == nil || !strings.Contains(err.Error(), "maximum depth") {
+		t.Errorf("CreateSubtask() beyond max depth error = %v, want maximum depth error", err)
 	}
 }
 
@@ -1094,6 +1186,82 @@ func TestSubtaskLifecycle(t *testing.T) {
 	t.Log("Subtask lifecycle integration test completed successfully")
 }
 
+func TestService_BatchUpdate(t *testing.T) {
+	idx := newMockIndex()
+	svc := NewService(newMockStorage(), nil, idx, []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	t1, _ := svc.Create("One", "", PriorityLow, "feature", nil)
+	t2, _ := svc.Create("Two", "", PriorityMedium, "feature", nil)
+	t3, _ := svc.Create("Three", "", PriorityLow, "feature", nil)
+
+	savesBefore := idx.saves
+	priority := PriorityCritical
+	taskType := "bug"
+	updated, err := svc.BatchUpdate([]int{t1.ID, t2.ID, t1.ID}, nil, nil, &priority, &taskType, false)
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+	if len(updated) != 2 {
+		t.Errorf("len(updated) = %d, want 2 (duplicates ignored)", len(updated))
+	}
+	if idx.saves-savesBefore != 1 {
+		t.Errorf("index saved %d times, want 1", idx.saves-savesBefore)
+	}
+	for _, id := range []int{t1.ID, t2.ID} {
+		got, _ := svc.Get(id)
+		if got.Priority != PriorityCritical || got.Type != "bug" {
+			t.Errorf("task %d = %s/%s, want critical/bug", id, got.Priority, got.Type)
+		}
+	}
+	got, _ := svc.Get(t3.ID)
+	if got.Priority != PriorityLow {
+		t.Errorf("task %d priority = %s, want unchanged low", t3.ID, got.Priority)
+	}
+}
+
+func TestService_BatchUpdate_ValidatesAllFirst(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	t1, _ := svc.Create("One", "", PriorityLow, "feature", nil)
+
+	priority := PriorityHigh
+	if _, err := svc.BatchUpdate([]int{t1.ID, 99}, nil, nil, &priority, nil, false); err == nil {
+		t.Fatal("BatchUpdate() with unknown ID should fail")
+	}
+	got, _ := svc.Get(t1.ID)
+	if got.Priority != PriorityLow {
+		t.Errorf("task %d priority = %s, want unchanged low after failed batch", t1.ID, got.Priority)
+	}
+
+	if _, err := svc.BatchUpdate([]int{t1.ID}, nil, nil, nil, nil, false); err == nil {
+		t.Error("BatchUpdate() without changes should fail")
+	}
+	invalid := Priority("urgent")
+	if _, err := svc.BatchUpdate([]int{t1.ID}, nil, nil, &invalid, nil, false); err == nil {
+		t.Error("BatchUpdate() with invalid priority should fail")
+	}
+}
+
+func TestService_FindTasks(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	svc.Create("One", "", PriorityLow, "feature", nil)
+	t2, _ := svc.Create("Two", "", PriorityLow, "bug", nil)
+	svc.Create("Three", "", PriorityHigh, "bug", nil)
+
+	filter, err := ParseFilter("priority=low,type=bug")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	ids := svc.FindTasks(filter)
+	if len(ids) != 1 || ids[0] != t2.ID {
+		t.Errorf("FindTasks() = %v, want [%d]", ids, t2.ID)
+	}
+}
+
 // === Relation Service Tests ===
 
 func TestService_AddRelation(t *testing.T) {
@@ -1293,6 +1461,29 @@ func TestService_Delete_CascadesRelations(t *testing.T) {
 	}
 }
 
+func TestService_Delete_CascadesSubtaskRelations(t *testing.T) {
+	cfg := &config.Config{
+		TaskTypes:     []string{"feature", "bug"},
+		RelationTypes: config.DefaultRelationTypes,
+	}
+	svc := NewService(newMockStorage(), nil, newMockIndex(), cfg.TaskTypes, cfg)
+	svc.Initialize()
+
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	sub, _ := svc.CreateSubtask("Sub", "", PriorityHigh, "feature", parent.ID)
+	other, _ := svc.Create("Other", "", PriorityHigh, "feature", nil)
+	svc.AddRelation(other.ID, "blocked_by", sub.ID)
+
+	if err := svc.Delete(parent.ID, true); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	got, _ := svc.Get(other.ID)
+	if len(got.Relations) != 0 {
+		t.Errorf("other should have no relations after deleting the subtask, got %v", got.Relations)
+	}
+}
+
 // === Archive Service Tests ===
 
 // newServiceWithArchive is a helper that creates a service with both storage and archive storage
@@ -1404,7 +1595,7 @@ func TestService_ArchiveTask_WithSubtasks_SomeNotDone(t *testing.T) {
 	}
 }
 
-func TestService_ArchiveTask_CleansRelations(t *testing.T) {
+func TestService_ArchiveTask_KeepsRelations(t *testing.T) {
 	cfg := &config.Config{
 		TaskTypes:     []string{"feature", "bug"},
 		RelationTypes: config.DefaultRelationTypes,
@@ -1416,8 +1607,10 @@ func TestService_ArchiveTask_CleansRelations(t *testing.T) {
 
 	task1, _ := svc.Create("Blocker", "", PriorityHigh, "feature", nil)
 	task2, _ := svc.Create("Blocked", "", PriorityHigh, "feature", nil)
+	task3, _ := svc.Create("Related", "", PriorityHigh, "feature", nil)
 
 	svc.AddRelation(task2.ID, "blocked_by", task1.ID)
+	svc.AddRelation(task1.ID, "relates_to", task3.ID)
 
 	// Complete task1 and archive it
 	svc.StartTask(task1.ID)
@@ -1426,10 +1619,38 @@ func TestService_ArchiveTask_CleansRelations(t *testing.T) {
 		t.Fatalf("ArchiveTask() error = %v", err)
 	}
 
-	// task2's blocked_by relation should be cleaned up
+	// task2 keeps its blocked_by relation, resolved like a done task
 	t2, _ := svc.Get(task2.ID)
-	if len(t2.Relations) != 0 {
-		t.Errorf("task2 should have no relations after archiving blocker, got %v", t2.Relations)
+	if !hasRelation(t2, "blocked_by", task1.ID) {
+		t.Errorf("task2 should keep its relation to the archived task, got %v", t2.Relations)
+	}
+	if blocked, _ := svc.IsBlocked(task2.ID); blocked {
+		t.Error("task2 should not be blocked by an archived task")
+	}
+	refs := svc.RelationRefs(t2)
+	if len(refs) != 1 || !refs[0].Archived {
+		t.Errorf("RelationRefs() = %v, want the archived blocker marked", refs)
+	}
+
+	// A symmetric relation moves to the task staying active, which keeps
+	// the link and can still remove it
+	t3, _ := svc.Get(task3.ID)
+	if !hasRelation(t3, "relates_to", task1.ID) {
+		t.Errorf("task3 should take over the relates_to relation, got %v", t3.Relations)
+	}
+	if a1, _ := svc.Get(task1.ID); hasRelation(a1, "relates_to", task3.ID) {
+		t.Errorf("archived task1 should no longer store the relation, got %v", a1.Relations)
+	}
+	if edges := svc.index.GetRelationsForTask(task3.ID); len(edges) == 0 {
+		t.Error("task3 should keep its relates_to edge to the archived task")
+	}
+	if err := svc.RemoveRelation(task3.ID, "relates_to", task1.ID); err != nil {
+		t.Errorf("RemoveRelation() from the active end error = %v", err)
+	}
+
+	// Relations stored on the archived task cannot be changed
+	if err := svc.AddRelation(task1.ID, "blocked_by", task2.ID); err == nil {
+		t.Error("AddRelation() from an archived task should fail")
 	}
 }
 
@@ -1470,7 +1691,7 @@ func TestService_ListArchived(t *testing.T) {
 	svc.CompleteTask(task2.ID)
 	svc.ArchiveTask(task2.ID)
 
-	archived, err := svc.ListArchived()
+	archived, err := svc.ListArchived(nil, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("ListArchived() error = %v", err)
 	}
@@ -1544,9 +1765,13 @@ func TestService_RunAutoArchive_Disabled(t *testing.T) {
 	svc.StartTask(task1.ID)
 	svc.CompleteTask(task1.ID)
 
-	if err := svc.RunAutoArchive(); err != nil {
+	result, err := svc.RunAutoArchive()
+	if err != nil {
 		t.Fatalf("RunAutoArchive() error = %v", err)
 	}
+	if result != nil {
+		t.Errorf("RunAutoArchive() = %+v, want nil when disabled", result)
+	}
 
 	// Task should NOT be archived since auto-archive is disabled
 	if as.IsArchived(task1.ID) {
@@ -1588,3 +1813,57 @@ func TestService_Initialize_RunsAutoArchive(t *testing.T) {
 		t.Error("old done task should be auto-archived on Initialize()")
 	}
 }
+
+func TestService_AddRelation_ConfiguredTypes(t *testing.T) {
+	cfg := &config.Config{
+		TaskTypes: []string{"feature", "bug"},
+		RelationTypes: []config.RelationType{
+			{Name: "blocked_by", Blocking: true, Inverse: "blocks"},
+			{Name: "relates_to", Symmetric: true},
+			{Name: "duplicate_of", SourceTypes: []string{"bug"}, TargetTypes: []string{"bug"}},
+		},
+	}
+	svc := NewService(newMockStorage(), nil, newMockIndex(), cfg.TaskTypes, cfg)
+	svc.Initialize()
+
+	feature, _ := svc.Create("Feature", "", PriorityHigh, "feature", nil)
+	bug1, _ := svc.Create("Bug 1", "", PriorityHigh, "bug", nil)
+	bug2, _ := svc.Create("Bug 2", "", PriorityHigh, "bug", nil)
+
+	// Inverse names are stored as the canonical type with source and target swapped
+	if err := svc.AddRelation(bug1.ID, "blocks", feature.ID); err != nil {
+		t.Fatalf("AddRelation(blocks) error = %v", err)
+	}
+	got, _ := svc.Get(feature.ID)
+	if len(got.Relations) != 1 || got.Relations[0].Type != "blocked_by" || got.Relations[0].Task != bug1.ID {
+		t.Errorf("feature.Relations = %v, want blocked_by %d", got.Relations, bug1.ID)
+	}
+	if blocked, _ := svc.IsBlocked(feature.ID); !blocked {
+		t.Error("feature should be blocked")
+	}
+	if err := svc.RemoveRelation(bug1.ID, "blocks", feature.ID); err != nil {
+		t.Errorf("RemoveRelation(blocks) error = %v", err)
+	}
+
+	// Allowed source and target task types
+	if err := svc.AddRelation(feature.ID, "duplicate_of", bug1.ID); err == nil || !strings.Contains(err.Error(), "allowed source types: bug") {
+		t.Errorf("AddRelation(duplicate_of from feature) error = %v, want source type error", err)
+	}
+	if err := svc.AddRelation(bug2.ID, "duplicate_of", bug1.ID); err != nil {
+		t.Errorf("AddRelation(duplicate_of) error = %v", err)
+	}
+
+	// Symmetric relations count in both directions
+	if err := svc.AddRelation(bug1.ID, "relates_to", bug2.ID); err != nil {
+		t.Fatalf("AddRelation(relates_to) error = %v", err)
+	}
+	if err := svc.AddRelation(bug2.ID, "relates_to", bug1.ID); err == nil {
+		t.Error("AddRelation() should reject the reverse of a symmetric relation")
+	}
+	if err := svc.RemoveRelation(bug2.ID, "relates_to", bug1.ID); err != nil {
+		t.Errorf("RemoveRelation() from the other side error = %v", err)
+	}
+	if got, _ := svc.Get(bug1.ID); len(got.Relations) != 0 {
+		t.Errorf("bug1.Relations = %v, want none", got.Relations)
+	}
+}
@@ -0,0 +1,318 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Ways SplitTask can derive subtasks from a task's description
+const (
+	SplitByChecklist = "checklist" // One subtask per "- [ ] item" line
+	SplitBySections  = "sections"  // One subtask per "## Heading" section
+)
+
+// SplitOptions controls how SplitTask creates subtasks. Either Titles or By
+// must be set.
+type SplitOptions struct {
+	Titles []string // Explicit subtask titles
+	By     string   // SplitByChecklist or SplitBySections
+}
+
+// SplitResult describes the outcome of SplitTask
+type SplitResult struct {
+	Parent   *Task   `json:"parent"`
+	Subtasks []*Task `json:"subtasks"`
+}
+
+// MergeResult describes the outcome of MergeTasks
+type MergeResult struct {
+	Task      *Task          `json:"task"`
+	Merged    []int          `json:"merged"`                   // IDs folded into Task and deleted
+	Repointed []RelationEdge `json:"repointed,omitempty"`      // Relations moved to Task
+	Dropped   []RelationEdge `json:"dropped,omitempty"`        // Relations removed because Task already has them or they became invalid
+	Subtasks  []int          `json:"moved_subtasks,omitempty"` // Subtasks of merged tasks now under Task
+}
+
+var checklistItemPattern = regexp.MustCompile(`^\s*[-*] \[([ xX])\] (.+)$`)
+
+// splitPart is a subtask derived from a description
+type splitPart struct {
+	title       string
+	description string
+	done        bool
+}
+
+// splitChecklist extracts checklist items and returns the description without them
+func splitChecklist(description string) ([]splitPart, string) {
+	var parts []splitPart
+	var rest []string
+	for _, line := range strings.Split(description, "\n") {
+		if m := checklistItemPattern.FindStringSubmatch(line); m != nil {
+			parts = append(parts, splitPart{title: strings.TrimSpace(m[2]), done: m[1] != " "})
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return parts, strings.TrimSpace(strings.Join(rest, "\n"))
+}
+
+// splitSections extracts "## " sections and returns the text before the first one
+func splitSections(description string) ([]splitPart, string) {
+	var parts []splitPart
+	var preamble, body []string
+	inSection := false
+	flush := func() {
+		if inSection {
+			parts[len(parts)-1].description = strings.TrimSpace(strings.Join(body, "\n"))
+		}
+		body = nil
+	}
+	for _, line := range strings.Split(description, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			parts = append(parts, splitPart{title: strings.TrimSpace(strings.TrimPrefix(line, "## "))})
+			inSection = true
+			continue
+		}
+		if inSection {
+			body = append(body, line)
+		} else {
+			preamble = append(preamble, line)
+		}
+	}
+	flush()
+	return parts, strings.TrimSpace(strings.Join(preamble, "\n"))
+}
+
+// SplitTask turns a task into a parent of new subtasks, either with the given
+// titles or derived from its description. Derived items are moved out of the
+// parent's description. Subtasks inherit priority and type.
+func (s *Service) SplitTask(id int, opts SplitOptions) (*SplitResult, error) {
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status == StatusDone {
+		return nil, fmt.Errorf("task %d is done and cannot be split", id)
+	}
+
+	var parts []splitPart
+	description := t.Description
+	switch {
+	case len(opts.Titles) > 0 && opts.By != "":
+		return nil, fmt.Errorf("use either titles or by, not both")
+	case len(opts.Titles) > 0:
+		for _, title := range opts.Titles {
+			if strings.TrimSpace(title) == "" {
+				return nil, fmt.Errorf("subtask title cannot be empty")
+			}
+			parts = append(parts, splitPart{title: strings.TrimSpace(title)})
+		}
+	case opts.By == SplitByChecklist:
+		parts, description = splitChecklist(t.Description)
+	case opts.By == SplitBySections:
+		parts, description = splitSections(t.Description)
+	case opts.By != "":
+		return nil, fmt.Errorf("invalid split mode: %s (use %s or %s)", opts.By, SplitByChecklist, SplitBySections)
+	default:
+		return nil, fmt.Errorf("titles or by is required")
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("task %d has no %s in its description to split", id, opts.By)
+	}
+	if err := s.checkDepth(id, 1); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	nextID := s.nextID()
+	result := &SplitResult{}
+	for _, part := range parts {
+		status := StatusTodo
+		if part.done {
+			status = StatusDone
+		}
+		parentID := id
+		sub := &Task{
+			ID:          nextID,
+			ParentID:    &parentID,
+			Title:       part.title,
+			Description: part.description,
+			Status:      status,
+			Priority:    t.Priority,
+			Type:        t.Type,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		nextID++
+		result.Subtasks = append(result.Subtasks, sub)
+	}
+
+	// Ensure directory exists for write operation
+	if err := s.storage.EnsureDir(); err != nil {
+		return nil, err
+	}
+
+	// Write the subtasks, then the parent; undo everything if one fails
+	for i, sub := range result.Subtasks {
+		if err := s.storage.Save(sub); err != nil {
+			for _, written := range result.Subtasks[:i] {
+				_ = s.storage.Delete(written.ID)
+			}
+			return nil, fmt.Errorf("failed to save subtask %q: %w", sub.Title, err)
+		}
+	}
+	parent := *t
+	parent.Description = description
+	parent.UpdatedAt = now
+	if err := s.storage.Save(&parent); err != nil {
+		for _, written := range result.Subtasks {
+			_ = s.storage.Delete(written.ID)
+		}
+		return nil, err
+	}
+
+	for _, sub := range result.Subtasks {
+		s.index.Set(sub)
+	}
+	s.index.Set(&parent)
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+
+	// Checked checklist items may leave nothing open
+	if _, err := s.syncParentStatus(id); err != nil {
+		return nil, err
+	}
+	if result.Parent, err = s.Get(id); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MergeTasks folds sibling tasks into the first one: descriptions are
+// concatenated, tags and relations united, subtasks moved over, and the
+// other tasks deleted. The merged task keeps the highest priority. All
+// changes are planned and validated before anything is written.
+func (s *Service) MergeTasks(ids []int) (*MergeResult, error) {
+	if len(ids) < 2 {
+		return nil, fmt.Errorf("at least two tasks are required to merge")
+	}
+	plan := s.newRelationPlan()
+	targetID := ids[0]
+	target, err := plan.task(targetID)
+	if err != nil {
+		return nil, err
+	}
+	others := make([]*Task, 0, len(ids)-1)
+	for _, id := range ids[1:] {
+		if id == targetID || plan.deleted[id] {
+			return nil, fmt.Errorf("task %d listed more than once", id)
+		}
+		other, err := plan.task(id)
+		if err != nil {
+			return nil, err
+		}
+		if !sameParent(target.ParentID, other.ParentID) {
+			return nil, fmt.Errorf("task %d and task %d do not share the same parent", targetID, id)
+		}
+		plan.deleted[id] = true
+		others = append(others, other)
+	}
+
+	result := &MergeResult{}
+	for _, other := range others {
+		// Relations other tasks have to the merged task
+		repointed := &DuplicateResult{}
+		plan.repoint(other.ID, targetID, repointed)
+		result.Repointed = append(result.Repointed, repointed.Repointed...)
+		result.Dropped = append(result.Dropped, repointed.Dropped...)
+
+		// Relations stored on the merged task itself
+		for _, rel := range other.Relations {
+			dropped := RelationEdge{Type: rel.Type, Source: other.ID, Target: rel.Task}
+			if !s.isLocalProject(rel.Project) {
+				rt, _, _, err := s.resolveRelation(targetID, rel.Type, rel.Task)
+				if err != nil || !isAllowedTaskType(rt.SourceTypes, target.Type) || hasProjectRelation(target, rel.Type, rel.Project, rel.Task) {
+					result.Dropped = append(result.Dropped, dropped)
+					continue
+				}
+				target.Relations = append(target.Relations, rel)
+				continue
+			}
+			if rel.Task == targetID || plan.deleted[rel.Task] || plan.checkAdd(targetID, rel.Type, rel.Task) != nil {
+				result.Dropped = append(result.Dropped, dropped)
+				continue
+			}
+			target.Relations = append(target.Relations, Relation{Type: rel.Type, Task: rel.Task})
+			result.Repointed = append(result.Repointed, RelationEdge{Type: rel.Type, Source: targetID, Target: rel.Task})
+		}
+
+		for _, sub := range s.index.GetSubtasks(other.ID) {
+			moved, err := plan.task(sub.ID)
+			if err != nil {
+				return nil, err
+			}
+			moved.ParentID = &targetID
+			plan.changed[moved.ID] = true
+			result.Subtasks = append(result.Subtasks, moved.ID)
+		}
+	}
+
+	now := time.Now().UTC()
+	allDone := target.Status == StatusDone
+	for _, other := range others {
+		mergeContent(target, other)
+		if other.Priority.Order() < target.Priority.Order() {
+			target.Priority = other.Priority
+		}
+		if other.Status != StatusDone {
+			allDone = false
+		}
+		if other.Status == StatusInProgress && target.Status != StatusInProgress {
+			target.setStatus(StatusInProgress, now)
+		}
+	}
+	if target.Status == StatusDone && !allDone {
+		target.setStatus(StatusInProgress, now)
+	}
+	plan.changed[targetID] = true
+
+	if err := plan.apply(now); err != nil {
+		return nil, err
+	}
+	for _, other := range others {
+		result.Merged = append(result.Merged, other.ID)
+	}
+
+	for _, id := range []*int{&targetID, target.ParentID} {
+		if id == nil {
+			continue
+		}
+		if _, err := s.syncParentStatus(*id); err != nil {
+			return nil, err
+		}
+	}
+	if result.Task, err = s.Get(targetID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeContent appends from's description and tags to into
+func mergeContent(into, from *Task) {
+	if strings.TrimSpace(from.Description) != "" {
+		merged := fmt.Sprintf("Merged from #%d (%s):\n\n%s", from.ID, from.Title, from.Description)
+		if into.Description != "" {
+			merged = into.Description + "\n\n---\n\n" + merged
+		}
+		into.Description = merged
+	}
+	for _, tag := range from.Tags {
+		if !containsString(into.Tags, tag) {
+			into.Tags = append(into.Tags, tag)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestService_SplitTask_Checklist(t *testing.T) {
+	svc := newDependencyTestService(t, 1, nil)
+	desc := "Build the login page.\n\n- [ ] Form layout\n- [x] Validation rules\n* [ ] Error messages"
+	if _, err := svc.Update(1, nil, &desc, nil, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	result, err := svc.SplitTask(1, SplitOptions{By: SplitByChecklist})
+	if err != nil {
+		t.Fatalf("SplitTask() error = %v", err)
+	}
+	if len(result.Subtasks) != 3 {
+		t.Fatalf("Subtasks = %d, want 3", len(result.Subtasks))
+	}
+	if result.Subtasks[0].Title != "Form layout" || result.Subtasks[1].Status != StatusDone {
+		t.Errorf("Subtasks = %+v, want titles from checklist and checked item done", result.Subtasks)
+	}
+	if result.Parent.Description != "Build the login page." {
+		t.Errorf("parent description = %q, want checklist removed", result.Parent.Description)
+	}
+	if sub := result.Subtasks[2]; sub.ParentID == nil || *sub.ParentID != 1 || sub.Priority != PriorityMedium {
+		t.Errorf("subtask = %+v, want parent 1 and inherited priority", sub)
+	}
+}
+
+func TestService_SplitTask_Sections(t *testing.T) {
+	svc := newDependencyTestService(t, 1, nil)
+	desc := "Overview\n\n## Backend\nAdd endpoint\n\n## Frontend\nAdd page"
+	if _, err := svc.Update(1, nil, &desc, nil, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	result, err := svc.SplitTask(1, SplitOptions{By: SplitBySections})
+	if err != nil {
+		t.Fatalf("SplitTask() error = %v", err)
+	}
+	if len(result.Subtasks) != 2 || result.Subtasks[1].Title != "Frontend" {
+		t.Fatalf("Subtasks = %+v, want Backend and Frontend", result.Subtasks)
+	}
+	backend, _ := svc.Get(result.Subtasks[0].ID)
+	if backend.Description != "Add endpoint" {
+		t.Errorf("Backend description = %q, want section body", backend.Description)
+	}
+	if result.Parent.Description != "Overview" {
+		t.Errorf("parent description = %q, want preamble", result.Parent.Description)
+	}
+}
+
+func TestService_SplitTask_Errors(t *testing.T) {
+	svc := newDependencyTestService(t, 1, nil)
+
+	tests := []struct {
+		name string
+		opts SplitOptions
+	}{
+		{"nothing", SplitOptions{}},
+		{"both", SplitOptions{Titles: []string{"A"}, By: SplitByChecklist}},
+		{"empty title", SplitOptions{Titles: []string{" "}}},
+		{"invalid mode", SplitOptions{By: "paragraphs"}},
+		{"no checklist", SplitOptions{By: SplitByChecklist}},
+	}
+	for _, tt := range tests {
+		if _, err := svc.SplitTask(1, tt.opts); err == nil {
+			t.Errorf("%s: SplitTask() expected error", tt.name)
+		}
+	}
+}
+
+func TestService_MergeTasks(t *testing.T) {
+	// 2 blocked_by 5, 4 blocked_by 3
+	svc := newDependencyTestService(t, 5, [][2]int{{2, 5}, {4, 3}})
+	desc1, desc2 := "First part", "Second part"
+	high := PriorityHigh
+	if _, err := svc.Update(1, nil, &desc1, nil, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := svc.Update(3, nil, &desc2, nil, &high, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 2); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+
+	result, err := svc.MergeTasks([]int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("MergeTasks() error = %v", err)
+	}
+
+	merged := result.Task
+	if !strings.Contains(merged.Description, "First part") || !strings.Contains(merged.Description, "Merged from #3") {
+		t.Errorf("Description = %q, want both descriptions", merged.Description)
+	}
+	if merged.Priority != PriorityHigh {
+		t.Errorf("Priority = %s, want high", merged.Priority)
+	}
+	if blockers := svc.index.GetBlockers(1); len(blockers) != 1 || blockers[0] != 5 {
+		t.Errorf("GetBlockers(1) = %v, want [5]", blockers)
+	}
+	if blockers := svc.index.GetBlockers(4); len(blockers) != 1 || blockers[0] != 1 {
+		t.Errorf("GetBlockers(4) = %v, want [1]", blockers)
+	}
+	if subs := svc.index.GetSubtasks(1); len(subs) != 1 || len(result.Subtasks) != 1 {
+		t.Errorf("GetSubtasks(1) = %d, want the moved subtask", len(subs))
+	}
+	for _, id := range []int{2, 3} {
+		if _, err := svc.Get(id); err == nil {
+			t.Errorf("task %d should be deleted", id)
+		}
+	}
+}
+
+func TestService_MergeTasks_Errors(t *testing.T) {
+	svc := newDependencyTestService(t, 2, nil)
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+
+	for name, ids := range map[string][]int{
+		"single":      {1},
+		"repeated":    {1, 1},
+		"not sibling": {2, 3},
+		"missing":     {1, 99},
+	} {
+		if _, err := svc.MergeTasks(ids); err == nil {
+			t.Errorf("%s: MergeTasks() expected error", name)
+		}
+	}
+}
+
+// failingStorage fails to save one task
+type failingStorage struct {
+	*mockStorage
+	failID int
+}
+
+func (f *failingStorage) Save(t *Task) error {
+	if t.ID == f.failID {
+		return errors.New("disk full")
+	}
+	return f.mockStorage.Save(t)
+}
+
+func TestService_SplitTask_AllOrNothing(t *testing.T) {
+	svc := newDependencyTestService(t, 1, nil)
+	ms := svc.storage.(*mockStorage)
+	svc.storage = &failingStorage{mockStorage: ms, failID: 3}
+
+	if _, err := svc.SplitTask(1, SplitOptions{Titles: []string{"A", "B", "C"}}); err == nil {
+		t.Fatal("SplitTask() expected error")
+	}
+	if len(ms.tasks) != 1 {
+		t.Errorf("stored tasks = %d, want only the original task", len(ms.tasks))
+	}
+	if svc.index.HasSubtasks(1) {
+		t.Error("no subtask should be indexed after a failed split")
+	}
+}
+
+func TestService_MergeTasks_ValidatesAgainstMergedState(t *testing.T) {
+	// 2 blocked_by 4 and 4 blocked_by 1: moving 2's blocker to 1 would close a cycle
+	svc := newDependencyTestService(t, 4, [][2]int{{2, 4}, {4, 1}, {3, 2}})
+	idx := svc.index.(*mockIndex)
+	saves := idx.saves
+
+	result, err := svc.MergeTasks([]int{1, 2})
+	if err != nil {
+		t.Fatalf("MergeTasks() error = %v", err)
+	}
+	if idx.saves != saves+1 {
+		t.Errorf("index saved %d times, want once", idx.saves-saves)
+	}
+	if len(result.Dropped) != 1 || result.Dropped[0] != (RelationEdge{Type: RelationBlockedBy, Source: 2, Target: 4}) {
+		t.Errorf("Dropped = %v, want the relation that would close a cycle", result.Dropped)
+	}
+	if blockers := svc.index.GetBlockers(1); len(blockers) != 0 {
+		t.Errorf("GetBlockers(1) = %v, want none", blockers)
+	}
+	if blockers := svc.index.GetBlockers(3); len(blockers) != 1 || blockers[0] != 1 {
+		t.Errorf("GetBlockers(3) = %v, want [1]", blockers)
+	}
+}
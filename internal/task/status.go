@@ -0,0 +1,72 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ActionForce marks a history entry for a status change that skipped validation
+const ActionForce = "force"
+
+// blockedError reports the open blockers that keep a task from starting
+func blockedError(id int, blockers []BlockingInfo) error {
+	var parts []string
+	for _, b := range blockers {
+		parts = append(parts, fmt.Sprintf("%d (%s)", b.TaskID, b.Status))
+	}
+	return fmt.Errorf("task %d is blocked by tasks: %s", id, strings.Join(parts, ", "))
+}
+
+// checkStatusChange applies the rules of StartTask, CompleteTask and
+// ReopenTask to a direct status change. pending holds the statuses other
+// tasks are about to receive in the same batch.
+func (s *Service) checkStatusChange(t *Task, to Status, pending map[int]Status) error {
+	from := t.Status
+	if to == from {
+		return nil
+	}
+	statusOf := func(e *Task) Status {
+		if st, ok := pending[e.ID]; ok {
+			return st
+		}
+		return e.Status
+	}
+
+	if from == StatusDone {
+		return fmt.Errorf("task %d is done; reopen it with a reason or force the change", t.ID)
+	}
+
+	if from == StatusTodo {
+		var open []BlockingInfo
+		if _, blockers := s.IsBlocked(t.ID); len(blockers) > 0 {
+			for _, b := range blockers {
+				if st, ok := pending[b.TaskID]; !ok || st != StatusDone {
+					open = append(open, b)
+				}
+			}
+		}
+		if len(open) > 0 {
+			return blockedError(t.ID, open)
+		}
+	}
+
+	incomplete, started := 0, 0
+	for _, sub := range s.Descendants(t.ID) {
+		st := statusOf(sub)
+		if st != StatusDone {
+			incomplete++
+		}
+		if st != StatusTodo {
+			started++
+		}
+	}
+	switch {
+	case to == StatusDone && incomplete > 0:
+		return fmt.Errorf("cannot complete task %d: has %d incomplete subtask(s)", t.ID, incomplete)
+	case to == StatusDone && from != StatusInProgress:
+		return fmt.Errorf("task %d is not in progress (current: %s); start it first or force the change", t.ID, from)
+	case to == StatusTodo && started > 0:
+		return fmt.Errorf("cannot move task %d back to todo: %d subtask(s) already started", t.ID, started)
+	}
+	return nil
+}
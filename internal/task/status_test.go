@@ -0,0 +1,158 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestService_Update_StatusRules(t *testing.T) {
+	// 2 is a subtask of 1; 3 blocked_by 2
+	svc := newDependencyTestService(t, 1, nil)
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+	if _, err := svc.Create("Dependent", "", PriorityMedium, "feature", nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := svc.AddRelation(3, RelationBlockedBy, 2); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+
+	inProgress, done, todo := StatusInProgress, StatusDone, StatusTodo
+	if _, err := svc.Update(3, nil, nil, &inProgress, nil, nil, false); err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("Update() of blocked task error = %v, want blocked", err)
+	}
+	if _, err := svc.Update(1, nil, nil, &done, nil, nil, false); err == nil || !strings.Contains(err.Error(), "incomplete subtask") {
+		t.Errorf("Update() of parent with open subtask error = %v, want incomplete subtask", err)
+	}
+	plain, _ := svc.Create("Plain", "", PriorityMedium, "feature", nil)
+	if _, err := svc.Update(plain.ID, nil, nil, &done, nil, nil, false); err == nil || !strings.Contains(err.Error(), "not in progress") {
+		t.Errorf("Update() of todo task to done error = %v, want not in progress", err)
+	}
+
+	// Starting the subtask starts the parent, completing it completes the parent
+	if _, err := svc.Update(2, nil, nil, &inProgress, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if p, _ := svc.Get(1); p.Status != StatusInProgress {
+		t.Errorf("parent status = %s, want in_progress", p.Status)
+	}
+	if _, err := svc.Update(1, nil, nil, &todo, nil, nil, false); err == nil {
+		t.Error("Update() back to todo with a started subtask should fail")
+	}
+	if _, err := svc.Update(2, nil, nil, &done, nil, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if p, _ := svc.Get(1); p.Status != StatusDone {
+		t.Errorf("parent status = %s, want done", p.Status)
+	}
+
+	// Done tasks are reopened with a reason, not updated
+	if _, err := svc.Update(2, nil, nil, &todo, nil, nil, false); err == nil || !strings.Contains(err.Error(), "reopen") {
+		t.Errorf("Update() of done task error = %v, want reopen hint", err)
+	}
+
+	// Non-status changes are never checked
+	title := "Renamed"
+	if _, err := svc.Update(2, &title, nil, nil, nil, nil, false); err != nil {
+		t.Errorf("Update() title of done task error = %v", err)
+	}
+}
+
+func TestService_Update_Force(t *testing.T) {
+	svc := newDependencyTestService(t, 2, [][2]int{{2, 1}})
+	parent := 1
+	if _, err := svc.Create("Sub", "", PriorityMedium, "feature", &parent); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	done := StatusDone
+	updated, err := svc.Update(2, nil, nil, &done, nil, nil, true)
+	if err != nil {
+		t.Fatalf("Update() with force error = %v", err)
+	}
+	if n := len(updated.History); n != 1 || updated.History[0].Action != ActionForce || updated.History[0].From != StatusTodo || updated.History[0].To != StatusDone {
+		t.Errorf("History = %+v, want one force entry todo -> done", updated.History)
+	}
+
+	// Forcing skips the cascade: the parent stays todo
+	inProgress := StatusInProgress
+	if _, err := svc.Update(3, nil, nil, &inProgress, nil, nil, true); err != nil {
+		t.Fatalf("Update() with force error = %v", err)
+	}
+	if p, _ := svc.Get(1); p.Status != StatusTodo {
+		t.Errorf("parent status = %s, want todo", p.Status)
+	}
+
+	// Forcing an unchanged status records nothing
+	again, err := svc.Update(2, nil, nil, &done, nil, nil, true)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(again.History) != 1 {
+		t.Errorf("len(History) = %d, want 1", len(again.History))
+	}
+}
+
+func TestService_BatchUpdate_StatusRules(t *testing.T) {
+	// 2 blocked_by 1
+	svc := newDependencyTestService(t, 3, [][2]int{{2, 1}})
+
+	inProgress, done := StatusInProgress, StatusDone
+	if _, err := svc.BatchUpdate([]int{2, 3}, nil, &inProgress, nil, nil, false); err == nil {
+		t.Fatal("BatchUpdate() including a blocked task should fail")
+	}
+	if task3, _ := svc.Get(3); task3.Status != StatusTodo {
+		t.Errorf("task 3 status = %s, want unchanged after failed batch", task3.Status)
+	}
+
+	// Todo tasks are started before they are completed, as with CompleteTask
+	if _, err := svc.BatchUpdate([]int{1}, nil, &done, nil, nil, false); err == nil || !strings.Contains(err.Error(), "not in progress") {
+		t.Errorf("BatchUpdate() of todo task to done error = %v, want not in progress", err)
+	}
+
+	// A subtask completed in the same batch no longer keeps the parent open
+	sub, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1)
+	if err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+	if _, err := svc.StartTask(sub.ID); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	if _, err := svc.BatchUpdate([]int{1, sub.ID}, nil, &done, nil, nil, false); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	tasks, err := svc.BatchUpdate([]int{3}, nil, &done, nil, nil, true)
+	if err != nil {
+		t.Fatalf("BatchUpdate() with force error = %v", err)
+	}
+	if len(tasks[0].History) != 1 || tasks[0].History[0].Action != ActionForce {
+		t.Errorf("History = %+v, want force entry", tasks[0].History)
+	}
+}
+
+func TestService_Update_RejectsArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	done, _ := svc.Create("Done", "", PriorityMedium, "feature", nil)
+	completeAndArchive(t, svc, done.ID)
+
+	todo := StatusTodo
+	title := "Renamed"
+	checks := map[string]func() error{
+		"Update":       func() error { _, err := svc.Update(done.ID, &title, nil, nil, nil, nil, false); return err },
+		"Update force": func() error { _, err := svc.Update(done.ID, nil, nil, &todo, nil, nil, true); return err },
+		"BatchUpdate":  func() error { _, err := svc.BatchUpdate([]int{done.ID}, nil, &todo, nil, nil, true); return err },
+		"StartTask":    func() error { _, err := svc.StartTask(done.ID); return err },
+		"CompleteTask": func() error { _, err := svc.CompleteTask(done.ID); return err },
+	}
+	for name, check := range checks {
+		if err := check(); err == nil || !strings.Contains(err.Error(), "archived") {
+			t.Errorf("%s() on an archived task error = %v, want archived error", name, err)
+		}
+	}
+	// No active copy is written next to the archived one
+	if _, ok := svc.index.Get(done.ID); ok {
+		t.Error("archived task should not be back in the active index")
+	}
+}
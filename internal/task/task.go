@@ -1,6 +1,9 @@
 package task
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Status represents the current state of a task
 type Status string
@@ -37,24 +40,80 @@ func (p Priority) Order() int {
 	}
 }
 
-// Relation represents a link between tasks
+// ResolutionCancelled marks a done task that was cancelled rather than completed
+const ResolutionCancelled = "cancelled"
+
+// StatusChange records a status transition that was made for a reason
+type StatusChange struct {
+	Action string    `yaml:"action" json:"action"` // e.g. reopen, cancel
+	From   Status    `yaml:"from" json:"from"`
+	To     Status    `yaml:"to" json:"to"`
+	Reason string    `yaml:"reason,omitempty" json:"reason,omitempty"`
+	At     time.Time `yaml:"at" json:"at"`
+}
+
+// RelationBlockedBy is the relation type that marks a task as blocked by another
+const RelationBlockedBy = "blocked_by"
+
+// Relation represents a link between tasks. Project names the workspace
+// project of the target task; empty means the task's own project.
 type Relation struct {
-	Type string `yaml:"type" json:"type"`
-	Task int    `yaml:"task" json:"task"`
+	Type    string `yaml:"type" json:"type"`
+	Task    int    `yaml:"task" json:"task"`
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+}
+
+// Ref returns the relation target as a reference: #42, or api#42 for a task
+// in another project
+func (r Relation) Ref() string {
+	if r.Project != "" {
+		return FormatRef(r.Project, r.Task)
+	}
+	return fmt.Sprintf("#%d", r.Task)
 }
 
 // Task represents a single task
 type Task struct {
-	ID          int        `yaml:"id" json:"id"`
-	ParentID    *int       `yaml:"parent_id,omitempty" json:"parent_id,omitempty"`
-	Title       string     `yaml:"title" json:"title"`
-	Description string     `yaml:"-" json:"description"` // Stored in markdown body
-	Status      Status     `yaml:"status" json:"status"`
-	Priority    Priority   `yaml:"priority" json:"priority"`
-	Type        string     `yaml:"type" json:"type"`
-	Relations   []Relation `yaml:"relations,omitempty" json:"relations,omitempty"`
-	CreatedAt   time.Time  `yaml:"created_at" json:"created_at"`
-	UpdatedAt   time.Time  `yaml:"updated_at" json:"updated_at"`
+	ID          int            `yaml:"id" json:"id"`
+	ParentID    *int           `yaml:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Title       string         `yaml:"title" json:"title"`
+	Description string         `yaml:"-" json:"description"` // Stored in markdown body
+	Status      Status         `yaml:"status" json:"status"`
+	Priority    Priority       `yaml:"priority" json:"priority"`
+	Type        string         `yaml:"type" json:"type"`
+	Tags        []string       `yaml:"tags,omitempty" json:"tags,omitempty"`
+	ExternalID  string         `yaml:"external_id,omitempty" json:"external_id,omitempty"` // ID in the tracker the task was imported from
+	Order       int            `yaml:"order,omitempty" json:"order,omitempty"`             // Rank among siblings; 0 = by ID (see Rank)
+	Relations   []Relation     `yaml:"relations,omitempty" json:"relations,omitempty"`
+	Resolution  string         `yaml:"resolution,omitempty" json:"resolution,omitempty"` // ResolutionCancelled for cancelled tasks
+	History     []StatusChange `yaml:"history,omitempty" json:"history,omitempty"`
+	CreatedAt   time.Time      `yaml:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `yaml:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time     `yaml:"completed_at,omitempty" json:"completed_at,omitempty"` // When the task last became done
+}
+
+// setStatus changes the status, recording when the task becomes done and
+// forgetting it when the task is reopened
+func (t *Task) setStatus(status Status, at time.Time) {
+	switch {
+	case status != StatusDone:
+		t.CompletedAt = nil
+	case t.Status != StatusDone || t.CompletedAt == nil:
+		t.CompletedAt = &at
+	}
+	t.Status = status
+}
+
+// OrderStep is the rank distance between consecutive tasks without an explicit order
+const OrderStep = 1000
+
+// Rank returns the position of the task among its siblings (lower first).
+// Tasks without an explicit order rank by ID, so they keep creation order.
+func (t *Task) Rank() int {
+	if t.Order != 0 {
+		return t.Order
+	}
+	return t.ID * OrderStep
 }
 
 // IsValidStatus checks if status is valid
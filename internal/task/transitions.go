@@ -0,0 +1,158 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TransitionResult describes the outcome of ReopenTask and CancelTask
+type TransitionResult struct {
+	Task           *Task   `json:"task"`
+	UpdatedParents []*Task `json:"updated_parents,omitempty"`    // Ancestors whose status changed
+	Cancelled      []int   `json:"cancelled_subtasks,omitempty"` // Open subtasks cancelled along with the task
+	Reblocked      []int   `json:"reblocked,omitempty"`          // Dependents blocked again by the reopened task
+	Unblocked      []int   `json:"unblocked,omitempty"`          // Dependents no longer blocked
+}
+
+// recordTransition sets a task's status and resolution, appends the change
+// to its history and saves it. The index is not saved.
+func (s *Service) recordTransition(t *Task, to Status, resolution, action, reason string) error {
+	now := time.Now().UTC()
+	t.History = append(t.History, StatusChange{Action: action, From: t.Status, To: to, Reason: reason, At: now})
+	t.setStatus(to, now)
+	t.Resolution = resolution
+	t.UpdatedAt = now
+	if err := s.storage.Save(t); err != nil {
+		return fmt.Errorf("failed to save task %d: %w", t.ID, err)
+	}
+	s.index.Set(t)
+	return nil
+}
+
+// ReopenTask moves a done or cancelled task back to todo (in_progress if it
+// has subtasks). Done ancestors are un-completed and open dependents become
+// blocked again.
+func (s *Service) ReopenTask(id int, reason string) (*TransitionResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to reopen a task")
+	}
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != StatusDone {
+		return nil, fmt.Errorf("task %d is not done (current: %s)", id, t.Status)
+	}
+
+	to := StatusTodo
+	if s.index.HasSubtasks(id) {
+		to = StatusInProgress
+	}
+	if err := s.recordTransition(t, to, "", "reopen", reason); err != nil {
+		return nil, err
+	}
+
+	result := &TransitionResult{Task: t}
+	for _, ancestorID := range s.ancestorIDs(t) {
+		ancestor, err := s.activeTask(ancestorID)
+		if err != nil {
+			return nil, err
+		}
+		if ancestor.Status != StatusDone {
+			continue
+		}
+		if err := s.recordTransition(ancestor, StatusInProgress, "", "reopen", fmt.Sprintf("subtask #%d reopened: %s", id, reason)); err != nil {
+			return nil, err
+		}
+		result.UpdatedParents = append(result.UpdatedParents, ancestor)
+	}
+
+	for _, depID := range s.index.GetDependents(id) {
+		if dep, ok := s.index.Get(depID); ok && dep.Status != StatusDone {
+			result.Reblocked = append(result.Reblocked, depID)
+		}
+	}
+
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelTask closes a task that will not be done, together with its open
+// subtasks. Cancelled tasks count as done for parents and dependents and are
+// marked with ResolutionCancelled.
+func (s *Service) CancelTask(id int, reason string) (*TransitionResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to cancel a task")
+	}
+	t, err := s.activeTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status == StatusDone {
+		return nil, fmt.Errorf("task %d is already done", id)
+	}
+
+	var blockedBefore []int
+	for _, depID := range s.index.GetDependents(id) {
+		if blocked, _ := s.IsBlocked(depID); blocked {
+			blockedBefore = append(blockedBefore, depID)
+		}
+	}
+
+	result := &TransitionResult{Task: t}
+	for _, sub := range s.Descendants(id) {
+		if sub.Status == StatusDone {
+			continue
+		}
+		full, err := s.activeTask(sub.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.recordTransition(full, StatusDone, ResolutionCancelled, "cancel", fmt.Sprintf("parent #%d cancelled: %s", id, reason)); err != nil {
+			return nil, err
+		}
+		result.Cancelled = append(result.Cancelled, sub.ID)
+	}
+	if err := s.recordTransition(t, StatusDone, ResolutionCancelled, "cancel", reason); err != nil {
+		return nil, err
+	}
+
+	// Auto-complete ancestors whose subtasks are now all closed
+	status := StatusDone
+	for _, ancestorID := range s.ancestorIDs(t) {
+		allDone := true
+		for _, sib := range s.index.GetSubtasks(ancestorID) {
+			if sib.Status != StatusDone {
+				allDone = false
+				break
+			}
+		}
+		if !allDone {
+			break
+		}
+		if ancestor, ok := s.index.Get(ancestorID); ok && ancestor.Status == StatusDone {
+			continue
+		}
+		updated, err := s.update(ancestorID, nil, nil, &status, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to auto-complete parent: %w", err)
+		}
+		result.UpdatedParents = append(result.UpdatedParents, updated)
+	}
+
+	for _, depID := range blockedBefore {
+		if blocked, _ := s.IsBlocked(depID); !blocked {
+			result.Unblocked = append(result.Unblocked, depID)
+		}
+	}
+
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestService_ReopenTask(t *testing.T) {
+	// 3 blocked_by 2; 2 is a subtask of 1
+	svc := newDependencyTestService(t, 1, nil)
+	if _, err := svc.CreateSubtask("Sub", "", PriorityMedium, "feature", 1); err != nil {
+		t.Fatalf("CreateSubtask() error = %v", err)
+	}
+	if _, err := svc.Create("Dependent", "", PriorityMedium, "feature", nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := svc.AddRelation(3, RelationBlockedBy, 2); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	svc.StartTask(2)
+	svc.CompleteTask(2)
+	if p, _ := svc.Get(1); p.Status != StatusDone {
+		t.Fatalf("parent status = %s, want auto-completed", p.Status)
+	}
+
+	if _, err := svc.ReopenTask(2, " "); err == nil {
+		t.Error("ReopenTask() without reason should fail")
+	}
+	if _, err := svc.ReopenTask(3, "why"); err == nil {
+		t.Error("ReopenTask() on a todo task should fail")
+	}
+
+	result, err := svc.ReopenTask(2, "tests fail on CI")
+	if err != nil {
+		t.Fatalf("ReopenTask() error = %v", err)
+	}
+	if result.Task.Status != StatusTodo {
+		t.Errorf("status = %s, want todo", result.Task.Status)
+	}
+	if len(result.UpdatedParents) != 1 || result.UpdatedParents[0].Status != StatusInProgress {
+		t.Errorf("UpdatedParents = %v, want parent back in progress", result.UpdatedParents)
+	}
+	if len(result.Reblocked) != 1 || result.Reblocked[0] != 3 {
+		t.Errorf("Reblocked = %v, want [3]", result.Reblocked)
+	}
+	if blocked, _ := svc.IsBlocked(3); !blocked {
+		t.Error("task 3 should be blocked again")
+	}
+
+	reopened, _ := svc.Get(2)
+	if n := len(reopened.History); n != 1 || reopened.History[0].Reason != "tests fail on CI" || reopened.History[0].From != StatusDone {
+		t.Errorf("History = %+v, want one reopen entry with reason", reopened.History)
+	}
+}
+
+func TestService_CancelTask(t *testing.T) {
+	// 4 blocked_by 1; 1 has subtasks 2 (done) and 3 (todo)
+	svc := newDependencyTestService(t, 1, nil)
+	svc.CreateSubtask("Done sub", "", PriorityMedium, "feature", 1)
+	svc.CreateSubtask("Open sub", "", PriorityMedium, "feature", 1)
+	svc.Create("Dependent", "", PriorityMedium, "feature", nil)
+	if err := svc.AddRelation(4, RelationBlockedBy, 1); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	svc.StartTask(2)
+	svc.CompleteTask(2)
+
+	if _, err := svc.CancelTask(1, ""); err == nil {
+		t.Error("CancelTask() without reason should fail")
+	}
+
+	result, err := svc.CancelTask(1, "superseded by new design")
+	if err != nil {
+		t.Fatalf("CancelTask() error = %v", err)
+	}
+	if result.Task.Status != StatusDone || result.Task.Resolution != ResolutionCancelled {
+		t.Errorf("task = %s/%s, want done/cancelled", result.Task.Status, result.Task.Resolution)
+	}
+	if len(result.Cancelled) != 1 || result.Cancelled[0] != 3 {
+		t.Errorf("Cancelled = %v, want [3]", result.Cancelled)
+	}
+	if len(result.Unblocked) != 1 || result.Unblocked[0] != 4 {
+		t.Errorf("Unblocked = %v, want [4]", result.Unblocked)
+	}
+	if done, _ := svc.Get(2); done.Resolution != "" {
+		t.Errorf("completed subtask resolution = %q, want unchanged", done.Resolution)
+	}
+
+	if _, err := svc.CancelTask(1, "again"); err == nil {
+		t.Error("CancelTask() on a done task should fail")
+	}
+
+	// Reopening clears the resolution
+	reopened, err := svc.ReopenTask(1, "back on the roadmap")
+	if err != nil {
+		t.Fatalf("ReopenTask() error = %v", err)
+	}
+	if reopened.Task.Resolution != "" || reopened.Task.Status != StatusInProgress {
+		t.Errorf("task = %s/%q, want in_progress without resolution", reopened.Task.Status, reopened.Task.Resolution)
+	}
+	if len(reopened.Task.History) != 2 {
+		t.Errorf("History length = %d, want 2", len(reopened.Task.History))
+	}
+}
+
+func TestService_Transitions_RejectArchived(t *testing.T) {
+	svc := newArchiveTestService(t)
+	done, _ := svc.Create("Done", "", PriorityMedium, "feature", nil)
+	completeAndArchive(t, svc, done.ID)
+
+	if _, err := svc.ReopenTask(done.ID, "oops"); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("ReopenTask() on an archived task error = %v, want archived error", err)
+	}
+	if _, err := svc.CancelTask(done.ID, "oops"); err == nil || !strings.Contains(err.Error(), "archived") {
+		t.Errorf("CancelTask() on an archived task error = %v, want archived error", err)
+	}
+	// No active copy is written next to the archived one
+	if _, ok := svc.index.Get(done.ID); ok {
+		t.Error("archived task should not be back in the active index")
+	}
+	if archived, err := svc.archiveStorage.LoadArchived(done.ID); err != nil || archived.Status != StatusDone || len(archived.History) != 0 {
+		t.Errorf("archived task = %+v, %v, want it unchanged", archived, err)
+	}
+}
@@ -0,0 +1,261 @@
+package task
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TaskTreeNode describes a single task in a task tree document.
+// Key is a local identifier used to reference the task from blocked_by
+// entries and to report the ID assigned on creation.
+type TaskTreeNode struct {
+	Key         string         `yaml:"key,omitempty" json:"key,omitempty"`
+	Title       string         `yaml:"title" json:"title"`
+	Description string         `yaml:"description,omitempty" json:"description,omitempty"`
+	Priority    Priority       `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Type        string         `yaml:"type,omitempty" json:"type,omitempty"`
+	BlockedBy   []string       `yaml:"blocked_by,omitempty" json:"blocked_by,omitempty"`
+	Subtasks    []TaskTreeNode `yaml:"subtasks,omitempty" json:"subtasks,omitempty"`
+}
+
+// TaskTree is a nested plan document that is created in one operation.
+// If ParentID is set, the top-level nodes are created as subtasks of that
+// existing task.
+type TaskTree struct {
+	ParentID *int           `yaml:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Tasks    []TaskTreeNode `yaml:"tasks" json:"tasks"`
+}
+
+// TaskTreeResult holds the outcome of CreateTaskTree
+type TaskTreeResult struct {
+	IDs            map[string]int `json:"ids"`
+	Tasks          []*Task        `json:"tasks"`
+	UpdatedParents []*Task        `json:"updated_parents,omitempty"` // Ancestors whose status changed
+}
+
+// ParseTaskTree parses a task tree document in YAML or JSON format.
+// Unknown fields are rejected so typos do not silently drop data.
+func ParseTaskTree(data []byte) (*TaskTree, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var tree TaskTree
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("invalid task tree document: %w", err)
+	}
+	return &tree, nil
+}
+
+// plannedTask is a task from a tree document with its ID already assigned
+type plannedTask struct {
+	node *TaskTreeNode
+	task *Task
+}
+
+// CreateTaskTree creates all tasks described by the tree in a single
+// all-or-nothing operation. Everything is validated before any file is
+// written; if a write fails, the files already written are removed again.
+func (s *Service) CreateTaskTree(tree *TaskTree) (*TaskTreeResult, error) {
+	if tree == nil || len(tree.Tasks) == 0 {
+		return nil, fmt.Errorf("task tree contains no tasks")
+	}
+
+	if tree.ParentID != nil {
+		if _, ok := s.index.Get(*tree.ParentID); !ok {
+			return nil, fmt.Errorf("parent task not found: %d", *tree.ParentID)
+		}
+	}
+
+	if s.config != nil && !s.config.IsValidRelationType(RelationBlockedBy) && treeHasBlockers(tree.Tasks) {
+		return nil, fmt.Errorf("invalid relation type: %s", RelationBlockedBy)
+	}
+
+	// Assign IDs and build tasks in document order
+	now := time.Now().UTC()
+	nextID := s.nextID()
+	keys := make(map[string]int)
+	var planned []plannedTask
+
+	// depth is the level of the nodes being planned; top-level tasks are at depth 1
+	var plan func(nodes []TaskTreeNode, parentID *int, depth int) error
+	plan = func(nodes []TaskTreeNode, parentID *int, depth int) error {
+		for i := range nodes {
+			node := &nodes[i]
+			if err := s.validateTreeNode(node); err != nil {
+				return err
+			}
+			if limit := s.MaxDepth(); limit > 0 && depth > limit {
+				return fmt.Errorf("task %q: task hierarchy too deep: maximum depth is %d", node.Title, limit)
+			}
+			if node.Key != "" {
+				if _, exists := keys[node.Key]; exists {
+					return fmt.Errorf("duplicate key in task tree: %q", node.Key)
+				}
+				keys[node.Key] = nextID
+			}
+
+			t := &Task{
+				ID:          nextID,
+				ParentID:    parentID,
+				Title:       node.Title,
+				Description: node.Description,
+				Status:      StatusTodo,
+				Priority:    node.Priority,
+				Type:        node.Type,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			if t.Priority == "" {
+				t.Priority = PriorityMedium
+			}
+			if t.Type == "" && len(s.validTypes) > 0 {
+				t.Type = s.validTypes[0]
+			}
+			nextID++
+			planned = append(planned, plannedTask{node: node, task: t})
+
+			if len(node.Subtasks) > 0 {
+				id := t.ID
+				if err := plan(node.Subtasks, &id, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	startDepth := 1
+	if tree.ParentID != nil {
+		startDepth = s.Depth(*tree.ParentID) + 1
+	}
+	if err := plan(tree.Tasks, tree.ParentID, startDepth); err != nil {
+		return nil, err
+	}
+
+	// Resolve blocked_by references to local keys or existing task IDs
+	for _, p := range planned {
+		seen := make(map[int]bool)
+		for _, ref := range p.node.BlockedBy {
+			target, err := s.resolveTreeReference(ref, keys)
+			if err != nil {
+				return nil, fmt.Errorf("task %q: %w", p.task.Title, err)
+			}
+			if target == p.task.ID {
+				return nil, fmt.Errorf("task %q: cannot be blocked by itself", p.task.Title)
+			}
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			p.task.Relations = append(p.task.Relations, Relation{Type: RelationBlockedBy, Task: target})
+		}
+	}
+
+	// Existing tasks cannot be blocked by the new ones, so any cycle lies
+	// entirely within the tree
+	blockers := make(map[int][]int)
+	for _, p := range planned {
+		for _, rel := range p.task.Relations {
+			blockers[p.task.ID] = append(blockers[p.task.ID], rel.Task)
+		}
+	}
+	next := func(id int) []int {
+		if ids, ok := blockers[id]; ok {
+			return ids
+		}
+		return nil
+	}
+	for _, p := range planned {
+		for _, target := range blockers[p.task.ID] {
+			if path := findPath(target, p.task.ID, next); path != nil {
+				return nil, fmt.Errorf("task %q: %w", p.task.Title, &CycleError{Path: append([]int{p.task.ID}, path...)})
+			}
+		}
+	}
+
+	// Ensure directory exists for write operation
+	if err := s.storage.EnsureDir(); err != nil {
+		return nil, err
+	}
+
+	for i, p := range planned {
+		if err := s.storage.Save(p.task); err != nil {
+			for _, written := range planned[:i] {
+				_ = s.storage.Delete(written.task.ID)
+			}
+			return nil, fmt.Errorf("failed to save task %q: %w", p.task.Title, err)
+		}
+	}
+
+	result := &TaskTreeResult{IDs: keys}
+	for _, p := range planned {
+		s.index.Set(p.task)
+		for _, rel := range p.task.Relations {
+			s.index.AddRelation(RelationEdge{Type: rel.Type, Source: p.task.ID, Target: rel.Task})
+		}
+		result.Tasks = append(result.Tasks, p.task)
+	}
+	if err := s.index.Save(); err != nil {
+		return nil, err
+	}
+
+	// A done parent has open subtasks again
+	if tree.ParentID != nil {
+		updated, err := s.syncParentStatus(*tree.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		result.UpdatedParents = updated
+	}
+
+	return result, nil
+}
+
+// validateTreeNode checks the fields of a single node before anything is written
+func (s *Service) validateTreeNode(node *TaskTreeNode) error {
+	if node.Title == "" {
+		if node.Key != "" {
+			return fmt.Errorf("task %q: title is required", node.Key)
+		}
+		return fmt.Errorf("title is required")
+	}
+	if node.Priority != "" && !IsValidPriority(string(node.Priority)) {
+		return fmt.Errorf("task %q: invalid priority: %s", node.Title, node.Priority)
+	}
+	if node.Type != "" && !s.isValidType(node.Type) {
+		return fmt.Errorf("task %q: invalid task type: %s", node.Title, node.Type)
+	}
+	return nil
+}
+
+// resolveTreeReference resolves a blocked_by reference to a task ID.
+// Local keys take precedence; otherwise the reference must be the ID of an
+// existing task (optionally prefixed with '#').
+func (s *Service) resolveTreeReference(ref string, keys map[string]int) (int, error) {
+	if id, ok := keys[ref]; ok {
+		return id, nil
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return 0, fmt.Errorf("unknown blocked_by reference: %q", ref)
+	}
+	if _, err := s.Get(id); err != nil {
+		return 0, fmt.Errorf("blocked_by target not found: %d", id)
+	}
+	return id, nil
+}
+
+// treeHasBlockers reports whether any node in the tree declares blocked_by references
+func treeHasBlockers(nodes []TaskTreeNode) bool {
+	for _, node := range nodes {
+		if len(node.BlockedBy) > 0 || treeHasBlockers(node.Subtasks) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testTreeDocument = `
+tasks:
+  - key: api
+    title: Build API
+    priority: high
+    subtasks:
+      - key: schema
+        title: Define schema
+      - key: handlers
+        title: Write handlers
+        blocked_by: [schema]
+  - key: ui
+    title: Build UI
+    type: bug
+    blocked_by: [api]
+`
+
+func TestParseTaskTree(t *testing.T) {
+	tree, err := ParseTaskTree([]byte(testTreeDocument))
+	if err != nil {
+		t.Fatalf("ParseTaskTree() error = %v", err)
+	}
+	if len(tree.Tasks) != 2 {
+		t.Fatalf("len(Tasks) = %d, want 2", len(tree.Tasks))
+	}
+	if len(tree.Tasks[0].Subtasks) != 2 {
+		t.Errorf("len(Subtasks) = %d, want 2", len(tree.Tasks[0].Subtasks))
+	}
+
+	// JSON is accepted as well
+	tree, err = ParseTaskTree([]byte(`{"parent_id": 3, "tasks": [{"key": "a", "title": "A"}]}`))
+	if err != nil {
+		t.Fatalf("ParseTaskTree(JSON) error = %v", err)
+	}
+	if tree.ParentID == nil || *tree.ParentID != 3 {
+		t.Errorf("ParentID = %v, want 3", tree.ParentID)
+	}
+
+	// Unknown fields are rejected
+	if _, err := ParseTaskTree([]byte("tasks:\n  - title: A\n    blockedby: [x]\n")); err == nil {
+		t.Error("ParseTaskTree() should reject unknown fields")
+	}
+}
+
+func TestService_CreateTaskTree(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	existing, _ := svc.Create("Existing", "", PriorityLow, "feature", nil)
+
+	tree, _ := ParseTaskTree([]byte(testTreeDocument))
+	result, err := svc.CreateTaskTree(tree)
+	if err != nil {
+		t.Fatalf("CreateTaskTree() error = %v", err)
+	}
+
+	want := map[string]int{"api": 2, "schema": 3, "handlers": 4, "ui": 5}
+	for key, id := range want {
+		if result.IDs[key] != id {
+			t.Errorf("IDs[%q] = %d, want %d", key, result.IDs[key], id)
+		}
+	}
+	if len(result.Tasks) != 4 {
+		t.Errorf("len(Tasks) = %d, want 4", len(result.Tasks))
+	}
+
+	schema, _ := svc.Get(3)
+	if schema.ParentID == nil || *schema.ParentID != 2 {
+		t.Errorf("schema.ParentID = %v, want 2", schema.ParentID)
+	}
+	if schema.Priority != PriorityMedium {
+		t.Errorf("schema.Priority = %q, want default %q", schema.Priority, PriorityMedium)
+	}
+	if schema.Type != "feature" {
+		t.Errorf("schema.Type = %q, want default %q", schema.Type, "feature")
+	}
+
+	if blocked, _ := svc.IsBlocked(4); !blocked {
+		t.Error("handlers should be blocked by schema")
+	}
+	if blocked, _ := svc.IsBlocked(5); !blocked {
+		t.Error("ui should be blocked by api")
+	}
+	if blocked, _ := svc.IsBlocked(existing.ID); blocked {
+		t.Error("existing task should not be blocked")
+	}
+}
+
+func TestService_CreateTaskTree_ExistingReferences(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	blocker, _ := svc.Create("Blocker", "", PriorityHigh, "feature", nil)
+
+	tree := &TaskTree{
+		ParentID: &parent.ID,
+		Tasks: []TaskTreeNode{
+			{Key: "step", Title: "Step", BlockedBy: []string{fmt.Sprintf("#%d", blocker.ID)}},
+		},
+	}
+	result, err := svc.CreateTaskTree(tree)
+	if err != nil {
+		t.Fatalf("CreateTaskTree() error = %v", err)
+	}
+
+	step, _ := svc.Get(result.IDs["step"])
+	if step.ParentID == nil || *step.ParentID != parent.ID {
+		t.Errorf("step.ParentID = %v, want %d", step.ParentID, parent.ID)
+	}
+	if len(step.Relations) != 1 || step.Relations[0].Task != blocker.ID {
+		t.Errorf("step.Relations = %v, want blocked_by %d", step.Relations, blocker.ID)
+	}
+}
+
+func TestService_CreateTaskTree_ReopensDoneParent(t *testing.T) {
+	svc := NewService(newMockStorage(), nil, newMockIndex(), []string{"feature", "bug"}, nil)
+	svc.Initialize()
+
+	parent, _ := svc.Create("Parent", "", PriorityHigh, "feature", nil)
+	svc.StartTask(parent.ID)
+	svc.CompleteTask(parent.ID)
+
+	result, err := svc.CreateTaskTree(&TaskTree{ParentID: &parent.ID, Tasks: []TaskTreeNode{{Title: "Follow-up"}}})
+	if err != nil {
+		t.Fatalf("CreateTaskTree() error = %v", err)
+	}
+	if p, _ := svc.Get(parent.ID); p.Status != StatusInProgress {
+		t.Errorf("parent status = %s, want in_progress with an open subtask", p.Status)
+	}
+	if len(result.UpdatedParents) != 1 || result.UpdatedParents[0].ID != parent.ID {
+		t.Errorf("UpdatedParents = %v, want the parent", result.UpdatedParents)
+	}
+}
+
+func TestService_CreateTaskTree_AllOrNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		tree    *TaskTree
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			tree:    &TaskTree{},
+			wantErr: "no tasks",
+		},
+		{
+			name: "missing title",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A"},
+				{Key: "b"},
+			}},
+			wantErr: "title is required",
+		},
+		{
+			name: "invalid type in subtask",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A", Subtasks: []TaskTreeNode{{Title: "B", Type: "chore"}}},
+			}},
+			wantErr: "invalid task type",
+		},
+		{
+			name: "duplicate key",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A"},
+				{Key: "a", Title: "B"},
+			}},
+			wantErr: "duplicate key",
+		},
+		{
+			name: "unknown reference",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A", BlockedBy: []string{"missing"}},
+			}},
+			wantErr: "unknown blocked_by reference",
+		},
+		{
+			name: "self reference",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A", BlockedBy: []string{"a"}},
+			}},
+			wantErr: "blocked by itself",
+		},
+		{
+			name: "dependency cycle",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Key: "a", Title: "A", BlockedBy: []string{"b"}},
+				{Key: "b", Title: "B", BlockedBy: []string{"a"}},
+			}},
+			wantErr: "dependency cycle: 1 -> 2 -> 1",
+		},
+		{
+			name: "too deeply nested subtasks",
+			tree: &TaskTree{Tasks: []TaskTreeNode{
+				{Title: "A", Subtasks: []TaskTreeNode{
+					{Title: "B", Subtasks: []TaskTreeNode{
+						{Title: "C", Subtasks: []TaskTreeNode{{Title: "D"}}},
+					}},
+				}},
+			}},
+			wantErr: "maximum depth is 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMockStorage()
+			svc := NewService(storage, nil, newMockIndex(), []string{"feature", "bug"}, nil)
+			svc.Initialize()
+
+			_, err := svc.CreateTaskTree(tt.tree)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("CreateTaskTree() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if len(storage.tasks) != 0 {
+				t.Errorf("%d task(s) written despite validation error", len(storage.tasks))
+			}
+			if len(svc.List(nil, nil, nil, nil)) != 0 {
+				t.Error("index modified despite validation error")
+			}
+		})
+	}
+}
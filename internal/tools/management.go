@@ -37,6 +37,19 @@ func registerManagementTools(s *server.MCPServer, svc *task.Service, validTypes
 	)
 	s.AddTool(createTool, createTaskHandler(svc))
 
+	// create_task_tree
+	createTreeTool := mcp.NewTool("create_task_tree",
+		mcp.WithDescription("Create a nested tree of tasks in one all-or-nothing operation. "+
+			"The document (YAML or JSON) has an optional parent_id and a tasks list; each task has "+
+			"key, title, description, priority, type, blocked_by (local keys or existing task IDs) and subtasks. "+
+			"Returns the mapping of keys to created task IDs."),
+		mcp.WithString("document",
+			mcp.Required(),
+			mcp.Description("Task tree document in YAML or JSON format"),
+		),
+	)
+	s.AddTool(createTreeTool, createTaskTreeHandler(svc))
+
 	// get_task
 	getTool := mcp.NewTool("get_task",
 		mcp.WithDescription("Get a task by ID"),
@@ -44,6 +57,9 @@ func registerManagementTools(s *server.MCPServer, svc *task.Service, validTypes
 			mcp.Required(),
 			mcp.Description("Task ID"),
 		),
+		mcp.WithBoolean("archived",
+			mcp.Description("If true, get an archived task and its archived subtasks"),
+		),
 	)
 	s.AddTool(getTool, getTaskHandler(svc))
 
@@ -72,9 +88,44 @@ func registerManagementTools(s *server.MCPServer, svc *task.Service, validTypes
 			mcp.Description(allowedValuesDescription("New task type.", validTypes)),
 			mcp.Enum(validTypes...),
 		),
+		mcp.WithBoolean("force",
+			mcp.Description("Change status without the checks of start_task/complete_task (blockers, open subtasks) and without updating parents. Recorded in the task history."),
+		),
 	)
 	s.AddTool(updateTool, updateTaskHandler(svc))
 
+	// batch_update
+	batchUpdateTool := mcp.NewTool("batch_update",
+		mcp.WithDescription("Apply the same field changes to many tasks at once. "+
+			"Select tasks by ids and/or a where filter; all tasks are validated before any is written."),
+		mcp.WithArray("ids",
+			mcp.Description("Task IDs to update"),
+			mcp.WithNumberItems(),
+		),
+		mcp.WithString("where",
+			mcp.Description("Filter selecting tasks to update, as comma-separated key=value pairs (keys: status, priority, type, parent), e.g. \"status=todo,priority=low\""),
+		),
+		mcp.WithString("description",
+			mcp.Description("New description"),
+		),
+		mcp.WithString("status",
+			mcp.Description("New status"),
+			mcp.Enum("todo", "in_progress", "done"),
+		),
+		mcp.WithString("priority",
+			mcp.Description("New priority"),
+			mcp.Enum("critical", "high", "medium", "low"),
+		),
+		mcp.WithString("type",
+			mcp.Description(allowedValuesDescription("New task type.", validTypes)),
+			mcp.Enum(validTypes...),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Change status without the checks of start_task/complete_task and without updating parents. Recorded in each task's history."),
+		),
+	)
+	s.AddTool(batchUpdateTool, batchUpdateHandler(svc))
+
 	// delete_task
 	deleteTool := mcp.NewTool("delete_task",
 		mcp.WithDescription("Delete a task"),
@@ -107,7 +158,16 @@ func registerManagementTools(s *server.MCPServer, svc *task.Service, validTypes
 			mcp.Description("Filter by parent task ID (0 for top-level tasks, omit for top-level by default)"),
 		),
 		mcp.WithBoolean("archived",
-			mcp.Description("If true, list archived tasks instead of active tasks"),
+			mcp.Description("If true, list archived tasks instead of active tasks; the status, priority, type and parent_id filters apply (all archived tasks when parent_id is omitted)"),
+		),
+		mcp.WithBoolean("include_duplicates",
+			mcp.Description("If true, include tasks marked as duplicates (hidden by default)"),
+		),
+		mcp.WithBoolean("tree",
+			mcp.Description("If true, return the tasks below parent_id as a tree with nested subtasks. Filters then keep the ancestors of matching tasks, marked context: true."),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("With tree, the number of levels returned; deeper subtasks are counted in collapsed_subtasks (default: all)"),
 		),
 	)
 	s.AddTool(listTool, listTasksHandler(svc))
@@ -121,6 +181,83 @@ func registerManagementTools(s *server.MCPServer, svc *task.Service, validTypes
 		),
 	)
 	s.AddTool(archiveTool, archiveTaskHandler(svc))
+
+	// unarchive_task
+	unarchiveTool := mcp.NewTool("unarchive_task",
+		mcp.WithDescription("Move an archived task and its archived subtasks back to the active tasks with their relations"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Archived task ID"),
+		),
+	)
+	s.AddTool(unarchiveTool, unarchiveTaskHandler(svc))
+
+	// auto_archive
+	autoArchiveTool := mcp.NewTool("auto_archive",
+		mcp.WithDescription("Apply the auto_archive policy from the config now, even if it is not enabled: archive done tasks per type/tag rules and the max_active threshold. Returns the archived tasks with reasons."),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report what would be archived (default: false)"),
+		),
+	)
+	s.AddTool(autoArchiveTool, autoArchiveHandler(svc))
+
+	// move_task
+	moveTool := mcp.NewTool("move_task",
+		mcp.WithDescription("Reorder a task among its siblings. Affects subtask order, list order and get_next_task tie-breaking."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Task ID to move"),
+		),
+		mcp.WithNumber("before",
+			mcp.Description("Place the task directly before this sibling"),
+		),
+		mcp.WithNumber("after",
+			mcp.Description("Place the task directly after this sibling"),
+		),
+	)
+	s.AddTool(moveTool, moveTaskHandler(svc))
+
+	// set_parent
+	setParentTool := mcp.NewTool("set_parent",
+		mcp.WithDescription("Move a task (with its subtasks) under a new parent, or to top level. Relations and timestamps are kept; the old and new parent statuses are re-evaluated."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Task ID to move"),
+		),
+		mcp.WithNumber("parent_id",
+			mcp.Description("New parent task ID (0 or omit to make the task top-level)"),
+		),
+	)
+	s.AddTool(setParentTool, setParentHandler(svc))
+
+	// split_task
+	splitTool := mcp.NewTool("split_task",
+		mcp.WithDescription("Split a task that is too big into subtasks, either with explicit titles or one per checklist item or ## section of its description (moved out of the parent). Subtasks inherit priority and type."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Task ID to split"),
+		),
+		mcp.WithArray("titles",
+			mcp.Description("Titles of the subtasks to create"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString("by",
+			mcp.Description("Derive subtasks from the description instead of titles"),
+			mcp.Enum(task.SplitByChecklist, task.SplitBySections),
+		),
+	)
+	s.AddTool(splitTool, splitTaskHandler(svc))
+
+	// merge_tasks
+	mergeTool := mcp.NewTool("merge_tasks",
+		mcp.WithDescription("Fold sibling tasks into the first one: descriptions are concatenated, tags and relations united, subtasks moved over, and the other tasks deleted"),
+		mcp.WithArray("ids",
+			mcp.Required(),
+			mcp.Description("Task IDs to merge; the first task is kept"),
+			mcp.WithNumberItems(),
+		),
+	)
+	s.AddTool(mergeTool, mergeTasksHandler(svc))
 }
 
 func createTaskHandler(svc *task.Service) server.ToolHandlerFunc {
@@ -146,6 +283,26 @@ func createTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 	}
 }
 
+func createTaskTreeHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tree, err := task.ParseTaskTree([]byte(req.GetString("document", "")))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := svc.CreateTaskTree(tree)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
 // taskWithSubtasksResponse is the response structure for get_task
 type taskWithSubtasksResponse struct {
 	ID          int                 `json:"id"`
@@ -155,12 +312,16 @@ type taskWithSubtasksResponse struct {
 	Status      task.Status         `json:"status"`
 	Priority    task.Priority       `json:"priority"`
 	Type        string              `json:"type"`
-	Relations   []task.Relation     `json:"relations,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	ExternalID  string              `json:"external_id,omitempty"`
+	Relations   []task.RelationRef  `json:"relations,omitempty"` // Marked archived when the target is
+	Resolution  string              `json:"resolution,omitempty"`
+	History     []task.StatusChange `json:"history,omitempty"`
 	Blocked     bool                `json:"blocked"`
 	BlockedBy   []task.BlockingInfo `json:"blocked_by,omitempty"`
 	CreatedAt   string              `json:"created_at"`
 	UpdatedAt   string              `json:"updated_at"`
-	Subtasks    []*task.Task        `json:"subtasks,omitempty"`
+	Subtasks    []*task.Task        `json:"subtasks,omitempty"` // All levels; parent_id rebuilds the tree
 }
 
 func getTaskHandler(svc *task.Service) server.ToolHandlerFunc {
@@ -171,13 +332,25 @@ func getTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 		}
 
 		id := req.GetInt("id", 0)
-
-		t, subtasks, err := svc.GetWithSubtasks(id)
+		archived := req.GetBool("archived", false)
+
+		var t *task.Task
+		var subtasks []*task.Task
+		var err error
+		if archived {
+			t, subtasks, err = svc.GetArchived(id)
+		} else {
+			t, subtasks, err = svc.GetWithSubtasks(id)
+		}
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		blocked, blockers := svc.IsBlocked(id)
+		var blocked bool
+		var blockers []task.BlockingInfo
+		if !archived {
+			blocked, blockers = svc.IsBlocked(id)
+		}
 
 		response := taskWithSubtasksResponse{
 			ID:          t.ID,
@@ -187,7 +360,11 @@ func getTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 			Status:      t.Status,
 			Priority:    t.Priority,
 			Type:        t.Type,
-			Relations:   t.Relations,
+			Tags:        t.Tags,
+			ExternalID:  t.ExternalID,
+			Relations:   svc.RelationRefs(t),
+			Resolution:  t.Resolution,
+			History:     t.History,
 			Blocked:     blocked,
 			BlockedBy:   blockers,
 			CreatedAt:   t.CreatedAt.Format("2006-01-02T15:04:05Z"),
@@ -238,7 +415,7 @@ func updateTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 			taskType = &v
 		}
 
-		t, err := svc.Update(id, title, description, status, priority, taskType)
+		t, err := svc.Update(id, title, description, status, priority, taskType, req.GetBool("force", false))
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
@@ -247,6 +424,53 @@ func updateTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 	}
 }
 
+func batchUpdateHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids := req.GetIntSlice("ids", nil)
+
+		args := req.GetArguments()
+		if _, ok := args["where"]; ok {
+			filter, err := task.ParseFilter(req.GetString("where", ""))
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			ids = append(ids, svc.FindTasks(filter)...)
+		}
+
+		var description, taskType *string
+		var status *task.Status
+		var priority *task.Priority
+
+		if _, ok := args["description"]; ok {
+			v := req.GetString("description", "")
+			description = &v
+		}
+		if _, ok := args["status"]; ok {
+			s := task.Status(req.GetString("status", ""))
+			status = &s
+		}
+		if _, ok := args["priority"]; ok {
+			p := task.Priority(req.GetString("priority", ""))
+			priority = &p
+		}
+		if _, ok := args["type"]; ok {
+			v := req.GetString("type", "")
+			taskType = &v
+		}
+
+		tasks, err := svc.BatchUpdate(ids, description, status, priority, taskType, req.GetBool("force", false))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, err := json.MarshalIndent(tasks, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
 func deleteTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		id := req.GetInt("id", 0)
@@ -270,27 +494,102 @@ func archiveTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 	}
 }
 
-func listTasksHandler(svc *task.Service) server.ToolHandlerFunc {
+func unarchiveTaskHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := svc.UnarchiveTask(req.GetInt("id", 0))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func autoArchiveHandler(svc *task.Service) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Check project exists for read operation
 		if err := svc.EnsureProjectExists(); err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		result, err := svc.AutoArchive(req.GetBool("dry_run", false))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
 
-		// If archived flag is set, return archived tasks
-		if req.GetBool("archived", false) {
-			tasks, err := svc.ListArchived()
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-			if len(tasks) == 0 {
-				return mcp.NewToolResultText("No archived tasks found"), nil
-			}
-			data, err := json.MarshalIndent(tasks, "", "  ")
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-			return mcp.NewToolResultText(string(data)), nil
+func moveTaskHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t, err := svc.MoveTask(req.GetInt("id", 0), req.GetInt("before", 0), req.GetInt("after", 0))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return taskResult(t)
+	}
+}
+
+func setParentHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var parentID *int
+		if pid := req.GetInt("parent_id", 0); pid != 0 {
+			parentID = &pid
+		}
+		result, err := svc.SetParent(req.GetInt("id", 0), parentID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func splitTaskHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		opts := task.SplitOptions{
+			Titles: req.GetStringSlice("titles", nil),
+			By:     req.GetString("by", ""),
+		}
+		result, err := svc.SplitTask(req.GetInt("id", 0), opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func mergeTasksHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := svc.MergeTasks(req.GetIntSlice("ids", nil))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func listTasksHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Check project exists for read operation
+		if err := svc.EnsureProjectExists(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		var status *task.Status
@@ -311,6 +610,50 @@ func listTasksHandler(svc *task.Service) server.ToolHandlerFunc {
 			taskType = &v
 		}
 
+		// If archived flag is set, return archived tasks: all of them unless
+		// parent_id selects the subtasks of one
+		if req.GetBool("archived", false) {
+			var archivedParent *int
+			if _, ok := args["parent_id"]; ok {
+				id := req.GetInt("parent_id", 0)
+				archivedParent = &id
+			}
+			tasks, err := svc.ListArchived(status, priority, taskType, archivedParent)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(tasks) == 0 {
+				return mcp.NewToolResultText("No archived tasks found"), nil
+			}
+			data, err := json.MarshalIndent(tasks, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		}
+
+		if req.GetBool("tree", false) {
+			nodes, err := svc.Hierarchy(task.HierarchyFilter{
+				Status:            status,
+				Priority:          priority,
+				Type:              taskType,
+				ParentID:          req.GetInt("parent_id", 0),
+				MaxDepth:          req.GetInt("depth", 0),
+				IncludeDuplicates: req.GetBool("include_duplicates", false),
+			})
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(nodes) == 0 {
+				return mcp.NewToolResultText("No tasks found"), nil
+			}
+			data, err := json.MarshalIndent(nodes, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(string(data)), nil
+		}
+
 		// Default to showing top-level tasks (parentID = 0)
 		// If parent_id is explicitly provided, use that value
 		defaultParentID := 0
@@ -321,6 +664,9 @@ func listTasksHandler(svc *task.Service) server.ToolHandlerFunc {
 		}
 
 		tasks := svc.List(status, priority, taskType, parentID)
+		if !req.GetBool("include_duplicates", false) {
+			tasks = svc.WithoutDuplicates(tasks)
+		}
 
 		if len(tasks) == 0 {
 			return mcp.NewToolResultText("No tasks found"), nil
@@ -2,8 +2,12 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/gpayer/mcp-task-manager/internal/graph"
 	"github.com/gpayer/mcp-task-manager/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -26,6 +30,9 @@ func registerRelationTools(s *server.MCPServer, svc *task.Service, relationTypes
 			mcp.Required(),
 			mcp.Description("Target task ID"),
 		),
+		mcp.WithString("target_project",
+			mcp.Description("Workspace project of the target task, if it is in another project"),
+		),
 	)
 	s.AddTool(addTool, addRelationHandler(svc))
 
@@ -45,8 +52,63 @@ func registerRelationTools(s *server.MCPServer, svc *task.Service, relationTypes
 			mcp.Required(),
 			mcp.Description("Target task ID"),
 		),
+		mcp.WithString("target_project",
+			mcp.Description("Workspace project of the target task, if it is in another project"),
+		),
 	)
 	s.AddTool(removeTool, removeRelationHandler(svc))
+
+	// dependency_graph
+	graphTool := mcp.NewTool("dependency_graph",
+		mcp.WithDescription("Show the transitive blocked_by relations of a task, or the critical path across all open tasks"),
+		mcp.WithNumber("id",
+			mcp.Description("Task ID to show blockers and dependents for"),
+		),
+		mcp.WithBoolean("critical_path",
+			mcp.Description("Return the longest chain of open blocked_by tasks instead of a single task's graph"),
+		),
+	)
+	s.AddTool(graphTool, dependencyGraphHandler(svc))
+
+	// mark_duplicate
+	duplicateTool := mcp.NewTool("mark_duplicate",
+		mcp.WithDescription("Mark a task as duplicate of a canonical task. Relations other tasks have to the duplicate are re-pointed to the canonical task, and the duplicate is hidden from list_tasks and get_next_task."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Duplicate task ID"),
+		),
+		mcp.WithNumber("canonical",
+			mcp.Required(),
+			mcp.Description("Canonical task ID"),
+		),
+		mcp.WithBoolean("close",
+			mcp.Description("Set the duplicate to done (default from config duplicates.auto_close)"),
+		),
+		mcp.WithBoolean("merge",
+			mcp.Description("Append the duplicate's description and tags to the canonical task (default from config duplicates.merge)"),
+		),
+	)
+	s.AddTool(duplicateTool, markDuplicateHandler(svc))
+
+	// render_graph
+	edgeTypes := append([]string{task.EdgeParent}, relationTypes...)
+	renderTool := mcp.NewTool("render_graph",
+		mcp.WithDescription("Render tasks with their parent links and relations as a Graphviz DOT or Mermaid flowchart"),
+		mcp.WithString("format",
+			mcp.Description(allowedValuesDescription("Output format (default: mermaid).", graph.Formats())),
+			mcp.Enum(graph.Formats()...),
+		),
+		mcp.WithNumber("root",
+			mcp.Description("Only show this task, its subtasks and tasks directly related to them"),
+		),
+		mcp.WithString("status",
+			mcp.Description("Comma-separated statuses to include (default: all)"),
+		),
+		mcp.WithString("edge_types",
+			mcp.Description(allowedValuesDescription("Comma-separated edge types to include (default: all).", edgeTypes)),
+		),
+	)
+	s.AddTool(renderTool, renderGraphHandler(svc))
 }
 
 func addRelationHandler(svc *task.Service) server.ToolHandlerFunc {
@@ -54,13 +116,22 @@ func addRelationHandler(svc *task.Service) server.ToolHandlerFunc {
 		source := req.GetInt("source", 0)
 		relationType := req.GetString("type", "")
 		target := req.GetInt("target", 0)
+		targetProject := req.GetString("target_project", "")
 
-		if err := svc.AddRelation(source, relationType, target); err != nil {
+		if err := svc.AddProjectRelation(source, relationType, targetProject, target); err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Added %s relation from task %d to task %d", relationType, source, target)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Added %s relation from task %d to task %s", relationType, source, targetRef(targetProject, target))), nil
+	}
+}
+
+// targetRef formats a relation target: 42, or api#42 in another project
+func targetRef(project string, id int) string {
+	if project == "" {
+		return strconv.Itoa(id)
 	}
+	return task.FormatRef(project, id)
 }
 
 func removeRelationHandler(svc *task.Service) server.ToolHandlerFunc {
@@ -68,11 +139,72 @@ func removeRelationHandler(svc *task.Service) server.ToolHandlerFunc {
 		source := req.GetInt("source", 0)
 		relationType := req.GetString("type", "")
 		target := req.GetInt("target", 0)
+		targetProject := req.GetString("target_project", "")
+
+		if err := svc.RemoveProjectRelation(source, relationType, targetProject, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Removed %s relation from task %d to task %s", relationType, source, targetRef(targetProject, target))), nil
+	}
+}
+
+func dependencyGraphHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var result any
+		if req.GetBool("critical_path", false) {
+			result = svc.CriticalPath()
+		} else {
+			id := req.GetInt("id", 0)
+			if id == 0 {
+				return mcp.NewToolResultError("either id or critical_path is required"), nil
+			}
+			graph, err := svc.GetDependencyGraph(id)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			result = graph
+		}
 
-		if err := svc.RemoveRelation(source, relationType, target); err != nil {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func renderGraphHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filter := graph.NewFilter(req.GetInt("root", 0), req.GetString("status", ""), req.GetString("edge_types", ""))
+		g, err := svc.TaskGraph(filter)
+		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Removed %s relation from task %d to task %d", relationType, source, target)), nil
+		var sb strings.Builder
+		if err := graph.Render(&sb, req.GetString("format", "mermaid"), g); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(sb.String()), nil
+	}
+}
+
+func markDuplicateHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		opts := svc.DefaultDuplicateOptions()
+		opts.Close = req.GetBool("close", opts.Close)
+		opts.Merge = req.GetBool("merge", opts.Merge)
+
+		result, err := svc.MarkDuplicate(req.GetInt("id", 0), req.GetInt("canonical", 0), opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
 	}
 }
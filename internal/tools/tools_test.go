@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -38,6 +41,35 @@ func TestRegisterDocumentsAllowedTypeValues(t *testing.T) {
 	)
 }
 
+func TestCompleteTaskReportsAutoArchive(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.ProjectFound = true
+	cfg.AutoArchive = config.AutoArchiveConfig{Enabled: true, AfterDays: 0}
+	mdStorage := storage.NewMarkdownStorage(cfg.DataDir)
+	svc := task.NewService(mdStorage, storage.NewArchiveIndex(mdStorage), storage.NewIndex(cfg.DataDir, mdStorage), cfg.TaskTypes, cfg)
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	s := server.NewMCPServer("test-server", "1.0.0")
+	Register(s, svc, cfg.TaskTypes, cfg.RelationTypeNames())
+
+	svc.Create("Done soon", "", task.PriorityMedium, "feature", nil)
+	svc.Create("Open", "", task.PriorityMedium, "feature", nil)
+	svc.StartTask(1)
+
+	if got := callTool(t, s, "auto_archive", map[string]any{"dry_run": true}); !strings.Contains(got, `"archived": []`) {
+		t.Errorf("auto_archive dry run = %s, want nothing to archive", got)
+	}
+	got := callTool(t, s, "complete_task", map[string]any{"id": 1})
+	if !strings.Contains(got, `"status": "done"`) || !strings.Contains(got, `"auto_archive"`) || !strings.Contains(got, `"reason": "done for 0 days (default: after 0 days)"`) {
+		t.Errorf("complete_task = %s, want the task and the auto-archive run", got)
+	}
+	if got := callTool(t, s, "list_tasks", map[string]any{}); strings.Contains(got, "Done soon") {
+		t.Errorf("list_tasks = %s, want the completed task archived", got)
+	}
+}
+
 func assertStringProperty(t *testing.T, properties map[string]any, name, wantDescriptionSuffix string, wantEnum []string) {
 	t.Helper()
 
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/gpayer/mcp-task-manager/internal/task"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -34,6 +35,34 @@ func registerWorkflowTools(s *server.MCPServer, svc *task.Service) {
 		),
 	)
 	s.AddTool(completeTool, completeTaskHandler(svc))
+
+	// reopen_task
+	reopenTool := mcp.NewTool("reopen_task",
+		mcp.WithDescription("Move a done or cancelled task back to todo. Done parents are un-completed and open dependents are blocked again. The reason is stored in the task history."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Task ID to reopen"),
+		),
+		mcp.WithString("reason",
+			mcp.Required(),
+			mcp.Description("Why the task is reopened"),
+		),
+	)
+	s.AddTool(reopenTool, reopenTaskHandler(svc))
+
+	// cancel_task
+	cancelTool := mcp.NewTool("cancel_task",
+		mcp.WithDescription("Close a task that will not be done, together with its open subtasks. Cancelled tasks count as done for parents and dependents and get resolution \"cancelled\". The reason is stored in the task history."),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("Task ID to cancel"),
+		),
+		mcp.WithString("reason",
+			mcp.Required(),
+			mcp.Description("Why the task is cancelled"),
+		),
+	)
+	s.AddTool(cancelTool, cancelTaskHandler(svc))
 }
 
 func getNextTaskHandler(svc *task.Service) server.ToolHandlerFunc {
@@ -73,9 +102,70 @@ func completeTaskHandler(svc *task.Service) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Trigger auto-archive check if enabled
-		_ = svc.RunAutoArchive()
+		archived := runAutoArchive(svc)
+		if archived == nil {
+			return taskResult(t)
+		}
+		data, err := json.MarshalIndent(struct {
+			*task.Task
+			AutoArchive *task.AutoArchiveResult `json:"auto_archive"`
+		}{t, archived}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
 
-		return taskResult(t)
+func reopenTaskHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := svc.ReopenTask(req.GetInt("id", 0), req.GetString("reason", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return transitionResult(result)
+	}
+}
+
+func cancelTaskHandler(svc *task.Service) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := svc.CancelTask(req.GetInt("id", 0), req.GetString("reason", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		archived := runAutoArchive(svc)
+		if archived == nil {
+			return transitionResult(result)
+		}
+		data, err := json.MarshalIndent(struct {
+			*task.TransitionResult
+			AutoArchive *task.AutoArchiveResult `json:"auto_archive"`
+		}{result, archived}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+// runAutoArchive runs the enabled auto_archive policy after a task was
+// closed. It returns nil unless something was archived or went wrong.
+func runAutoArchive(svc *task.Service) *task.AutoArchiveResult {
+	result, err := svc.RunAutoArchive()
+	if err != nil {
+		return &task.AutoArchiveResult{Archived: []task.AutoArchiveEntry{}, Errors: []string{err.Error()}}
+	}
+	if result == nil || (len(result.Archived) == 0 && len(result.Errors) == 0) {
+		return nil
+	}
+	return result
+}
+
+func transitionResult(result *task.TransitionResult) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	return mcp.NewToolResultText(string(data)), nil
 }
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ProjectOpener opens the task service of a workspace project together with
+// the project's configuration, whose task and relation types its tools use
+type ProjectOpener func(name string) (*task.Service, *config.Config, error)
+
+// workspaceTools serves tool calls for other workspace projects by
+// registering the same tools against each project's service on demand
+type workspaceTools struct {
+	projects []string
+	open     ProjectOpener
+
+	mu       sync.Mutex
+	services map[string]*task.Service
+	configs  map[string]*config.Config
+	handlers map[string]map[string]server.ToolHandlerFunc // Project -> tool -> handler
+}
+
+// RegisterWorkspace registers all tools like Register and adds a "project"
+// parameter to each of them. Calls naming a project are served by that
+// project's service; get_next_task can also pick across all projects.
+func RegisterWorkspace(s *server.MCPServer, svc *task.Service, validTypes, relationTypes, projects []string, open ProjectOpener) {
+	Register(s, svc, validTypes, relationTypes)
+
+	w := &workspaceTools{
+		projects: projects,
+		open:     open,
+		services: make(map[string]*task.Service),
+		configs:  make(map[string]*config.Config),
+		handlers: make(map[string]map[string]server.ToolHandlerFunc),
+	}
+	for name, st := range s.ListTools() {
+		tool := st.Tool
+		relaxEnums(&tool, validTypes, relationTypes)
+		mcp.WithString("project",
+			mcp.Description(allowedValuesDescription("Workspace project to use (default: the current project).", projects)),
+			mcp.Enum(projects...),
+		)(&tool)
+		handler := st.Handler
+		if name == "get_next_task" {
+			mcp.WithBoolean("all_projects",
+				mcp.Description("If true, pick the next task across all workspace projects"),
+			)(&tool)
+			handler = w.nextTaskHandler(handler)
+		}
+		s.AddTool(tool, w.wrap(name, handler))
+	}
+}
+
+// relaxEnums drops enums listing the current project's task or relation
+// types, since other projects may configure different ones. Each project's
+// service still validates the values.
+func relaxEnums(tool *mcp.Tool, lists ...[]string) {
+	for _, prop := range tool.InputSchema.Properties {
+		schema, ok := prop.(map[string]any)
+		if !ok {
+			continue
+		}
+		enum, ok := schema["enum"].([]string)
+		if !ok {
+			continue
+		}
+		for _, list := range lists {
+			if slices.Equal(enum, list) {
+				delete(schema, "enum")
+				schema["description"] = fmt.Sprintf("%v Other projects may allow other values.", schema["description"])
+				break
+			}
+		}
+	}
+}
+
+// wrap dispatches calls with a project parameter to that project's handler
+func (w *workspaceTools) wrap(name string, current server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		project := req.GetString("project", "")
+		if project == "" {
+			return current(ctx, req)
+		}
+		handlers, err := w.projectHandlers(project)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		handler, ok := handlers[name]
+		if !ok {
+			return mcp.NewToolResultError("unknown tool: " + name), nil
+		}
+		if name == "get_next_task" {
+			handler = w.nextTaskHandler(handler)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// service returns the (cached) service of a project
+func (w *workspaceTools) service(project string) (*task.Service, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if svc, ok := w.services[project]; ok {
+		return svc, nil
+	}
+	svc, cfg, err := w.open(project)
+	if err != nil {
+		return nil, err
+	}
+	w.services[project] = svc
+	w.configs[project] = cfg
+	return svc, nil
+}
+
+// projectHandlers returns the tool handlers bound to a project's service
+func (w *workspaceTools) projectHandlers(project string) (map[string]server.ToolHandlerFunc, error) {
+	svc, err := w.service(project)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if handlers, ok := w.handlers[project]; ok {
+		return handlers, nil
+	}
+	// The project's own task and relation types, not the current project's
+	cfg := w.configs[project]
+	tmp := server.NewMCPServer(project, "")
+	Register(tmp, svc, cfg.TaskTypes, cfg.RelationTypeNames())
+	handlers := make(map[string]server.ToolHandlerFunc)
+	for name, st := range tmp.ListTools() {
+		handlers[name] = st.Handler
+	}
+	w.handlers[project] = handlers
+	return handlers, nil
+}
+
+// nextTaskHandler adds the all_projects option to get_next_task
+func (w *workspaceTools) nextTaskHandler(single server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !req.GetBool("all_projects", false) {
+			return single(ctx, req)
+		}
+
+		services := make(map[string]*task.Service)
+		for _, project := range w.projects {
+			svc, err := w.service(project)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			services[project] = svc
+		}
+
+		t := task.NextAcrossProjects(services)
+		if t == nil {
+			return mcp.NewToolResultText("No tasks available"), nil
+		}
+		data, err := json.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
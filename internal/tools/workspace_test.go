@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newTestService(t *testing.T, titles map[string]task.Priority) *task.Service {
+	t.Helper()
+	return newConfiguredTestService(t, config.DefaultConfig(), titles)
+}
+
+func newConfiguredTestService(t *testing.T, cfg *config.Config, titles map[string]task.Priority) *task.Service {
+	t.Helper()
+	cfg.DataDir = t.TempDir()
+	cfg.ProjectFound = true
+	mdStorage := storage.NewMarkdownStorage(cfg.DataDir)
+	svc := task.NewService(mdStorage, storage.NewArchiveIndex(mdStorage), storage.NewIndex(cfg.DataDir, mdStorage), cfg.TaskTypes, cfg)
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	for title, priority := range titles {
+		if _, err := svc.Create(title, "", priority, "feature", nil); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	return svc
+}
+
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]any) string {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := s.ListTools()[name].Handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("%s error = %v", name, err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		return "error: " + text
+	}
+	return text
+}
+
+func TestRegisterWorkspace(t *testing.T) {
+	current := newTestService(t, map[string]task.Priority{"Current task": task.PriorityLow})
+	apiCfg := config.DefaultConfig()
+	apiCfg.TaskTypes = []string{"feature", "chore"}
+	projects := map[string]*task.Service{
+		"api": newConfiguredTestService(t, apiCfg, map[string]task.Priority{"API task": task.PriorityMedium}),
+		"web": newTestService(t, map[string]task.Priority{"Web task": task.PriorityCritical}),
+	}
+	configs := map[string]*config.Config{"api": apiCfg, "web": config.DefaultConfig()}
+	open := func(name string) (*task.Service, *config.Config, error) {
+		svc, ok := projects[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown project: %s", name)
+		}
+		return svc, configs[name], nil
+	}
+
+	s := server.NewMCPServer("test-server", "1.0.0")
+	RegisterWorkspace(s, current, []string{"feature", "bug"}, []string{"blocked_by"}, []string{"api", "web"}, open)
+
+	if _, ok := s.ListTools()["get_task"].Tool.InputSchema.Properties["project"]; !ok {
+		t.Error("get_task should accept a project parameter")
+	}
+
+	if got := callTool(t, s, "get_task", map[string]any{"id": 1}); !strings.Contains(got, "Current task") {
+		t.Errorf("get_task without project = %s, want current project task", got)
+	}
+	if got := callTool(t, s, "get_task", map[string]any{"id": 1, "project": "api"}); !strings.Contains(got, "API task") {
+		t.Errorf("get_task with project = %s, want api task", got)
+	}
+	if got := callTool(t, s, "get_task", map[string]any{"id": 1, "project": "docs"}); !strings.Contains(got, "unknown project") {
+		t.Errorf("get_task with unknown project = %s, want error", got)
+	}
+
+	got := callTool(t, s, "get_next_task", map[string]any{"all_projects": true})
+	if !strings.Contains(got, `"ref": "web#1"`) {
+		t.Errorf("get_next_task across projects = %s, want web#1", got)
+	}
+
+	// Task types are the project's own, so the schema cannot list the current ones only
+	if _, ok := s.ListTools()["create_task"].Tool.InputSchema.Properties["type"].(map[string]any)["enum"]; ok {
+		t.Error("create_task type should not be restricted to the current project's types")
+	}
+	if got := callTool(t, s, "create_task", map[string]any{"title": "Chore", "priority": "low", "type": "chore", "project": "api"}); strings.HasPrefix(got, "error") {
+		t.Errorf("create_task with the project's own type = %s", got)
+	}
+	if got := callTool(t, s, "create_task", map[string]any{"title": "Chore", "priority": "low", "type": "chore"}); !strings.Contains(got, "invalid task type") {
+		t.Errorf("create_task with another project's type = %s, want error", got)
+	}
+}
+
+func TestCrossProjectRelations(t *testing.T) {
+	current := newTestService(t, map[string]task.Priority{"Web task": task.PriorityHigh})
+	api := newTestService(t, map[string]task.Priority{"API task": task.PriorityLow})
+	projects := map[string]*task.Service{"api": api, "web": current}
+	resolve := task.NewProjectResolver(func(name string) (*task.Service, error) {
+		svc, ok := projects[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown project: %s", name)
+		}
+		return svc, nil
+	})
+	current.SetWorkspace("web", resolve)
+	open := func(name string) (*task.Service, *config.Config, error) {
+		svc, err := resolve(name)
+		return svc, config.DefaultConfig(), err
+	}
+
+	s := server.NewMCPServer("test-server", "1.0.0")
+	RegisterWorkspace(s, current, []string{"feature", "bug"}, []string{"blocked_by", "blocks"}, []string{"api", "web"}, open)
+
+	got := callTool(t, s, "add_relation", map[string]any{"source": 1, "type": "blocked_by", "target": 1, "target_project": "api"})
+	if !strings.Contains(got, "to task api#1") {
+		t.Fatalf("add_relation = %s", got)
+	}
+	if got := callTool(t, s, "add_relation", map[string]any{"source": 1, "type": "blocked_by", "target": 1, "target_project": "web", "project": "api"}); !strings.Contains(got, "dependency cycle") {
+		t.Errorf("add_relation back from api = %s, want cycle error", got)
+	}
+
+	got = callTool(t, s, "get_task", map[string]any{"id": 1})
+	for _, want := range []string{`"blocked": true`, `"project": "api"`, `"title": "API task"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("get_task missing %s:\n%s", want, got)
+		}
+	}
+	if got := callTool(t, s, "get_next_task", map[string]any{}); strings.Contains(got, "Web task") {
+		t.Errorf("get_next_task = %s, blocked task should be skipped", got)
+	}
+	if got := callTool(t, s, "render_graph", map[string]any{"format": "dot"}); !strings.Contains(got, "t1 -> p_api_1") {
+		t.Errorf("render_graph = %s, want edge to api#1", got)
+	}
+
+	// Done, and later archived, blockers in the other project resolve it
+	callTool(t, s, "start_task", map[string]any{"id": 1, "project": "api"})
+	callTool(t, s, "complete_task", map[string]any{"id": 1, "project": "api"})
+	if err := api.ArchiveTask(1); err != nil {
+		t.Fatalf("ArchiveTask() error = %v", err)
+	}
+	if blocked, blockers := current.IsBlocked(1); blocked {
+		t.Errorf("IsBlocked() = %v, want resolved", blockers)
+	}
+	if got := callTool(t, s, "get_next_task", map[string]any{}); !strings.Contains(got, "Web task") {
+		t.Errorf("get_next_task = %s, want the unblocked task", got)
+	}
+
+	got = callTool(t, s, "remove_relation", map[string]any{"source": 1, "type": "blocked_by", "target": 1, "target_project": "api"})
+	if strings.HasPrefix(got, "error") {
+		t.Errorf("remove_relation = %s", got)
+	}
+}
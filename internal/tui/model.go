@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// columnStatuses are the board columns, left to right
+var columnStatuses = []task.Status{task.StatusTodo, task.StatusInProgress, task.StatusDone}
+
+// priorities from highest to lowest, used to raise and lower a task's priority
+var priorities = []task.Priority{task.PriorityCritical, task.PriorityHigh, task.PriorityMedium, task.PriorityLow}
+
+// inputMode is the kind of text being entered at the prompt
+type inputMode int
+
+const (
+	inputNone inputMode = iota
+	inputAddRelation
+	inputRemoveRelation
+)
+
+// tickMsg triggers a check of the tasks directory
+type tickMsg time.Time
+
+type model struct {
+	opts      Options
+	svc       *task.Service
+	signature string
+
+	columns [][]*task.Task
+	col     int
+	rows    []int // Selected row per column
+
+	mode  inputMode
+	input string
+
+	message string
+	isError bool
+
+	width, height int
+}
+
+func newModel(opts Options) (*model, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultRefreshInterval
+	}
+	m := &model{opts: opts, rows: make([]int, len(columnStatuses))}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *model) Init() tea.Cmd {
+	return m.tick()
+}
+
+func (m *model) tick() tea.Cmd {
+	return tea.Tick(m.opts.Interval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// reload rebuilds the service from disk and refreshes the board
+func (m *model) reload() error {
+	svc, err := m.opts.Load()
+	if err != nil {
+		return err
+	}
+	m.svc = svc
+	m.signature = storage.DirSignature(m.opts.TasksDir)
+	m.rebuild()
+	return nil
+}
+
+// rebuild sorts tasks into columns, keeping the selected task selected
+func (m *model) rebuild() {
+	selected := m.selected()
+	m.columns = make([][]*task.Task, len(columnStatuses))
+	for _, t := range m.svc.AllTasks() {
+		for i, st := range columnStatuses {
+			if t.Status == st {
+				m.columns[i] = append(m.columns[i], t)
+			}
+		}
+	}
+	for _, tasks := range m.columns {
+		task.SortByRank(tasks)
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].Priority.Order() < tasks[j].Priority.Order()
+		})
+	}
+	if selected != nil {
+		m.selectTask(selected.ID)
+	}
+	for i := range m.rows {
+		m.rows[i] = min(m.rows[i], max(len(m.columns[i])-1, 0))
+	}
+}
+
+// selected returns the task under the cursor, or nil for an empty column
+func (m *model) selected() *task.Task {
+	if m.col >= len(m.columns) || len(m.columns[m.col]) == 0 {
+		return nil
+	}
+	return m.columns[m.col][m.rows[m.col]]
+}
+
+// selectTask moves the cursor to a task, following it across columns
+func (m *model) selectTask(id int) {
+	for c, tasks := range m.columns {
+		for r, t := range tasks {
+			if t.ID == id {
+				m.col, m.rows[c] = c, r
+				return
+			}
+		}
+	}
+}
+
+func (m *model) setMessage(format string, args ...any) {
+	m.message, m.isError = fmt.Sprintf(format, args...), false
+}
+
+func (m *model) setError(err error) {
+	m.message, m.isError = err.Error(), true
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tickMsg:
+		m.refreshIfChanged()
+		return m, m.tick()
+	case tea.KeyMsg:
+		if m.mode != inputNone {
+			m.handleInput(msg)
+			return m, nil
+		}
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// refreshIfChanged reloads the board when another process changed the tasks directory
+func (m *model) refreshIfChanged() {
+	if storage.DirSignature(m.opts.TasksDir) == m.signature {
+		return
+	}
+	if err := m.reload(); err != nil {
+		m.setError(err)
+	}
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "left", "h":
+		m.col = max(m.col-1, 0)
+	case "right", "l":
+		m.col = min(m.col+1, len(m.columns)-1)
+	case "up", "k":
+		m.rows[m.col] = max(m.rows[m.col]-1, 0)
+	case "down", "j":
+		m.rows[m.col] = min(m.rows[m.col]+1, max(len(m.columns[m.col])-1, 0))
+	case "g", "ctrl+r":
+		if err := m.reload(); err != nil {
+			m.setError(err)
+		} else {
+			m.setMessage("Refreshed.")
+		}
+	case "s":
+		m.act(func(t *task.Task) (string, error) {
+			if _, err := m.svc.StartTask(t.ID); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Task #%d started.", t.ID), nil
+		})
+	case "c":
+		m.act(func(t *task.Task) (string, error) {
+			if _, err := m.svc.CompleteTask(t.ID); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Task #%d completed.", t.ID), nil
+		})
+	case "+", "=":
+		m.shiftPriority(-1)
+	case "-":
+		m.shiftPriority(1)
+	case "r":
+		m.prompt(inputAddRelation)
+	case "x":
+		m.prompt(inputRemoveRelation)
+	}
+	return nil
+}
+
+// act runs an action on the selected task and refreshes the board
+func (m *model) act(fn func(t *task.Task) (string, error)) {
+	t := m.selected()
+	if t == nil {
+		return
+	}
+	msg, err := fn(t)
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	m.setMessage("%s", msg)
+	m.signature = storage.DirSignature(m.opts.TasksDir)
+	m.rebuild()
+}
+
+// shiftPriority moves the selected task's priority by delta steps (negative raises it)
+func (m *model) shiftPriority(delta int) {
+	m.act(func(t *task.Task) (string, error) {
+		i := 0
+		for j, p := range priorities {
+			if p == t.Priority {
+				i = j
+			}
+		}
+		i = min(max(i+delta, 0), len(priorities)-1)
+		if priorities[i] == t.Priority {
+			return fmt.Sprintf("Task #%d is already %s.", t.ID, t.Priority), nil
+		}
+		if _, err := m.svc.Update(t.ID, nil, nil, nil, &priorities[i], nil, false); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Task #%d is now %s.", t.ID, priorities[i]), nil
+	})
+}
+
+func (m *model) prompt(mode inputMode) {
+	if m.selected() == nil {
+		return
+	}
+	m.mode, m.input, m.message = mode, "", ""
+}
+
+func (m *model) handleInput(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.mode = inputNone
+	case tea.KeyEnter:
+		mode := m.mode
+		m.mode = inputNone
+		m.submitRelation(mode, m.input)
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+}
+
+// submitRelation adds or removes a relation given as "<type> <task id>"
+func (m *model) submitRelation(mode inputMode, input string) {
+	fields := strings.Fields(input)
+	if len(fields) != 2 {
+		m.setError(fmt.Errorf("expected <type> <task id>, e.g. blocked_by 5"))
+		return
+	}
+	target, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#"))
+	if err != nil {
+		m.setError(fmt.Errorf("invalid task ID: %s", fields[1]))
+		return
+	}
+	m.act(func(t *task.Task) (string, error) {
+		if mode == inputRemoveRelation {
+			if err := m.svc.RemoveRelation(t.ID, fields[0], target); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Removed %s #%d from task #%d.", fields[0], target, t.ID), nil
+		}
+		if err := m.svc.AddRelation(t.ID, fields[0], target); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Task #%d %s #%d.", t.ID, fields[0], target), nil
+	})
+}
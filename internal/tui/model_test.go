@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gpayer/mcp-task-manager/internal/config"
+	"github.com/gpayer/mcp-task-manager/internal/storage"
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// newTestModel returns a board over a fresh tasks directory and a loader
+// that opens another service on the same directory, as an agent would
+func newTestModel(t *testing.T, titles ...string) (*model, func() *task.Service) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.ProjectFound = true
+	load := func() (*task.Service, error) {
+		mdStorage := storage.NewMarkdownStorage(cfg.DataDir)
+		svc := task.NewService(mdStorage, storage.NewArchiveIndex(mdStorage), storage.NewIndex(cfg.DataDir, mdStorage), cfg.TaskTypes, cfg)
+		return svc, svc.Initialize()
+	}
+	open := func() *task.Service {
+		svc, err := load()
+		if err != nil {
+			t.Fatalf("Initialize() error = %v", err)
+		}
+		return svc
+	}
+
+	svc := open()
+	for _, title := range titles {
+		if _, err := svc.Create(title, "", task.PriorityMedium, "feature", nil); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	m, err := newModel(Options{TasksDir: cfg.DataDir, Load: load})
+	if err != nil {
+		t.Fatalf("newModel() error = %v", err)
+	}
+	return m, open
+}
+
+func press(m *model, keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m.Update(msg)
+	}
+}
+
+func columnIDs(m *model, c int) []int {
+	var ids []int
+	for _, t := range m.columns[c] {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestModel_Board(t *testing.T) {
+	m, open := newTestModel(t, "Low", "High")
+	svc := open()
+	high := task.PriorityHigh
+	if _, err := svc.Update(2, nil, nil, nil, &high, nil, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := m.reload(); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+
+	if got := columnIDs(m, 0); len(got) != 2 || got[0] != 2 {
+		t.Errorf("todo column = %v, want high priority task first", got)
+	}
+	view := m.View()
+	for _, want := range []string{"todo (2)", "in_progress (0)", "done (0)", "#2 High [high]", "#1 Low\nStatus: todo  Priority: medium"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestModel_Actions(t *testing.T) {
+	m, _ := newTestModel(t, "First", "Second")
+
+	// Start follows the task into the in_progress column
+	press(m, "s")
+	if m.isError {
+		t.Fatalf("start failed: %s", m.message)
+	}
+	if got := columnIDs(m, 1); len(got) != 1 || got[0] != 1 {
+		t.Errorf("in_progress column = %v, want [1]", got)
+	}
+	if m.col != 1 || m.selected().ID != 1 {
+		t.Errorf("selection = column %d, task %v, want task 1 in column 1", m.col, m.selected())
+	}
+
+	press(m, "+")
+	if got, _ := m.svc.Get(1); got.Priority != task.PriorityHigh {
+		t.Errorf("priority = %s, want high", got.Priority)
+	}
+
+	press(m, "c")
+	if got := columnIDs(m, 2); len(got) != 1 || got[0] != 1 {
+		t.Errorf("done column = %v, want [1]", got)
+	}
+
+	// Completing a todo task is rejected and reported
+	press(m, "h", "h", "c")
+	if !m.isError || !strings.Contains(m.View(), "Error: task 2 is not in progress") {
+		t.Errorf("expected error in footer, got %q", m.message)
+	}
+}
+
+func TestModel_Relations(t *testing.T) {
+	m, _ := newTestModel(t, "First", "Second")
+	press(m, "j", "r")
+	if !strings.Contains(m.View(), "Add relation") {
+		t.Fatal("expected relation prompt")
+	}
+	press(m, "blocked_by", " ", "1", "enter")
+	if m.isError {
+		t.Fatalf("add relation failed: %s", m.message)
+	}
+	if blocked, _ := m.svc.IsBlocked(2); !blocked {
+		t.Error("task 2 should be blocked by task 1")
+	}
+	if !strings.Contains(m.View(), "Blocked by: #1 (todo)") {
+		t.Errorf("detail pane should show blocker:\n%s", m.View())
+	}
+
+	press(m, "x", "blocked_by 1", "enter")
+	if blocked, _ := m.svc.IsBlocked(2); blocked {
+		t.Error("task 2 should no longer be blocked")
+	}
+
+	// Esc leaves the prompt without changes
+	press(m, "r", "oops", "esc")
+	if m.mode != inputNone || m.isError {
+		t.Errorf("mode = %d, isError = %v after esc", m.mode, m.isError)
+	}
+}
+
+func TestModel_RefreshesOnExternalChange(t *testing.T) {
+	m, open := newTestModel(t, "First")
+
+	// Another process adds a task and starts the first one
+	agent := open()
+	if _, err := agent.Create("From agent", "", task.PriorityMedium, "feature", nil); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := agent.StartTask(1); err != nil {
+		t.Fatalf("StartTask() error = %v", err)
+	}
+	// Make sure the change is visible even on coarse file timestamps
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(m.opts.TasksDir, ".index.json"), future, future)
+
+	m.Update(tickMsg(time.Now()))
+	if got := columnIDs(m, 0); len(got) != 1 || got[0] != 2 {
+		t.Errorf("todo column = %v, want [2]", got)
+	}
+	if got := columnIDs(m, 1); len(got) != 1 || got[0] != 1 {
+		t.Errorf("in_progress column = %v, want [1]", got)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	got := renderMarkdown("# Plan\n\n- [ ] open\n- [x] done\n* item\n```\ncode\n```", 80)
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{"Plan", "☐ open", "☑ done", "• item", "code"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("renderMarkdown() missing %q in %q", want, joined)
+		}
+	}
+	if strings.Contains(joined, "```") || strings.Contains(joined, "# Plan") {
+		t.Errorf("renderMarkdown() should drop markup: %q", joined)
+	}
+
+	if lines := wrap("one two three four", 9); len(lines) != 3 {
+		t.Errorf("wrap() = %q, want 3 lines", lines)
+	}
+}
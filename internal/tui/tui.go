@@ -0,0 +1,30 @@
+// Package tui implements an interactive kanban board for the task manager.
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+// DefaultRefreshInterval is how often the tasks directory is checked for changes
+const DefaultRefreshInterval = 2 * time.Second
+
+// Options configures the board
+type Options struct {
+	TasksDir string                        // Directory watched for changes made by other processes
+	Load     func() (*task.Service, error) // Builds a fresh service; called on start and on every external change
+	Interval time.Duration                 // Refresh interval; DefaultRefreshInterval when zero
+}
+
+// Run shows the board until the user quits
+func Run(opts Options) error {
+	m, err := newModel(opts)
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
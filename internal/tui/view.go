@@ -0,0 +1,229 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gpayer/mcp-task-manager/internal/task"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true)
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	columnStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	focusedStyle  = columnStyle.BorderForeground(lipgloss.Color("4"))
+)
+
+const helpText = "←/→ column  ↑/↓ task  s start  c complete  +/- priority  r add relation  x remove relation  g refresh  q quit"
+
+// Default size used before the terminal reports its dimensions
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+func (m *model) View() string {
+	width, height := m.width, m.height
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	// Header, footer and the two column border lines take 5 lines
+	boardRows := max((height-5)/2, 3)
+	detailRows := max(height-boardRows-5, 3)
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Tasks") + dimStyle.Render(" "+m.opts.TasksDir) + "\n")
+	sb.WriteString(m.viewBoard(width, boardRows) + "\n")
+	sb.WriteString(strings.Join(m.viewDetail(width, detailRows), "\n") + "\n")
+	sb.WriteString(m.viewFooter())
+	return sb.String()
+}
+
+// viewBoard renders the status columns side by side
+func (m *model) viewBoard(width, rows int) string {
+	// Each column has a border and padding of 2 cells on both sides
+	inner := max(width/len(columnStatuses)-4, 10)
+	blocks := make([]string, len(columnStatuses))
+	for c, st := range columnStatuses {
+		tasks := m.columns[c]
+		lines := []string{headerStyle.Render(fmt.Sprintf("%s (%d)", st, len(tasks)))}
+
+		// Scroll so the selected row stays visible
+		first := max(m.rows[c]-(rows-2), 0)
+		for r := first; r < len(tasks) && len(lines) < rows; r++ {
+			line := truncate(m.cardLabel(tasks[r]), inner)
+			if c == m.col && r == m.rows[c] {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		for len(lines) < rows {
+			lines = append(lines, "")
+		}
+
+		style := columnStyle
+		if c == m.col {
+			style = focusedStyle
+		}
+		blocks[c] = style.Width(inner + 2).Render(strings.Join(lines, "\n"))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, blocks...)
+}
+
+// cardLabel is a task's one-line entry on the board
+func (m *model) cardLabel(t *task.Task) string {
+	var marks string
+	if t.ParentID != nil {
+		marks += "↳"
+	}
+	if t.Status == task.StatusTodo {
+		if blocked, _ := m.svc.IsBlocked(t.ID); blocked {
+			marks += "⊘"
+		}
+	}
+	if marks != "" {
+		marks += " "
+	}
+	return fmt.Sprintf("%s#%d %s [%s]", marks, t.ID, t.Title, t.Priority)
+}
+
+// viewDetail renders the selected task with its markdown body
+func (m *model) viewDetail(width, rows int) []string {
+	t := m.selected()
+	if t == nil {
+		return []string{dimStyle.Render("No task selected.")}
+	}
+
+	status := string(t.Status)
+	if t.Resolution != "" {
+		status += " (" + t.Resolution + ")"
+	}
+	lines := []string{
+		titleStyle.Render(truncate(fmt.Sprintf("#%d %s", t.ID, t.Title), width)),
+		fmt.Sprintf("Status: %s  Priority: %s  Type: %s", status, t.Priority, t.Type),
+	}
+	if t.ParentID != nil {
+		lines = append(lines, fmt.Sprintf("Parent: #%d", *t.ParentID))
+	}
+	if blocked, blockers := m.svc.IsBlocked(t.ID); blocked {
+		var parts []string
+		for _, b := range blockers {
+			parts = append(parts, fmt.Sprintf("%s (%s)", b.Ref(), b.Status))
+		}
+		lines = append(lines, errorStyle.Render("Blocked by: "+strings.Join(parts, ", ")))
+	}
+	if refs := m.svc.RelationRefs(t); len(refs) > 0 {
+		var parts []string
+		for _, ref := range refs {
+			part := fmt.Sprintf("%s %s", ref.Type, ref.Ref())
+			if ref.Archived {
+				part += " (archived)"
+			}
+			parts = append(parts, part)
+		}
+		lines = append(lines, truncate("Relations: "+strings.Join(parts, ", "), width))
+	}
+	if t.Description != "" {
+		lines = append(lines, "")
+		lines = append(lines, renderMarkdown(t.Description, width)...)
+	}
+
+	if len(lines) > rows {
+		lines = append(lines[:rows-1], dimStyle.Render("…"))
+	}
+	return lines
+}
+
+func (m *model) viewFooter() string {
+	var status string
+	switch {
+	case m.mode == inputAddRelation:
+		status = "Add relation (<type> <id>): " + m.input + "█"
+	case m.mode == inputRemoveRelation:
+		status = "Remove relation (<type> <id>): " + m.input + "█"
+	case m.isError:
+		status = errorStyle.Render("Error: " + m.message)
+	default:
+		status = m.message
+	}
+	return status + "\n" + dimStyle.Render(helpText)
+}
+
+// renderMarkdown styles headings, lists, checklists and code blocks and wraps
+// the text to width
+func renderMarkdown(body string, width int) []string {
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			lines = append(lines, dimStyle.Render(truncate("  "+line, width)))
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			lines = append(lines, headerStyle.Render(truncate(heading, width)))
+			continue
+		case strings.HasPrefix(trimmed, "- [ ] "), strings.HasPrefix(trimmed, "* [ ] "):
+			line = indent + "☐ " + trimmed[6:]
+		case strings.HasPrefix(trimmed, "- [x] "), strings.HasPrefix(trimmed, "- [X] "),
+			strings.HasPrefix(trimmed, "* [x] "), strings.HasPrefix(trimmed, "* [X] "):
+			line = indent + "☑ " + trimmed[6:]
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			line = indent + "• " + trimmed[2:]
+		}
+		line = strings.ReplaceAll(line, "**", "")
+		lines = append(lines, wrap(line, width)...)
+	}
+	return lines
+}
+
+// wrap breaks a line at spaces so that no part is wider than width
+func wrap(line string, width int) []string {
+	if width <= 0 || len([]rune(line)) <= width {
+		return []string{line}
+	}
+	var lines []string
+	var current string
+	for _, word := range strings.Split(line, " ") {
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	lines = append(lines, current)
+	for i, l := range lines {
+		lines[i] = truncate(l, width)
+	}
+	return lines
+}
+
+// truncate shortens s to at most width runes, ending in an ellipsis
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width <= 0 || len(r) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(r[:width-1]) + "…"
+}